@@ -0,0 +1,37 @@
+package main
+
+import (
+	"file-uploader/config"
+	"fmt"
+	"log"
+	"os"
+)
+
+const configUsage = "usage: app [flags] config <print|check>"
+
+// runConfig handles the config subcommand, print shows the effective
+// settings and where each one came from
+func runConfig(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal(configUsage)
+	}
+
+	switch args[0] {
+	case "print":
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			log.Fatal(err)
+		}
+
+	case "check":
+		if err := cfg.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("configuration is valid")
+
+	default:
+		log.Fatal(configUsage)
+	}
+}
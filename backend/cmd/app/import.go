@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/upload"
+	processor "file-uploader/internal/service/csv"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+)
+
+const importUsage = "usage: app [flags] import [-json] [-tenant name] [-term term] <file|directory>..."
+
+// Exit codes of the import command, partial when some files were imported
+// and others failed
+const (
+	exitImported = 0
+	exitFailed   = 1
+	exitPartial  = 2
+)
+
+// runImport handles the import subcommand, it runs local CSV files through
+// the upload pipeline straight against the database and returns the exit code
+func runImport(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print JSON lines instead of a progress bar")
+	tenantName := flags.String("tenant", "", "tenant to import into, the default tenant when empty")
+	term := flags.String("term", "", "term the grades belong to, e.g. 2024-fall")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return exitFailed
+	}
+
+	paths, err := collectCSV(flags.Args())
+	if err != nil {
+		log.Printf("Failed to list files: %v", err)
+		return exitFailed
+	}
+	if len(paths) == 0 {
+		log.Printf("No CSV files found")
+		return exitFailed
+	}
+
+	db, students, err := openStudents(cfg)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return exitFailed
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	tenantID := config.DefaultTenantID
+	if *tenantName != "" {
+		tenant, err := repository.NewTenantRepository(db).GetByName(*tenantName)
+		if err != nil {
+			log.Printf("Failed to find tenant: %v", err)
+			return exitFailed
+		}
+		tenantID = tenant.ID
+	}
+
+	pipeline := upload.Pipeline{
+		Students: students,
+		Courses:  repository.NewCourseRepository(db),
+		Grades:   repository.NewGradeRepository(db),
+		Settings: cfg.Import,
+	}
+
+	// Imports stop after the batch in flight, the committed rows are kept
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var report reporter = newProgressBar(os.Stderr)
+	if *asJSON {
+		report = newJSONLines(os.Stdout)
+	}
+	return importFiles(ctx, pipeline, tenantID, *term, paths, report)
+}
+
+// importFiles validates each file on its own so a bad file doesn't hold back
+// the others, imports the valid ones and returns the exit code
+func importFiles(
+	ctx context.Context,
+	pipeline upload.Pipeline,
+	tenantID uuid.UUID,
+	term string,
+	paths []string,
+	report reporter,
+) int {
+	var files []*os.File
+	var names []string
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	failed := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			report.done(path, err.Error())
+			failed++
+			continue
+		}
+		if err := pipeline.Validate([]*os.File{f}, tenantID, nil); err != nil {
+			f.Close()
+			report.done(path, err.Error())
+			failed++
+			continue
+		}
+		files = append(files, f)
+		names = append(names, path)
+	}
+
+	// A file is imported once its report says every record was stored, the
+	// progress reaches 100% as soon as the file is read so files cancelled
+	// with inserts pending would pass for imported. Reports are made before
+	// the status channel is closed
+	errs := make([]string, len(files))
+	complete := make([]bool, len(files))
+	done := processor.WithReport(func(id int, report processor.FileReport) {
+		complete[id] = report.Done
+	})
+
+	status := make(chan processor.ProcessStatus)
+	go pipeline.Import(ctx, files, tenantID, term, status, done)
+	for s := range status {
+		report.status(names[s.Id], s)
+		if s.Error != "" && errs[s.Id] == "" {
+			errs[s.Id] = s.Error
+		}
+	}
+
+	imported := 0
+	for i, name := range names {
+		switch {
+		case errs[i] != "":
+			failed++
+		case !complete[i]:
+			errs[i] = "interrupted"
+			failed++
+		default:
+			imported++
+		}
+		report.done(name, errs[i])
+	}
+
+	code := exitPartial
+	switch {
+	case failed == 0:
+		code = exitImported
+	case imported == 0:
+		code = exitFailed
+	}
+	report.summary(imported, failed, code)
+	return code
+}
+
+// collectCSV expands directories into the CSV files they hold, recursively
+// and in name order, files named explicitly are kept whatever their extension
+func collectCSV(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		var found []string
+		err = filepath.WalkDir(arg, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), ".csv") {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
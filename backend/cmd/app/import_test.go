@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/upload"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, _, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to initalize test DB: %v", err)
+	}
+	testDB = db
+
+	code := m.Run()
+
+	testDB.Migrator().DropTable(model.StudentTest{})
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
+
+// writeCSV writes the rows under the students header
+func writeCSV(t *testing.T, dir, name string, rows ...string) string {
+	path := filepath.Join(dir, name)
+	content := config.StudentsTableHeader + "\n" + strings.Join(rows, "\n") + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestCollectCSV(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fall"), 0o700))
+
+	b := writeCSV(t, dir, "b.csv")
+	a := writeCSV(t, dir, "fall/a.CSV")
+	writeCSV(t, dir, "notes.txt")
+	named := writeCSV(t, t.TempDir(), "export.txt")
+
+	paths, err := collectCSV([]string{dir, named})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b, a, named}, paths)
+
+	_, err = collectCSV([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
+
+// cancellingStudents cancels the import while inserting the second batch,
+// the first one is slow enough for the parser to read the whole file
+type cancellingStudents struct {
+	repository.StudentRepository[model.Student]
+	cancel context.CancelFunc
+	calls  *atomic.Int32
+}
+
+func (r cancellingStudents) CreateMany(items []*model.Student) error {
+	switch r.calls.Add(1) {
+	case 1:
+		time.Sleep(150 * time.Millisecond)
+	case 2:
+		r.cancel()
+		return context.Canceled
+	}
+	return r.StudentRepository.CreateMany(items)
+}
+
+func (r cancellingStudents) ForTenant(tenantID uuid.UUID) repository.StudentRepository[model.Student] {
+	return cancellingStudents{r.StudentRepository.ForTenant(tenantID), r.cancel, r.calls}
+}
+
+func TestImportFiles(t *testing.T) {
+	pipeline := upload.Pipeline{
+		Students: repository.NewStudentRepository[model.Student](testDB),
+		Courses:  repository.NewCourseRepository(testDB),
+		Grades:   repository.NewGradeRepository(testDB),
+		Settings: config.Default().Import,
+	}
+
+	row := func(subject string) string {
+		return uuid.NewString() + ",Ali," + subject + ",90"
+	}
+
+	cases := []struct {
+		name     string
+		files    map[string][]string
+		expected int
+		failed   []string
+	}{
+		{
+			name:     "every file imported",
+			files:    map[string][]string{"a.csv": {row("Physics"), row("Art")}, "b.csv": {row("Music")}},
+			expected: exitImported,
+		},
+		{
+			name:     "bad rows fail their file only",
+			files:    map[string][]string{"a.csv": {row("Physics")}, "b.csv": {"not-an-id,Ali,Physics,90"}},
+			expected: exitPartial,
+			failed:   []string{"b.csv"},
+		},
+		{
+			name:     "unknown subjects are rejected before importing",
+			files:    map[string][]string{"a.csv": {row("Alchemy")}},
+			expected: exitFailed,
+			failed:   []string{"a.csv"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var paths []string
+			for name, rows := range tt.files {
+				paths = append(paths, writeCSV(t, dir, name, rows...))
+			}
+
+			var out bytes.Buffer
+			code := importFiles(context.Background(), pipeline, config.DefaultTenantID, "", paths, newJSONLines(&out))
+			assert.Equal(t, tt.expected, code)
+
+			var failed []string
+			var summary importEvent
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				var event importEvent
+				require.NoError(t, json.Unmarshal([]byte(line), &event))
+
+				switch event.Event {
+				case "file":
+					if !*event.Imported {
+						failed = append(failed, filepath.Base(event.File))
+					}
+				case "summary":
+					summary = event
+				}
+			}
+			assert.ElementsMatch(t, tt.failed, failed)
+			require.NotNil(t, summary.Exit)
+			assert.Equal(t, tt.expected, *summary.Exit)
+			assert.Equal(t, len(tt.files), *summary.Files)
+		})
+	}
+
+	t.Run("interrupted files fail", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		path := writeCSV(t, t.TempDir(), "a.csv", row("Physics"))
+		code := importFiles(ctx, pipeline, config.DefaultTenantID, "", []string{path}, newProgressBar(&bytes.Buffer{}))
+		assert.Equal(t, exitFailed, code)
+	})
+
+	t.Run("files cancelled mid-file fail", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cancelled := pipeline
+		cancelled.Students = cancellingStudents{pipeline.Students, cancel, &atomic.Int32{}}
+		cancelled.Settings.BatchSize = 2
+		cancelled.Settings.InsertWorkers = 1
+
+		rows := make([]string, 20)
+		for i := range rows {
+			rows[i] = row("Physics")
+		}
+		path := writeCSV(t, t.TempDir(), "a.csv", rows...)
+
+		var out bytes.Buffer
+		code := importFiles(ctx, cancelled, config.DefaultTenantID, "", []string{path}, newJSONLines(&out))
+		assert.Equal(t, exitFailed, code)
+		assert.Contains(t, out.String(), `"percent":100`, "the whole file was read")
+		assert.Contains(t, out.String(), "interrupted")
+	})
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"file-uploader/config"
 	"file-uploader/database"
 	"file-uploader/database/model"
+	"file-uploader/database/repository"
 	"file-uploader/internal/api"
+	apimiddleware "file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/jobs"
+	"file-uploader/internal/service/metrics"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -20,35 +31,132 @@ func main() {
 		log.Printf("Error loading .env file: %v", err) // Use fatalf in local environment
 	}
 
-	// Get database connection string
-	dsn, exist := os.LookupEnv(config.DBEnvVar)
-	if !exist {
-		log.Fatalf("Database connection string not found in environment variables")
+	// Settings come from the defaults, a file, the environment and the
+	// flags, in that order
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Get server post
-	port, exist := os.LookupEnv(config.PortEnvVar)
-	if !exist {
-		log.Fatalf("Server port not found in environment variables")
+	// Printing the configuration must work while it is invalid, seeding files
+	// doesn't need it
+	if len(args) > 0 && args[0] == "config" {
+		runConfig(cfg, args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "seed" {
+		runSeed(cfg, args[1:])
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
 
-	db, studentsRepository, err := database.SetupDB[model.Student](dsn, false)
+	// Subcommands
+	if len(args) > 0 {
+		driver, dsn := cfg.Database.Driver, cfg.Database.DSN
+		switch args[0] {
+		case "migrate":
+			runMigrate(driver, dsn, args[1:])
+		case "user":
+			runUser(driver, dsn, args[1:])
+		case "tenant":
+			runTenant(driver, dsn, args[1:])
+		case "import":
+			os.Exit(runImport(cfg, args[1:]))
+		default:
+			log.Fatalf("Unknown command %q, expected migrate, user, tenant, import, seed or config", args[0])
+		}
+		return
+	}
+
+	serverMetrics := metrics.New()
+
+	db, studentsRepository, err := openStudents(cfg, repository.WithInsertObserver(serverMetrics.ObserveInsert))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if sqlDB, err := db.DB(); err == nil {
+		serverMetrics.WatchDB(sqlDB)
+	}
 
 	e := echo.New()
+	e.Use(apimiddleware.Metrics(serverMetrics))
 
-	// Add CORS middleware
+	// Add CORS middleware, credentials need explicit origins
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
-		AllowMethods: []string{echo.GET, echo.PUT, echo.POST, echo.DELETE},
+		AllowOrigins:     cfg.Server.CORSOrigins,
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowMethods:     []string{echo.GET, echo.PUT, echo.POST, echo.DELETE},
+		AllowCredentials: true,
 	}))
 
-	api.RegisterRoutes(e, db, studentsRepository)
+	// Uploads are processed by a fixed pool of workers
+	queue := jobs.NewQueue(cfg.Upload.MaxConcurrent, cfg.Upload.MaxQueued)
+
+	uploads := api.RegisterRoutes(e, db, studentsRepository, cfg, queue, serverMetrics)
+
+	// Metrics are still collected, but nothing can scrape them without a token
+	if cfg.Server.MetricsToken == "" {
+		log.Printf("Metrics are collected but /metrics is not served, set server.metrics_token (METRICS_TOKEN) to expose it")
+	}
+
+	// Pick up the uploads the last shutdown interrupted
+	if resumed, err := uploads.Resume(); err != nil {
+		log.Printf("Failed to resume uploads: %v", err)
+	} else if resumed > 0 {
+		log.Printf("Resumed %d interrupted uploads", resumed)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := e.Start(":" + strconv.Itoa(cfg.Server.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start HTTP server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	// Stop accepting requests, then let the workers drain the queued uploads.
+	// Past the deadline batches stop after the one in flight commits and the
+	// unfinished uploads are marked interrupted, they resume on the next start
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to stop HTTP server: %v", err)
+	}
+	if err := queue.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Uploads interrupted: %v", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	log.Printf("Server stopped")
+}
+
+// openStudents connects with the pool and insert settings of the config,
+// opts apply on top of them
+func openStudents(cfg *config.Config, opts ...repository.StudentRepoOption) (*gorm.DB, repository.StudentRepository[model.Student], error) {
+	db, students, err := database.SetupDB[model.Student](
+		cfg.Database.Driver,
+		cfg.Database.DSN,
+		false,
+		append([]repository.StudentRepoOption{
+			repository.WithInsertBatches(cfg.Database.InsertBatchSize, cfg.Database.InsertWorkers),
+			repository.WithInsertMethod(cfg.Database.InsertMethod),
+			repository.WithInsertLatency(cfg.Database.InsertLatency),
+		}, opts...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if err := e.Start(":" + port); err != nil {
-		log.Fatalf("Failed to start HTTP server: %v", err)
+	if err := database.ConfigurePool(db, cfg.Database); err != nil {
+		return nil, nil, err
 	}
+	return db, students, nil
 }
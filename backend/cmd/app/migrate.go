@@ -0,0 +1,76 @@
+package main
+
+import (
+	"file-uploader/config"
+	"file-uploader/database"
+	"file-uploader/database/migrations"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+const migrateUsage = "usage: app migrate <up|down [steps]|status>"
+
+// runMigrate handles the migrate subcommand
+func runMigrate(driver config.DBDriver, dsn string, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	db, err := database.Open(driver, dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		versions, err := migrator.Up()
+		for _, version := range versions {
+			fmt.Printf("applied %04d\n", version)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(versions) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatal(migrateUsage)
+			}
+		}
+
+		versions, err := migrator.Down(steps)
+		for _, version := range versions {
+			fmt.Printf("reverted %04d\n", version)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-30s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
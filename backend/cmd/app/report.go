@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	processor "file-uploader/internal/service/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reporter prints the progress of the import command
+type reporter interface {
+	// status is called for every status of a file being imported
+	status(file string, status processor.ProcessStatus)
+
+	// done is called once per file, err is empty when it was imported
+	done(file string, err string)
+
+	summary(imported, failed, code int)
+}
+
+// progressBar draws the mean progress of the files on a single line, meant
+// for a terminal
+type progressBar struct {
+	out     io.Writer
+	percent map[string]float64
+	width   int // of the last line drawn, to erase it
+}
+
+const barWidth = 30
+
+func newProgressBar(out io.Writer) *progressBar {
+	return &progressBar{out: out, percent: map[string]float64{}}
+}
+
+func (p *progressBar) status(file string, status processor.ProcessStatus) {
+	p.percent[file] = status.Percent
+
+	total, finished := 0.0, 0
+	for _, percent := range p.percent {
+		total += percent
+		if percent >= 100 {
+			finished++
+		}
+	}
+	mean := total / float64(len(p.percent))
+	filled := min(int(mean/100*barWidth), barWidth)
+
+	line := fmt.Sprintf("[%s%s] %3.0f%%  %d/%d files",
+		strings.Repeat("#", filled), strings.Repeat("-", barWidth-filled), mean, finished, len(p.percent))
+	p.erase()
+	fmt.Fprint(p.out, line)
+	p.width = len(line)
+}
+
+func (p *progressBar) done(file string, err string) {
+	p.erase()
+	if err != "" {
+		fmt.Fprintf(p.out, "failed   %s: %s\n", file, err)
+		return
+	}
+	fmt.Fprintf(p.out, "imported %s\n", file)
+}
+
+func (p *progressBar) summary(imported, failed, code int) {
+	p.erase()
+	fmt.Fprintf(p.out, "imported %d of %d files\n", imported, imported+failed)
+}
+
+func (p *progressBar) erase() {
+	if p.width > 0 {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", p.width))
+		p.width = 0
+	}
+}
+
+// jsonLines writes an event per line, meant for scripts
+type jsonLines struct {
+	encoder *json.Encoder
+}
+
+type importEvent struct {
+	Event    string   `json:"event"`
+	File     string   `json:"file,omitempty"`
+	Percent  *float64 `json:"percent,omitempty"`
+	Timeleft *float64 `json:"timeleft,omitempty"`
+	Imported *bool    `json:"imported,omitempty"`
+	Error    string   `json:"error,omitempty"`
+
+	// Summary counts, with the exit code
+	Files     *int `json:"files,omitempty"`
+	Succeeded *int `json:"succeeded,omitempty"`
+	Failed    *int `json:"failed,omitempty"`
+	Exit      *int `json:"exit,omitempty"`
+}
+
+func newJSONLines(out io.Writer) *jsonLines {
+	return &jsonLines{encoder: json.NewEncoder(out)}
+}
+
+func (j *jsonLines) status(file string, status processor.ProcessStatus) {
+	j.encoder.Encode(importEvent{
+		Event:    "progress",
+		File:     file,
+		Percent:  &status.Percent,
+		Timeleft: &status.Timeleft,
+		Error:    status.Error,
+	})
+}
+
+func (j *jsonLines) done(file string, err string) {
+	imported := err == ""
+	j.encoder.Encode(importEvent{Event: "file", File: file, Imported: &imported, Error: err})
+}
+
+func (j *jsonLines) summary(imported, failed, code int) {
+	files := imported + failed
+	j.encoder.Encode(importEvent{Event: "summary", Files: &files, Succeeded: &imported, Failed: &failed, Exit: &code})
+}
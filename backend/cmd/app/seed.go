@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	processor "file-uploader/internal/service/csv"
+	Seeder "file-uploader/internal/service/csv/seeder"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const seedUsage = "usage: app seed [-rows n] [-seed n] [-out path] [-format csv|xlsx|ndjson] [-subjects list] [-grades spec] [-bad-ids rate] [-bad-grades rate] [-duplicates rate] [-db] [-fixture name] [-fixtures dir] [-tenant name] [-reset]"
+
+// runSeed handles the seed subcommand, it writes synthetic students to a
+// file for load and negative testing, or with -db or -fixture inserts them
+// into the database
+func runSeed(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	rows := flags.Int("rows", 1000, "number of rows")
+	seed := flags.Int64("seed", 0, "seed of the generator, the same seed gives the same rows, random when 0")
+	out := flags.String("out", "-", "output file, - for stdout")
+	format := flags.String("format", "", "csv, xlsx or ndjson, from the extension of -out by default")
+	subjects := flags.String("subjects", "", "comma separated subjects, the default course catalog when empty")
+	grades := flags.String("grades", "", "grade distributions, e.g. Mathematics=normal:60:18,*=uniform:40:100")
+	badIDs := flags.Float64("bad-ids", 0, "share of rows with a malformed student id")
+	badGrades := flags.Float64("bad-grades", 0, "share of rows with a grade that isn't a number")
+	duplicates := flags.Float64("duplicates", 0, "share of rows repeating the student id of an earlier row")
+	toDB := flags.Bool("db", false, "insert the rows into the database instead of writing a file")
+	fixture := flags.String("fixture", "", "load the named fixture set into the database instead of generating rows")
+	fixtures := flags.String("fixtures", "fixtures", "directory of the fixture sets")
+	tenantName := flags.String("tenant", "", "tenant of the inserted rows, created when missing, the default tenant when empty")
+	reset := flags.Bool("reset", false, "remove the students of the tenant before inserting")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *rows < 0 {
+		log.Fatal(seedUsage)
+	}
+
+	distributions, err := Seeder.ParseDistributions(*grades)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outputFormat, err := Seeder.ParseFormat(*format, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Print the seed drawn so the rows can be generated again
+	if *seed == 0 && *fixture == "" {
+		*seed = time.Now().UnixNano()
+		fmt.Fprintf(os.Stderr, "seed %d\n", *seed)
+	}
+
+	opts := Seeder.Options{
+		Seed:          *seed,
+		Distributions: distributions,
+		Errors:        Seeder.ErrorRates{BadIDs: *badIDs, BadGrades: *badGrades, Duplicates: *duplicates},
+	}
+	for _, subject := range strings.Split(*subjects, ",") {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			opts.Subjects = append(opts.Subjects, subject)
+		}
+	}
+
+	generator, err := Seeder.NewGenerator(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *fixture != "" || *toDB {
+		if opts.Errors != (Seeder.ErrorRates{}) {
+			log.Fatal("Injected errors are only written to files")
+		}
+		seedDB(cfg, generator, *rows, *fixture, *fixtures, *tenantName, *reset)
+		return
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create output: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	if err := Seeder.Write(buffered, outputFormat, generator, *rows); err != nil {
+		log.Fatalf("Failed to write rows: %v", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		log.Fatalf("Failed to write rows: %v", err)
+	}
+}
+
+// seedDB loads the fixture set, or the generated rows when there is none,
+// through the repositories the uploads use
+func seedDB(cfg *config.Config, generator *Seeder.Generator, rows int, fixture, fixtures, tenantName string, reset bool) {
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	db, students, err := openStudents(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	loader := Seeder.Loader[model.Student]{
+		Students: repository.NewNormalizingRepository(students, repository.NewGradeRepository(db), ""),
+		Courses:  repository.NewCourseRepository(db),
+		Tenants:  repository.NewTenantRepository(db),
+		Mapper:   processor.StudentMapper,
+	}
+
+	if fixture != "" {
+		path, err := Seeder.FindFixture(fixtures, fixture)
+		if err != nil {
+			log.Fatal(err)
+		}
+		set, err := Seeder.ReadFixture(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// The tenant of the command wins over the one of the file
+		if tenantName != "" {
+			set.Tenant = tenantName
+		}
+
+		inserted, err := loader.Load(set, reset)
+		if err != nil {
+			log.Fatalf("Failed to load fixture: %v", err)
+		}
+		fmt.Printf("loaded %d students from %s\n", inserted, path)
+		return
+	}
+
+	tenantID, err := loader.Tenant(tenantName)
+	if err != nil {
+		log.Fatalf("Failed to find tenant: %v", err)
+	}
+	if reset {
+		deleted, err := loader.Reset(tenantID)
+		if err != nil {
+			log.Fatalf("Failed to reset students: %v", err)
+		}
+		fmt.Printf("removed %d students\n", deleted)
+	}
+
+	inserted, err := loader.Generate(tenantID, generator, rows)
+	if err != nil {
+		log.Fatalf("Failed to insert students: %v", err)
+	}
+	fmt.Printf("inserted %d students\n", inserted)
+}
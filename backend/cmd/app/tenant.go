@@ -0,0 +1,61 @@
+package main
+
+import (
+	"file-uploader/config"
+	"file-uploader/database"
+	"file-uploader/database/migrations"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"fmt"
+	"log"
+)
+
+const tenantUsage = "usage: app tenant <create <name>|list>"
+
+// runTenant handles the tenant subcommand, every school is a tenant with its
+// own students, courses and users
+func runTenant(driver config.DBDriver, dsn string, args []string) {
+	if len(args) == 0 {
+		log.Fatal(tenantUsage)
+	}
+
+	db, err := database.Open(driver, dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	tenants := repository.NewTenantRepository(db)
+
+	switch args[0] {
+	case "create":
+		if len(args) < 2 {
+			log.Fatal(tenantUsage)
+		}
+
+		tenant := &model.Tenant{Name: args[1]}
+		if err := tenants.Create(tenant); err != nil {
+			log.Fatalf("Failed to create tenant: %v", err)
+		}
+		fmt.Printf("created tenant %s (%s)\n", tenant.Name, tenant.ID)
+
+	case "list":
+		list, err := tenants.List()
+		if err != nil {
+			log.Fatalf("Failed to list tenants: %v", err)
+		}
+		for _, tenant := range list {
+			fmt.Printf("%s\t%s\n", tenant.ID, tenant.Name)
+		}
+
+	default:
+		log.Fatal(tenantUsage)
+	}
+}
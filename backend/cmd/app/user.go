@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"file-uploader/config"
+	"file-uploader/database"
+	"file-uploader/database/migrations"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/service/auth"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const userUsage = "usage: app user <create <email> [tenant]|token <email> <name> [ttl]|role <email> <viewer|uploader|admin|platform_admin>|subjects <email> [subject...]|scope <email> <all|assigned>>, passwords are read from stdin"
+
+// runUser handles the user subcommand, accounts are created by operators
+// since the api has no sign up
+func runUser(driver config.DBDriver, dsn string, args []string) {
+	if len(args) < 2 {
+		log.Fatal(userUsage)
+	}
+
+	db, err := database.Open(driver, dsn)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	service := auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+
+	switch args[0] {
+	case "create":
+		fmt.Fprint(os.Stderr, "password: ")
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+
+		// Users belong to the default tenant unless one is named
+		tenantID := config.DefaultTenantID
+		if len(args) > 2 {
+			tenant, err := repository.NewTenantRepository(db).GetByName(args[2])
+			if err != nil {
+				log.Fatalf("Failed to find tenant: %v", err)
+			}
+			tenantID = tenant.ID
+		}
+
+		user, err := service.RegisterIn(tenantID, args[1], strings.TrimRight(password, "\r\n"))
+		if err != nil {
+			log.Fatalf("Failed to create user: %v", err)
+		}
+		fmt.Printf("created user %s (%s)\n", user.Email, user.ID)
+
+	case "token":
+		if len(args) < 3 {
+			log.Fatal(userUsage)
+		}
+
+		var ttl time.Duration
+		if len(args) > 3 {
+			ttl, err = time.ParseDuration(args[3])
+			if err != nil {
+				log.Fatal(userUsage)
+			}
+		}
+
+		user := findUser(service, args[1])
+		token, _, err := service.CreateAPIToken(user.ID, args[2], ttl)
+		if err != nil {
+			log.Fatalf("Failed to create api token: %v", err)
+		}
+		fmt.Println(token)
+
+	case "role":
+		if len(args) < 3 {
+			log.Fatal(userUsage)
+		}
+
+		user := findUser(service, args[1])
+		if err := service.Users.SetRole(user.ID, config.Role(args[2])); err != nil {
+			log.Fatalf("Failed to set role: %v", err)
+		}
+		fmt.Printf("%s is now %s\n", user.Email, args[2])
+
+	case "subjects":
+		// Assigning subjects limits the user to them, none to no subject
+		user := findUser(service, args[1])
+		if err := service.Users.SetSubjects(user.ID, args[2:]); err != nil {
+			log.Fatalf("Failed to set subjects: %v", err)
+		}
+		if len(args) == 2 {
+			fmt.Printf("%s sees no subject\n", user.Email)
+		} else {
+			fmt.Printf("%s is limited to %s\n", user.Email, strings.Join(args[2:], ", "))
+		}
+
+	case "scope":
+		if len(args) < 3 || (args[2] != "all" && args[2] != "assigned") {
+			log.Fatal(userUsage)
+		}
+
+		user := findUser(service, args[1])
+		if err := service.Users.SetAllSubjects(user.ID, args[2] == "all"); err != nil {
+			log.Fatalf("Failed to set scope: %v", err)
+		}
+		if args[2] == "all" {
+			fmt.Printf("%s sees every subject\n", user.Email)
+		} else {
+			fmt.Printf("%s sees the assigned subjects\n", user.Email)
+		}
+
+	default:
+		log.Fatal(userUsage)
+	}
+}
+
+func findUser(service *auth.Service, email string) *model.User {
+	user, err := service.Users.GetByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		log.Fatalf("Failed to find user: %v", err)
+	}
+	return user
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the application, Load fills it from its
+// sources
+type Config struct {
+	Server   Server
+	Database Database
+	Auth     Auth
+	Upload   Upload
+	Import   Import
+	Query    Query
+
+	// sources tells where each setting came from, by key
+	sources map[string]string
+}
+
+type Server struct {
+	Port        int
+	CORSOrigins []string
+
+	// ShutdownTimeout bounds how long shutdown waits for queued uploads
+	ShutdownTimeout time.Duration
+
+	// MetricsToken is the bearer token scrapers send to /metrics, which
+	// isn't served without one
+	MetricsToken string
+}
+
+type Database struct {
+	Driver DBDriver
+	DSN    string
+
+	// Inserts are split across workers, each inserting in batches, unless
+	// InsertMethod is InsertCopy. The workers bound the statements running
+	// at once across every insert, zero takes half the pool, the batch size
+	// adapts to InsertLatency unless it is zero
+	InsertBatchSize int
+	InsertWorkers   int
+	InsertMethod    InsertMethod
+	InsertLatency   time.Duration
+
+	// Pool of connections, SQLite always uses a single one
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+type Auth struct {
+	SessionTTL time.Duration
+}
+
+// Upload bounds what a single upload may contain and how many uploads are
+// processed at once, zero disables a size, count or rate limit
+type Upload struct {
+	MaxBodyBytes int64
+	MaxFileBytes int64
+	MaxFiles     int
+	MaxRows      int // per file, the header excluded
+
+	// RatePerMinute is the number of uploads a client may start per minute
+	RatePerMinute int
+
+	// MaxConcurrent uploads are processed at once by the job queue workers,
+	// up to MaxQueued more wait in line and the rest are turned away
+	MaxConcurrent int
+	MaxQueued     int
+
+	// SpoolDir holds the uploaded files until they are imported
+	SpoolDir string
+}
+
+// Import tunes the CSV processing of each upload
+type Import struct {
+	// BatchSize records are read before each insert
+	BatchSize int
+
+	// FileWorkers files of an upload are processed at once
+	FileWorkers int
+
+	// InsertWorkers batches of a file are inserted at once while the next
+	// ones are read, zero reads and inserts in turn
+	InsertWorkers int
+}
+
+type Query struct {
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            8080,
+			CORSOrigins:     []string{"http://localhost:5173"},
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: Database{
+			Driver:          DriverPostgres,
+			InsertBatchSize: 500,
+			InsertMethod:    InsertBatches,
+			InsertLatency:   200 * time.Millisecond,
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Auth: Auth{
+			SessionTTL: 7 * 24 * time.Hour,
+		},
+		Upload: Upload{
+			MaxBodyBytes:  100 << 20,
+			MaxFileBytes:  50 << 20,
+			MaxFiles:      10,
+			MaxRows:       1_000_000,
+			RatePerMinute: 10,
+			MaxConcurrent: 4,
+			MaxQueued:     16,
+		},
+		Import: Import{
+			BatchSize:     2000,
+			FileWorkers:   10,
+			InsertWorkers: 2,
+		},
+		Query: Query{
+			DefaultPageSize: 100,
+			MaxPageSize:     1000,
+		},
+		sources: map[string]string{},
+	}
+}
+
+// setting binds a key of the configuration file to its environment
+// variable, its flag and the field it sets
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	value  flag.Value
+	secret bool
+}
+
+func (c *Config) settings() []*setting {
+	return []*setting{
+		{key: "server.port", env: PortEnvVar, usage: "HTTP port", value: intVar(&c.Server.Port)},
+		{key: "server.cors_origins", env: CORSOriginsEnvVar, usage: "comma separated origins allowed to send credentials", value: listVar(&c.Server.CORSOrigins)},
+		{key: "server.shutdown_timeout", env: ShutdownTimeoutEnvVar, usage: "how long shutdown waits for queued uploads", value: durationVar(&c.Server.ShutdownTimeout)},
+		{key: "server.metrics_token", env: MetricsTokenEnvVar, usage: "bearer token of /metrics, empty doesn't serve it", value: stringVar(&c.Server.MetricsToken), secret: true},
+
+		{key: "database.driver", env: DBDriverEnvVar, usage: "postgres or sqlite", value: driverVar(&c.Database.Driver)},
+		{key: "database.dsn", env: DBEnvVar, usage: "connection string, a file path for sqlite", value: stringVar(&c.Database.DSN), secret: true},
+		{key: "database.insert_batch_size", env: DBInsertBatchEnvVar, usage: "rows of the first insert statement", value: intVar(&c.Database.InsertBatchSize)},
+		{key: "database.insert_workers", env: DBInsertWorkersEnvVar, usage: "insert statements running at once across imports, 0 takes half the pool", value: intVar(&c.Database.InsertWorkers)},
+		{key: "database.insert_method", env: DBInsertMethodEnvVar, usage: "batches or copy, copy needs postgres", value: insertMethodVar(&c.Database.InsertMethod)},
+		{key: "database.insert_latency", env: DBInsertLatencyEnvVar, usage: "time an insert statement is sized for, 0 keeps the batch size", value: durationVar(&c.Database.InsertLatency)},
+		{key: "database.max_open_conns", env: DBMaxOpenConnsEnvVar, usage: "connections open at once, 0 is unlimited", value: intVar(&c.Database.MaxOpenConns)},
+		{key: "database.max_idle_conns", env: DBMaxIdleConnsEnvVar, usage: "connections kept open while idle", value: intVar(&c.Database.MaxIdleConns)},
+		{key: "database.conn_max_lifetime", env: DBConnMaxLifetimeEnvVar, usage: "time a connection is reused, 0 is forever", value: durationVar(&c.Database.ConnMaxLifetime)},
+
+		{key: "auth.session_ttl", env: SessionTTLEnvVar, usage: "lifetime of login sessions", value: durationVar(&c.Auth.SessionTTL)},
+
+		{key: "upload.max_body_bytes", env: UploadMaxBodyEnvVar, usage: "largest upload request, 0 for no limit", value: int64Var(&c.Upload.MaxBodyBytes)},
+		{key: "upload.max_file_bytes", env: UploadMaxFileEnvVar, usage: "largest uploaded file, 0 for no limit", value: int64Var(&c.Upload.MaxFileBytes)},
+		{key: "upload.max_files", env: UploadMaxFilesEnvVar, usage: "files per upload, 0 for no limit", value: intVar(&c.Upload.MaxFiles)},
+		{key: "upload.max_rows", env: UploadMaxRowsEnvVar, usage: "rows per file, 0 for no limit", value: intVar(&c.Upload.MaxRows)},
+		{key: "upload.rate_per_minute", env: UploadRateEnvVar, usage: "uploads a user may start per minute, 0 for no limit", value: intVar(&c.Upload.RatePerMinute)},
+		{key: "upload.max_concurrent", env: UploadMaxConcurrentEnvVar, usage: "uploads processed at once", value: intVar(&c.Upload.MaxConcurrent)},
+		{key: "upload.max_queued", env: UploadMaxQueuedEnvVar, usage: "uploads waiting for a worker", value: intVar(&c.Upload.MaxQueued)},
+		{key: "upload.spool_dir", env: UploadSpoolEnvVar, usage: "directory of files waiting to be imported, empty for the temp directory", value: stringVar(&c.Upload.SpoolDir)},
+
+		{key: "import.batch_size", env: ImportBatchEnvVar, usage: "records read before each insert", value: intVar(&c.Import.BatchSize)},
+		{key: "import.file_workers", env: ImportFileWorkersEnvVar, usage: "files of an upload processed at once", value: intVar(&c.Import.FileWorkers)},
+		{key: "import.insert_workers", env: ImportInsertWorkersEnvVar, usage: "batches of a file inserted at once while reading on, 0 reads and inserts in turn", value: intVar(&c.Import.InsertWorkers)},
+
+		{key: "query.default_page_size", env: DefaultPageSizeEnvVar, usage: "students per page when size is missing or too large", value: intVar(&c.Query.DefaultPageSize)},
+		{key: "query.max_page_size", env: MaxPageSizeEnvVar, usage: "largest page of students", value: intVar(&c.Query.MaxPageSize)},
+	}
+}
+
+// Load reads the configuration from the defaults, then the file named
+// by -config or CONFIG_FILE, then the environment, then the flags, a later
+// source overrides an earlier one. It returns the arguments after the flags,
+// e.g. a subcommand. The configuration isn't validated
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	// Flags are parsed first to find the file, and applied last
+	var flagged []func() error
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	file := flags.String("config", os.Getenv(ConfigFileEnvVar), "YAML or TOML configuration file")
+	for _, s := range settings {
+		flags.Var(&deferredValue{setting: s, apply: func(raw string) {
+			flagged = append(flagged, func() error { return cfg.set(s, raw, SourceFlag) })
+		}}, flagName(s.key), s.usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if *file != "" {
+		if err := cfg.loadFile(*file, settings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if raw, exist := os.LookupEnv(s.env); exist && raw != "" {
+			if err := cfg.set(s, raw, SourceEnv+" "+s.env); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, apply := range flagged {
+		if err := apply(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+func (c *Config) set(s *setting, raw string, source string) error {
+	if err := s.value.Set(raw); err != nil {
+		return fmt.Errorf("%w: %s from %s: %v", ErrInvalidConfig, s.key, source, err)
+	}
+	c.sources[s.key] = source
+	return nil
+}
+
+// loadFile applies the settings of a YAML or, by its .toml extension, TOML
+// file made of sections of keys, unknown keys are rejected so typos don't go
+// unnoticed
+func (c *Config) loadFile(path string, settings []*setting) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	var sections map[string]map[string]any
+	unmarshal := yaml.Unmarshal
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		unmarshal = toml.Unmarshal
+	}
+	if err := unmarshal(content, &sections); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	byKey := make(map[string]*setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+
+	for section, values := range sections {
+		for name, value := range values {
+			key := section + "." + name
+			s, exist := byKey[key]
+			if !exist {
+				return fmt.Errorf("%w: unknown setting %s in %s", ErrInvalidConfig, key, path)
+			}
+
+			raw := fmt.Sprint(value)
+			if list, ok := value.([]any); ok {
+				items := make([]string, len(list))
+				for i, item := range list {
+					items[i] = fmt.Sprint(item)
+				}
+				raw = strings.Join(items, ",")
+			}
+			if err := c.set(s, raw, SourceFile+" "+path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate reports every setting out of range
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be between 1 and 65535")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	check(c.Database.Driver == DriverPostgres || c.Database.Driver == DriverSQLite, "database.driver must be postgres or sqlite")
+	check(c.Database.DSN != "", "database.dsn is required, set %s", DBEnvVar)
+	check(c.Database.InsertBatchSize > 0, "database.insert_batch_size must be positive")
+	check(c.Database.InsertWorkers >= 0, "database.insert_workers can't be negative")
+	check(c.Database.InsertLatency >= 0, "database.insert_latency can't be negative")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns can't be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns can't be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime can't be negative")
+	check(c.Database.InsertMethod != InsertCopy || c.Database.Driver == DriverPostgres, "database.insert_method copy needs the postgres driver")
+
+	check(c.Auth.SessionTTL > 0, "auth.session_ttl must be positive")
+
+	check(c.Upload.MaxBodyBytes >= 0, "upload.max_body_bytes can't be negative")
+	check(c.Upload.MaxFileBytes >= 0, "upload.max_file_bytes can't be negative")
+	check(c.Upload.MaxFiles >= 0, "upload.max_files can't be negative")
+	check(c.Upload.MaxRows >= 0, "upload.max_rows can't be negative")
+	check(c.Upload.RatePerMinute >= 0, "upload.rate_per_minute can't be negative")
+	check(c.Upload.MaxConcurrent > 0, "upload.max_concurrent must be at least 1")
+	check(c.Upload.MaxQueued >= 0, "upload.max_queued can't be negative")
+
+	check(c.Import.BatchSize > 0, "import.batch_size must be positive")
+	check(c.Import.FileWorkers > 0, "import.file_workers must be positive")
+	check(c.Import.InsertWorkers >= 0, "import.insert_workers can't be negative")
+
+	check(c.Query.DefaultPageSize > 0, "query.default_page_size must be positive")
+	check(c.Query.MaxPageSize >= c.Query.DefaultPageSize, "query.max_page_size can't be below query.default_page_size")
+
+	return errors.Join(problems...)
+}
+
+// Source tells where a setting came from, e.g. "env SERVER_PORT"
+func (c *Config) Source(key string) string {
+	if source, exist := c.sources[key]; exist {
+		return source
+	}
+	return SourceDefault
+}
+
+// Print writes the effective settings with their source, secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SETTING\tVALUE\tSOURCE")
+	for _, s := range c.settings() {
+		value := s.value.String()
+		if s.secret {
+			value = redact(value)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", s.key, value, c.Source(s.key))
+	}
+	return table.Flush()
+}
+
+var passwordParam = regexp.MustCompile(`(password=)\S+`)
+
+// redact hides the password of a connection string
+func redact(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			return u.String()
+		}
+	}
+	return passwordParam.ReplaceAllString(dsn, "${1}xxxxx")
+}
+
+// flagName turns server.cors_origins into server-cors-origins
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
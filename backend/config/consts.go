@@ -1,21 +1,116 @@
 package config
 
+import "github.com/google/uuid"
+
 type StudentCol string
 type SortOrder string
 type Course string
+type View string
+type DBDriver string
+type InsertMethod string
+type SearchMode string
+type Role string
+type ImportStatus string
 
 const (
-	DBEnvVar   = "DB_DSN_LOCAL"
-	PortEnvVar = "SERVER_PORT"
+	DBEnvVar          = "DB_DSN_LOCAL"
+	DBDriverEnvVar    = "DB_DRIVER"
+	TestDriverEnvVar  = "TEST_DB_DRIVER"
+	PortEnvVar        = "SERVER_PORT"
+	CORSOriginsEnvVar = "CORS_ORIGINS"
+
+	// ConfigFileEnvVar names a YAML or TOML file of settings, the -config flag wins
+	ConfigFileEnvVar = "CONFIG_FILE"
+
+	ShutdownTimeoutEnvVar = "SHUTDOWN_TIMEOUT"
+	MetricsTokenEnvVar    = "METRICS_TOKEN"
+	SessionTTLEnvVar      = "SESSION_TTL"
+	DBInsertBatchEnvVar   = "DB_INSERT_BATCH_SIZE"
+	DBInsertWorkersEnvVar = "DB_INSERT_WORKERS"
+	DBInsertMethodEnvVar  = "DB_INSERT_METHOD"
+	DBInsertLatencyEnvVar = "DB_INSERT_LATENCY"
+
+	DBMaxOpenConnsEnvVar    = "DB_MAX_OPEN_CONNS"
+	DBMaxIdleConnsEnvVar    = "DB_MAX_IDLE_CONNS"
+	DBConnMaxLifetimeEnvVar = "DB_CONN_MAX_LIFETIME"
+
+	ImportBatchEnvVar         = "IMPORT_BATCH_SIZE"
+	ImportFileWorkersEnvVar   = "IMPORT_FILE_WORKERS"
+	ImportInsertWorkersEnvVar = "IMPORT_INSERT_WORKERS"
+	DefaultPageSizeEnvVar     = "PAGE_SIZE_DEFAULT"
+	MaxPageSizeEnvVar         = "PAGE_SIZE_MAX"
+
+	// Upload limits, zero disables a size, count or rate limit
+	UploadMaxBodyEnvVar       = "UPLOAD_MAX_BODY_BYTES"
+	UploadMaxFileEnvVar       = "UPLOAD_MAX_FILE_BYTES"
+	UploadMaxFilesEnvVar      = "UPLOAD_MAX_FILES"
+	UploadMaxRowsEnvVar       = "UPLOAD_MAX_ROWS"
+	UploadRateEnvVar          = "UPLOAD_RATE_PER_MINUTE"
+	UploadMaxConcurrentEnvVar = "UPLOAD_MAX_CONCURRENT"
+	UploadMaxQueuedEnvVar     = "UPLOAD_MAX_QUEUED"
+
+	// UploadSpoolEnvVar is where uploaded files wait to be imported
+	UploadSpoolEnvVar = "UPLOAD_SPOOL_DIR"
+
+	// SessionCookie holds the session token of browser logins
+	SessionCookie = "session"
+
+	// APITokenPrefix marks api tokens apart from session tokens
+	APITokenPrefix = "fu_"
+
+	// SessionTokenPrefix marks session tokens, their random part may start
+	// like an api token otherwise
+	SessionTokenPrefix = "fus_"
+
+	DriverPostgres DBDriver = "postgres"
+	DriverSQLite   DBDriver = "sqlite"
+
+	// InsertBatches sends multi-row INSERTs, InsertCopy streams the rows with
+	// COPY and only applies to postgres
+	InsertBatches InsertMethod = "batches"
+	InsertCopy    InsertMethod = "copy"
 
 	Id      StudentCol = "Student_id"
 	Name    StudentCol = "Student_name"
 	Subject StudentCol = "Subject"
 	Grade   StudentCol = "Grade"
 
+	// TenantId scopes every row to a school, it is never filterable or returned
+	TenantId StudentCol = "Tenant_id"
+
+	// ImportId is the upload that stored a row, to roll it back
+	ImportId StudentCol = "Import_id"
+
 	SortAsc  SortOrder = "asc"
 	SortDesc SortOrder = "desc"
 
+	ViewFlat   View = "flat"
+	ViewNested View = "nested"
+
+	SearchSubstring SearchMode = "substring"
+	SearchToken     SearchMode = "token"
+	SearchFuzzy     SearchMode = "fuzzy"
+
+	// Roles are ordered, each role can do everything the previous one can.
+	// Platform admins also manage what every tenant shares
+	RoleViewer        Role = "viewer"
+	RoleUploader      Role = "uploader"
+	RoleAdmin         Role = "admin"
+	RolePlatformAdmin Role = "platform_admin"
+
+	// Uploads are queued, run, then end completed, failed or interrupted by
+	// a shutdown, interrupted uploads resume on the next start. Uploads that
+	// ended may be rolled back
+	ImportQueued      ImportStatus = "queued"
+	ImportRunning     ImportStatus = "running"
+	ImportCompleted   ImportStatus = "completed"
+	ImportFailed      ImportStatus = "failed"
+	ImportInterrupted ImportStatus = "interrupted"
+	ImportRolledBack  ImportStatus = "rolled_back"
+
+	// DefaultGradingScale applies to courses without a grading scale
+	DefaultGradingScale = "letter"
+
 	Mathematics Course = "Mathematics"
 	Physics     Course = "Physics"
 	Chemistry   Course = "Chemistry"
@@ -29,3 +124,21 @@ const (
 
 	StudentsTableHeader = "student_id,student_name,subject,grade"
 )
+
+// DefaultTenantID owns rows stored before tenants existed and rows written
+// without a tenant
+var DefaultTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// DefaultCourses seeds the course catalog on a fresh database
+var DefaultCourses = []Course{
+	Mathematics,
+	Physics,
+	Chemistry,
+	Biology,
+	History,
+	EnglishLit,
+	CompSci,
+	Art,
+	Music,
+	Geography,
+}
@@ -3,26 +3,90 @@ package config
 import "errors"
 
 var (
-	ErrFailedDBConnection = errors.New("failed to connect to postgress db")
+	ErrFailedDBConnection = errors.New("failed to connect to database")
 	ErrFailedMigration    = errors.New("failed migration")
+	ErrUnsupportedDriver  = errors.New("unsupported database driver")
+	ErrUnsupportedInsert  = errors.New("unsupported insert method")
+	ErrInvalidConfig      = errors.New("invalid configuration")
 	ErrEnvVarNotFound     = errors.New("environment variable doesn't exist")
 	ErrDotEnvNotLoaded    = errors.New("error loading .env file")
 	ErrFieldNotFound      = errors.New("field not found")
 	ErrMissingStudentData = errors.New("student data are missing, required name, subject and grade")
 	ErrStudentNotExist    = errors.New("student does not exist")
+	ErrDuplicateStudent   = errors.New("student id already exists")
+	ErrMissingCourseData  = errors.New("course data are missing, required name")
+	ErrCourseNotExist     = errors.New("course does not exist")
+	ErrCourseAlreadyExist = errors.New("course already exists")
+	ErrCourseInUse        = errors.New("course is referenced by student records")
+	ErrUnknownCourse      = errors.New("unknown course")
+
+	ErrMissingGradingScaleData  = errors.New("grading scale data are missing, required name and bands")
+	ErrInvalidGradingScale      = errors.New("invalid grading scale")
+	ErrGradingScaleNotExist     = errors.New("grading scale does not exist")
+	ErrGradingScaleAlreadyExist = errors.New("grading scale already exists")
+	ErrGradingScaleInUse        = errors.New("grading scale is the default or assigned to courses")
+
+	ErrMissingUserData    = errors.New("user data are missing, required email and a password of at least 8 characters")
+	ErrUserAlreadyExist   = errors.New("user already exists")
+	ErrUserNotExist       = errors.New("user does not exist")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUnauthenticated    = errors.New("missing, invalid or expired credentials")
+	ErrTokenNotExist      = errors.New("api token does not exist")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrSubjectNotAssigned = errors.New("subject is not assigned to the user")
+
+	ErrMissingTenantData  = errors.New("tenant data are missing, required name")
+	ErrTenantAlreadyExist = errors.New("tenant already exists")
+	ErrTenantNotExist     = errors.New("tenant does not exist")
+
+	ErrTooManyRows       = errors.New("file has too many rows")
+	ErrQueueFull         = errors.New("job queue is full")
+	ErrQueueClosed       = errors.New("job queue is shut down")
+	ErrInvalidPriority   = errors.New("invalid priority, expected low, normal or high")
+	ErrImportJobNotExist = errors.New("import job does not exist")
+	ErrImportJobRunning  = errors.New("import job has not ended")
+
+	ErrInvalidDistribution = errors.New("invalid grade distribution, expected normal:<mean>:<stddev> or uniform:<min>:<max>")
+	ErrInvalidErrorRate    = errors.New("invalid error rate, rates are between 0 and 1 and add up to at most 1")
+	ErrUnsupportedFormat   = errors.New("unsupported format, expected csv, xlsx or ndjson")
+	ErrFixtureNotExist     = errors.New("fixture does not exist")
+	ErrInvalidFixture      = errors.New("invalid fixture")
 )
 
 const (
-	ErrFormParseFailureHttp    = "Failed to parse multipart form"
-	ErrNoFilesProvidedHttp     = "No files were provided for upload"
-	ErrDBConfigNotFoundHttp    = "Database configuration not found"
-	ErrDBConnectionFailureHttp = "Failed to connect to database"
-	ErrFileOpenFailureHttp     = "Failed to open uploaded file"
-	ErrProcessingFailureHttp   = "Failed to process CSV data"
-	ErrInvalidFileTypeHttp     = "Invalid File type"
-	ErrInvalidCSVCols          = "Invalid CSV columns"
-	ErrInvalidFilterHttp       = "Invalid filter"
-	ErrMissingPathParamHttp    = "Missing path parameter"
-	ErrMissingSearchParamHttp  = "Missing search parameter"
-	ErrInvalidSearchParamHttp  = "Invalid search parameter"
+	ErrFormParseFailureHttp     = "Failed to parse multipart form"
+	ErrNoFilesProvidedHttp      = "No files were provided for upload"
+	ErrDBConfigNotFoundHttp     = "Database configuration not found"
+	ErrDBConnectionFailureHttp  = "Failed to connect to database"
+	ErrFileOpenFailureHttp      = "Failed to open uploaded file"
+	ErrProcessingFailureHttp    = "Failed to process CSV data"
+	ErrInvalidFileTypeHttp      = "Invalid File type"
+	ErrInvalidCSVCols           = "Invalid CSV columns"
+	ErrInvalidFilterHttp        = "Invalid filter"
+	ErrMissingPathParamHttp     = "Missing path parameter"
+	ErrMissingSearchParamHttp   = "Missing search parameter"
+	ErrInvalidSearchParamHttp   = "Invalid search parameter"
+	ErrInvalidRequestBodyHttp   = "Invalid request body"
+	ErrCourseNotFoundHttp       = "Course not found"
+	ErrCourseConflictHttp       = "Course already exists"
+	ErrCourseInUseHttp          = "Course is referenced by student records"
+	ErrGradingScaleNotFoundHttp = "Grading scale not found"
+	ErrGradingScaleConflictHttp = "Grading scale already exists"
+	ErrGradingScaleInUseHttp    = "Grading scale is the default or assigned to courses"
+	ErrUnauthorizedHttp         = "Authentication required"
+	ErrInvalidCredentialsHttp   = "Invalid email or password"
+	ErrTokenNotFoundHttp        = "API token not found"
+	ErrForbiddenHttp            = "Permission denied"
+	ErrBodyTooLargeHttp         = "Upload is too large"
+	ErrFileTooLargeHttp         = "File is too large"
+	ErrTooManyFilesHttp         = "Too many files in one upload"
+	ErrTooManyRowsHttp          = "File has too many rows"
+	ErrRateLimitedHttp          = "Too many uploads, try again later"
+	ErrUploadQueueFullHttp      = "Too many uploads in progress, try again later"
+	ErrShuttingDownHttp         = "Server is shutting down, try again later"
+	ErrInvalidPriorityHttp      = "Invalid priority, expected low, normal or high"
+	ErrImportInterruptedHttp    = "Upload interrupted by a shutdown, it resumes when the server restarts"
+	ErrStudentNotFoundHttp      = "Student not found"
+	ErrUploadNotFoundHttp       = "Upload not found"
+	ErrUploadRunningHttp        = "Upload is queued or running, roll it back once it has ended"
 )
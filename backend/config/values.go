@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// value is a flag.Value writing straight into a field of the configuration
+type value[T any] struct {
+	target *T
+	parse  func(string) (T, error)
+	format func(T) string
+}
+
+func (v *value[T]) Set(raw string) error {
+	parsed, err := v.parse(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+	*v.target = parsed
+	return nil
+}
+
+func (v *value[T]) String() string {
+	if v == nil || v.target == nil {
+		return ""
+	}
+	return v.format(*v.target)
+}
+
+func intVar(target *int) *value[int] {
+	return &value[int]{target: target, parse: strconv.Atoi, format: strconv.Itoa}
+}
+
+func int64Var(target *int64) *value[int64] {
+	return &value[int64]{
+		target: target,
+		parse:  func(raw string) (int64, error) { return strconv.ParseInt(raw, 10, 64) },
+		format: func(v int64) string { return strconv.FormatInt(v, 10) },
+	}
+}
+
+func stringVar(target *string) *value[string] {
+	return &value[string]{
+		target: target,
+		parse:  func(raw string) (string, error) { return raw, nil },
+		format: func(v string) string { return v },
+	}
+}
+
+func durationVar(target *time.Duration) *value[time.Duration] {
+	return &value[time.Duration]{target: target, parse: time.ParseDuration, format: time.Duration.String}
+}
+
+// listVar reads a comma separated list, blank items are dropped
+func listVar(target *[]string) *value[[]string] {
+	return &value[[]string]{
+		target: target,
+		parse: func(raw string) ([]string, error) {
+			var items []string
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return items, nil
+		},
+		format: func(v []string) string { return strings.Join(v, ",") },
+	}
+}
+
+func driverVar(target *DBDriver) *value[DBDriver] {
+	return &value[DBDriver]{
+		target: target,
+		parse: func(raw string) (DBDriver, error) {
+			driver := DBDriver(strings.ToLower(raw))
+			if driver != DriverPostgres && driver != DriverSQLite {
+				return "", fmt.Errorf("%w %q", ErrUnsupportedDriver, raw)
+			}
+			return driver, nil
+		},
+		format: func(v DBDriver) string { return string(v) },
+	}
+}
+
+func insertMethodVar(target *InsertMethod) *value[InsertMethod] {
+	return &value[InsertMethod]{
+		target: target,
+		parse: func(raw string) (InsertMethod, error) {
+			method := InsertMethod(strings.ToLower(raw))
+			if method != InsertBatches && method != InsertCopy {
+				return "", fmt.Errorf("%w %q", ErrUnsupportedInsert, raw)
+			}
+			return method, nil
+		},
+		format: func(v InsertMethod) string { return string(v) },
+	}
+}
+
+// deferredValue records a flag so it is applied after the file and the
+// environment, it shows the default in the usage
+type deferredValue struct {
+	setting *setting
+	apply   func(raw string)
+}
+
+func (d *deferredValue) Set(raw string) error {
+	d.apply(raw)
+	return nil
+}
+
+func (d *deferredValue) String() string {
+	if d == nil || d.setting == nil {
+		return ""
+	}
+	return d.setting.value.String()
+}
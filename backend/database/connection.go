@@ -1,31 +1,124 @@
 package database
 
 import (
+	"database/sql/driver"
 	"errors"
+	"strings"
+	"sync"
 
 	"file-uploader/config"
+	"file-uploader/database/migrations"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
 
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func SetupDB[T any](dsn string, isTest bool) (*gorm.DB, repository.StudentRepository[T], error) {
-	db, err := gorm.Open(
-		postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}),
-		&gorm.Config{},
+// sqlitePragmas enables foreign keys and waits on locks instead of failing
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+var registerSQLiteFunctions = sync.OnceValue(func() error {
+	// Stand-in for pg_trgm's word_similarity used by fuzzy search
+	return sqlitedriver.RegisterDeterministicScalarFunction("word_similarity", 2,
+		func(ctx *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+			a, _ := args[0].(string)
+			b, _ := args[1].(string)
+			return repository.WordSimilarity(a, b), nil
+		},
 	)
+})
+
+// Open connects to the database without touching the schema
+func Open(driver config.DBDriver, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+
+	switch driver {
+	case config.DriverPostgres, "":
+		dialector = postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true})
+	case config.DriverSQLite:
+		if err := registerSQLiteFunctions(); err != nil {
+			return nil, errors.New(config.ErrFailedDBConnection.Error() + ": " + err.Error())
+		}
+		dialector = sqlite.Open(withSQLitePragmas(dsn))
+	default:
+		return nil, errors.New(config.ErrUnsupportedDriver.Error() + ": " + string(driver))
+	}
 
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
-		return nil, nil, errors.New(config.ErrFailedDBConnection.Error() + ": " + err.Error())
+		return nil, errors.New(config.ErrFailedDBConnection.Error() + ": " + err.Error())
+	}
+
+	if driver == config.DriverSQLite {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, errors.New(config.ErrFailedDBConnection.Error() + ": " + err.Error())
+		}
+
+		// SQLite allows a single writer, serialize access instead of failing with locked errors
+		sqlDB.SetMaxOpenConns(1)
+
+		// Opening a file is lazy, make sure it is usable
+		if err := sqlDB.Ping(); err != nil {
+			return nil, errors.New(config.ErrFailedDBConnection.Error() + ": " + err.Error())
+		}
 	}
 
-	err = db.AutoMigrate(&model.Student{}, &model.StudentTest{})
+	return db, nil
+}
+
+func withSQLitePragmas(dsn string) string {
+	if strings.Contains(dsn, "_pragma=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + sqlitePragmas
+	}
+	return dsn + "?" + sqlitePragmas
+}
+
+// ConfigurePool sizes the pool of connections, SQLite keeps its single
+// connection
+func ConfigurePool(db *gorm.DB, settings config.Database) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, nil, errors.New(config.ErrFailedMigration.Error() + " : " + err.Error())
+		return err
+	}
+
+	if db.Dialector.Name() != string(config.DriverSQLite) {
+		sqlDB.SetMaxOpenConns(settings.MaxOpenConns)
+	}
+	sqlDB.SetMaxIdleConns(settings.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	return nil
+}
+
+func SetupDB[T any](driver config.DBDriver, dsn string, isTest bool, opts ...repository.StudentRepoOption) (*gorm.DB, repository.StudentRepository[T], error) {
+	db, err := Open(driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		return nil, nil, err
+	}
+
+	// The test table mirrors students and is never created in production
+	if isTest {
+		err = db.AutoMigrate(&model.StudentTest{})
+		if err != nil {
+			return nil, nil, errors.New(config.ErrFailedMigration.Error() + " : " + err.Error())
+		}
 	}
 
-	StudentRepo := repository.NewStudentRepository[T](db)
+	StudentRepo := repository.NewStudentRepository[T](db, opts...)
 	return db, StudentRepo, nil
 }
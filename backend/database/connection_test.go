@@ -4,7 +4,7 @@ import (
 	"file-uploader/config"
 	"file-uploader/database"
 	"file-uploader/database/model"
-	"os"
+	testutils "file-uploader/internal/test-utils"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -17,22 +17,34 @@ func TestSetupDB(t *testing.T) {
 		if err != nil {
 			assert.Error(t, config.ErrDotEnvNotLoaded)
 		}
-		dsn, exist := os.LookupEnv(config.DBEnvVar)
-
-		if !exist {
+		driver, dsn, err := testutils.TestDSN()
+		if err != nil {
 			assert.Error(t, config.ErrEnvVarNotFound)
 		}
 
-		db, repo, err := database.SetupDB[model.StudentTest](dsn, true)
+		t.Cleanup(func() { testutils.RemoveTestDSN(driver, dsn) })
+
+		db, repo, err := database.SetupDB[model.StudentTest](driver, dsn, true)
 		assert.NoError(t, err)
 		assert.NotNil(t, db)
 		assert.NotNil(t, repo)
+		if db != nil {
+			sqlDB, _ := db.DB()
+			sqlDB.Close()
+		}
 	})
 
 	t.Run("testing invalid database dsn, should return error", func(t *testing.T) {
-		db, repo, err := database.SetupDB[model.StudentTest]("this is invalid dsn", true)
+		db, repo, err := database.SetupDB[model.StudentTest](config.DriverPostgres, "this is invalid dsn", true)
 		assert.ErrorContains(t, err, config.ErrFailedDBConnection.Error())
 		assert.Nil(t, db)
 		assert.Nil(t, repo)
 	})
+
+	t.Run("testing unsupported driver, should return error", func(t *testing.T) {
+		db, repo, err := database.SetupDB[model.StudentTest]("mysql", "", true)
+		assert.ErrorContains(t, err, config.ErrUnsupportedDriver.Error())
+		assert.Nil(t, db)
+		assert.Nil(t, repo)
+	})
 }
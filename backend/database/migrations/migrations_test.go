@@ -0,0 +1,127 @@
+package migrations_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database"
+	"file-uploader/database/migrations"
+	testutils "file-uploader/internal/test-utils"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testSchema = "migrations_test"
+
+func TestLoad(t *testing.T) {
+	postgresMigrations, err := migrations.Load(string(config.DriverPostgres))
+	require.NoError(t, err)
+	require.NotEmpty(t, postgresMigrations)
+
+	sqliteMigrations, err := migrations.Load(string(config.DriverSQLite))
+	require.NoError(t, err)
+	require.Len(t, sqliteMigrations, len(postgresMigrations), "dialects must have the same migrations")
+
+	for i, migration := range postgresMigrations {
+		assert.Equal(t, i+1, migration.Version, "versions must be contiguous")
+		assert.Equal(t, migration.Name, sqliteMigrations[i].Name)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+
+	_, err = migrations.Load("not-a-dialect")
+	assert.ErrorIs(t, err, config.ErrFailedMigration)
+}
+
+func TestUpDown(t *testing.T) {
+	db := openIsolatedDB(t)
+
+	migrator, err := migrations.New(db)
+	require.NoError(t, err)
+	total := len(migrator.Migrations())
+
+	t.Run("apply every migration", func(t *testing.T) {
+		versions, err := migrator.Up()
+		require.NoError(t, err)
+		assert.Len(t, versions, total)
+
+		statuses, err := migrator.Status()
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.True(t, status.Applied, status.Name)
+		}
+	})
+
+	t.Run("up is a no-op when nothing is pending", func(t *testing.T) {
+		versions, err := migrator.Up()
+		require.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+
+	t.Run("revert every migration one by one", func(t *testing.T) {
+		for i := total; i > 0; i-- {
+			versions, err := migrator.Down(1)
+			require.NoError(t, err)
+			assert.Equal(t, []int{i}, versions)
+		}
+
+		assert.False(t, db.Migrator().HasTable("students"))
+		assert.False(t, db.Migrator().HasTable("courses"))
+	})
+
+	t.Run("re-apply after a full revert", func(t *testing.T) {
+		versions, err := migrator.Up()
+		require.NoError(t, err)
+		assert.Len(t, versions, total)
+	})
+}
+
+// openIsolatedDB runs the migrations in a fresh SQLite file, or in a
+// dedicated Postgres schema so they never touch the application tables
+func openIsolatedDB(t *testing.T) *gorm.DB {
+	err := godotenv.Load("../../.env")
+	if err != nil {
+		t.Skipf("Failed to load .env file: %v", err)
+	}
+
+	driver, dsn, err := testutils.TestDSN()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	if driver == config.DriverSQLite {
+		db, err := database.Open(driver, dsn)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			sqlDB, _ := db.DB()
+			sqlDB.Close()
+			testutils.RemoveTestDSN(driver, dsn)
+		})
+		return db
+	}
+
+	db, err := database.Open(driver, dsn)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("DROP SCHEMA IF EXISTS "+testSchema+" CASCADE; CREATE SCHEMA "+testSchema).Error)
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	isolated, err := database.Open(driver, dsn+separator+"search_path="+testSchema)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA IF EXISTS " + testSchema + " CASCADE")
+		for _, conn := range []*gorm.DB{isolated, db} {
+			sqlDB, _ := conn.DB()
+			sqlDB.Close()
+		}
+	})
+
+	return isolated
+}
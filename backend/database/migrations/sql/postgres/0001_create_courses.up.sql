@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS courses (
+    name       text PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+INSERT INTO courses (name, created_at, updated_at)
+VALUES
+    ('Mathematics', now(), now()),
+    ('Physics', now(), now()),
+    ('Chemistry', now(), now()),
+    ('Biology', now(), now()),
+    ('History', now(), now()),
+    ('English Literature', now(), now()),
+    ('Computer Science', now(), now()),
+    ('Art', now(), now()),
+    ('Music', now(), now()),
+    ('Geography', now(), now())
+ON CONFLICT (name) DO NOTHING;
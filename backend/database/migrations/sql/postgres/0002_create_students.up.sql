@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS students (
+    student_id   uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_name text   NOT NULL,
+    subject      text   NOT NULL,
+    grade        bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_student_name ON students (student_name);
+CREATE INDEX IF NOT EXISTS idx_students_subject ON students (subject);
+
+-- Subjects stored before the catalog existed become courses
+INSERT INTO courses (name, created_at, updated_at)
+SELECT DISTINCT subject, now(), now() FROM students
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE students DROP CONSTRAINT IF EXISTS fk_students_course;
+ALTER TABLE students ADD CONSTRAINT fk_students_course
+    FOREIGN KEY (subject) REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT;
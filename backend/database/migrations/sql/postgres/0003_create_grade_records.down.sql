@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS grade_records;
+DROP TABLE IF EXISTS student_profiles;
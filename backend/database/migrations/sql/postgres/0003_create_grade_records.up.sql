@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS student_profiles (
+    id   uuid PRIMARY KEY,
+    name text NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_name ON student_profiles (name);
+
+CREATE TABLE IF NOT EXISTS grade_records (
+    id          uuid PRIMARY KEY,
+    profile_id  uuid        NOT NULL REFERENCES student_profiles (id) ON DELETE CASCADE,
+    subject     text        NOT NULL REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    grade       bigint      NOT NULL,
+    term        text,
+    recorded_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_grade_records_profile_id ON grade_records (profile_id);
+CREATE INDEX IF NOT EXISTS idx_grade_records_subject ON grade_records (subject);
+CREATE INDEX IF NOT EXISTS idx_grade_records_term ON grade_records (term);
+
+-- Map existing flat rows into the normalized tables
+INSERT INTO student_profiles (id, name)
+SELECT gen_random_uuid(), student_name FROM students GROUP BY student_name
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO grade_records (id, profile_id, subject, grade, term, recorded_at)
+SELECT s.student_id, p.id, s.subject, s.grade, '', now()
+FROM students s JOIN student_profiles p ON p.name = s.student_name
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_student_profiles_name_trgm;
+DROP INDEX IF EXISTS idx_students_student_name_trgm;
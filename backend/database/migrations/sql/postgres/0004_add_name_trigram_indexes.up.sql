@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_students_student_name_trgm ON students USING gin (student_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_student_profiles_name_trgm ON student_profiles USING gin (name gin_trgm_ops);
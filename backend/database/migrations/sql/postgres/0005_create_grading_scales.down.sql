@@ -0,0 +1,3 @@
+ALTER TABLE courses DROP COLUMN IF EXISTS grading_scale;
+DROP TABLE IF EXISTS grade_bands;
+DROP TABLE IF EXISTS grading_scales;
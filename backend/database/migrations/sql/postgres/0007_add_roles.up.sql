@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'viewer';
+
+-- Subjects a user is limited to, users without any see every subject
+CREATE TABLE IF NOT EXISTS user_subjects (
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    subject text NOT NULL REFERENCES courses (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (user_id, subject)
+);
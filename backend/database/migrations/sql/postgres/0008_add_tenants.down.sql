@@ -0,0 +1,36 @@
+-- Tenants can't share a single catalog, reverting keeps the default tenant only
+DELETE FROM user_subjects WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM grade_records WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM student_profiles WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM students WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM users WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM courses WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE students DROP CONSTRAINT IF EXISTS fk_students_course;
+ALTER TABLE grade_records DROP CONSTRAINT IF EXISTS grade_records_subject_fkey;
+ALTER TABLE user_subjects DROP CONSTRAINT IF EXISTS user_subjects_subject_fkey;
+
+ALTER TABLE courses DROP CONSTRAINT IF EXISTS courses_pkey;
+ALTER TABLE courses ADD CONSTRAINT courses_pkey PRIMARY KEY (name);
+
+ALTER TABLE students ADD CONSTRAINT fk_students_course
+    FOREIGN KEY (subject) REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+ALTER TABLE grade_records ADD CONSTRAINT grade_records_subject_fkey
+    FOREIGN KEY (subject) REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+ALTER TABLE user_subjects ADD CONSTRAINT user_subjects_subject_fkey
+    FOREIGN KEY (subject) REFERENCES courses (name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_student_profiles_tenant_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_name ON student_profiles (name);
+
+DROP INDEX IF EXISTS idx_students_tenant_id;
+DROP INDEX IF EXISTS idx_grade_records_tenant_id;
+
+ALTER TABLE user_subjects DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE grade_records DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE student_profiles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE students DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE courses DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    created_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_name ON tenants (name);
+
+-- Rows stored before tenants existed belong to the default tenant
+INSERT INTO tenants (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', now())
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE students ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE student_profiles ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE grade_records ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE user_subjects ADD COLUMN IF NOT EXISTS tenant_id uuid NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+
+-- Course names are unique per tenant, subjects reference the course of their own tenant
+ALTER TABLE students DROP CONSTRAINT IF EXISTS fk_students_course;
+ALTER TABLE grade_records DROP CONSTRAINT IF EXISTS grade_records_subject_fkey;
+ALTER TABLE user_subjects DROP CONSTRAINT IF EXISTS user_subjects_subject_fkey;
+
+ALTER TABLE courses DROP CONSTRAINT IF EXISTS courses_pkey;
+ALTER TABLE courses ADD CONSTRAINT courses_pkey PRIMARY KEY (tenant_id, name);
+
+ALTER TABLE students ADD CONSTRAINT fk_students_course
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses (tenant_id, name) ON UPDATE CASCADE ON DELETE RESTRICT;
+ALTER TABLE grade_records ADD CONSTRAINT grade_records_subject_fkey
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses (tenant_id, name) ON UPDATE CASCADE ON DELETE RESTRICT;
+ALTER TABLE user_subjects ADD CONSTRAINT user_subjects_subject_fkey
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses (tenant_id, name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_student_profiles_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_tenant_name ON student_profiles (tenant_id, name);
+
+CREATE INDEX IF NOT EXISTS idx_students_tenant_id ON students (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_grade_records_tenant_id ON grade_records (tenant_id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id         uuid PRIMARY KEY,
+    tenant_id  uuid    NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    status     text    NOT NULL,
+    priority   integer NOT NULL DEFAULT 0,
+    term       text    NOT NULL DEFAULT '',
+    scope      text    NOT NULL DEFAULT '[]',
+    error      text    NOT NULL DEFAULT '',
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
+
+CREATE TABLE IF NOT EXISTS import_files (
+    job_id         uuid    NOT NULL REFERENCES import_jobs (id) ON DELETE CASCADE,
+    position       integer NOT NULL,
+    path           text    NOT NULL,
+    rows_committed bigint  NOT NULL DEFAULT 0,
+    PRIMARY KEY (job_id, position)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE import_jobs DROP COLUMN IF EXISTS all_subjects;
+ALTER TABLE users DROP COLUMN IF EXISTS all_subjects;
@@ -0,0 +1 @@
+ALTER TABLE import_files DROP COLUMN IF EXISTS rows_dispatched;
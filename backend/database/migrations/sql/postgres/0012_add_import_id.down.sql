@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_students_import_id;
+
+ALTER TABLE students DROP COLUMN IF EXISTS import_id;
@@ -0,0 +1,4 @@
+-- The upload that stored each row, rows stored before are never rolled back
+ALTER TABLE students ADD COLUMN IF NOT EXISTS import_id uuid;
+
+CREATE INDEX IF NOT EXISTS idx_students_import_id ON students (import_id);
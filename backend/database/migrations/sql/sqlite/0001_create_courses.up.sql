@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS courses (
+    name       text PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime
+);
+
+INSERT INTO courses (name, created_at, updated_at)
+VALUES
+    ('Mathematics', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Physics', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Chemistry', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Biology', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('History', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('English Literature', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Computer Science', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Art', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Music', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('Geography', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (name) DO NOTHING;
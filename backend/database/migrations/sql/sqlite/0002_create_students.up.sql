@@ -0,0 +1,10 @@
+-- SQLite cannot add constraints to an existing table, the foreign key is declared inline
+CREATE TABLE IF NOT EXISTS students (
+    student_id   text    PRIMARY KEY,
+    student_name text    NOT NULL,
+    subject      text    NOT NULL REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    grade        integer NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_student_name ON students (student_name);
+CREATE INDEX IF NOT EXISTS idx_students_subject ON students (subject);
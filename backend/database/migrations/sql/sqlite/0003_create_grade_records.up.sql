@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS student_profiles (
+    id   text PRIMARY KEY,
+    name text NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_name ON student_profiles (name);
+
+CREATE TABLE IF NOT EXISTS grade_records (
+    id          text     PRIMARY KEY,
+    profile_id  text     NOT NULL REFERENCES student_profiles (id) ON DELETE CASCADE,
+    subject     text     NOT NULL REFERENCES courses (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    grade       integer  NOT NULL,
+    term        text,
+    recorded_at datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_grade_records_profile_id ON grade_records (profile_id);
+CREATE INDEX IF NOT EXISTS idx_grade_records_subject ON grade_records (subject);
+CREATE INDEX IF NOT EXISTS idx_grade_records_term ON grade_records (term);
+
+-- Map existing flat rows into the normalized tables, formatting random blobs as uuids
+INSERT INTO student_profiles (id, name)
+SELECT lower(substr(h, 1, 8) || '-' || substr(h, 9, 4) || '-4' || substr(h, 14, 3) || '-a' || substr(h, 18, 3) || '-' || substr(h, 21, 12)), student_name
+FROM (SELECT hex(randomblob(16)) AS h, student_name FROM students GROUP BY student_name)
+WHERE true
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO grade_records (id, profile_id, subject, grade, term, recorded_at)
+SELECT s.student_id, p.id, s.subject, s.grade, '', CURRENT_TIMESTAMP
+FROM students s JOIN student_profiles p ON p.name = s.student_name
+WHERE true
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,3 @@
+-- SQLite has no trigram indexes, word_similarity is a Go function registered
+-- on the connection and fuzzy searches scan the table
+SELECT 1;
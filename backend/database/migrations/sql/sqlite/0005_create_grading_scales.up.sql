@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS grading_scales (
+    name       text PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS grade_bands (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    scale_name text    NOT NULL REFERENCES grading_scales (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    letter     text    NOT NULL,
+    min_grade  integer NOT NULL,
+    passing    numeric NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grade_bands_scale_letter ON grade_bands (scale_name, letter);
+
+-- SQLite can't drop a column that is part of a foreign key, the repository checks the scale exists
+ALTER TABLE courses ADD COLUMN grading_scale text;
+
+INSERT INTO grading_scales (name, created_at, updated_at)
+VALUES
+    ('letter', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('pass_fail', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO grade_bands (scale_name, letter, min_grade, passing)
+VALUES
+    ('letter', 'A', 90, true),
+    ('letter', 'B', 80, true),
+    ('letter', 'C', 70, true),
+    ('letter', 'D', 60, true),
+    ('letter', 'F', 0, false),
+    ('pass_fail', 'P', 50, true),
+    ('pass_fail', 'F', 0, false)
+ON CONFLICT (scale_name, letter) DO NOTHING;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            text PRIMARY KEY,
+    email         text NOT NULL,
+    password_hash text NOT NULL,
+    created_at    datetime,
+    updated_at    datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id         text PRIMARY KEY,
+    user_id    text        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at datetime NOT NULL,
+    created_at datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    token_hash   text NOT NULL,
+    expires_at   datetime,
+    last_used_at datetime,
+    created_at   datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_tokens_token_hash ON api_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens (user_id);
@@ -0,0 +1,81 @@
+-- Tenants can't share a single catalog, reverting keeps the default tenant only
+DELETE FROM user_subjects WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM grade_records WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM student_profiles WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM students WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM users WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM courses WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+
+CREATE TABLE courses_old (
+    name          text PRIMARY KEY,
+    grading_scale text,
+    created_at    datetime,
+    updated_at    datetime
+);
+
+INSERT INTO courses_old (name, grading_scale, created_at, updated_at)
+SELECT name, grading_scale, created_at, updated_at FROM courses;
+
+CREATE TABLE students_old (
+    student_id   text    PRIMARY KEY,
+    student_name text    NOT NULL,
+    subject      text    NOT NULL REFERENCES courses_old (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    grade        integer NOT NULL
+);
+
+INSERT INTO students_old (student_id, student_name, subject, grade)
+SELECT student_id, student_name, subject, grade FROM students;
+
+CREATE TABLE student_profiles_old (
+    id   text PRIMARY KEY,
+    name text NOT NULL
+);
+
+INSERT INTO student_profiles_old (id, name)
+SELECT id, name FROM student_profiles;
+
+CREATE TABLE grade_records_old (
+    id          text     PRIMARY KEY,
+    profile_id  text     NOT NULL REFERENCES student_profiles_old (id) ON DELETE CASCADE,
+    subject     text     NOT NULL REFERENCES courses_old (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    grade       integer  NOT NULL,
+    term        text,
+    recorded_at datetime NOT NULL
+);
+
+INSERT INTO grade_records_old (id, profile_id, subject, grade, term, recorded_at)
+SELECT id, profile_id, subject, grade, term, recorded_at FROM grade_records;
+
+CREATE TABLE user_subjects_old (
+    user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    subject text NOT NULL REFERENCES courses_old (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (user_id, subject)
+);
+
+INSERT INTO user_subjects_old (user_id, subject)
+SELECT user_id, subject FROM user_subjects;
+
+DROP TABLE user_subjects;
+DROP TABLE grade_records;
+DROP TABLE student_profiles;
+DROP TABLE students;
+DROP TABLE courses;
+
+ALTER TABLE courses_old RENAME TO courses;
+ALTER TABLE students_old RENAME TO students;
+ALTER TABLE student_profiles_old RENAME TO student_profiles;
+ALTER TABLE grade_records_old RENAME TO grade_records;
+ALTER TABLE user_subjects_old RENAME TO user_subjects;
+
+CREATE INDEX IF NOT EXISTS idx_students_student_name ON students (student_name);
+CREATE INDEX IF NOT EXISTS idx_students_subject ON students (subject);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_name ON student_profiles (name);
+
+CREATE INDEX IF NOT EXISTS idx_grade_records_profile_id ON grade_records (profile_id);
+CREATE INDEX IF NOT EXISTS idx_grade_records_subject ON grade_records (subject);
+CREATE INDEX IF NOT EXISTS idx_grade_records_term ON grade_records (term);
+
+ALTER TABLE users DROP COLUMN tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,99 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id         text PRIMARY KEY,
+    name       text NOT NULL,
+    created_at datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_name ON tenants (name);
+
+-- Rows stored before tenants existed belong to the default tenant
+INSERT INTO tenants (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', CURRENT_TIMESTAMP)
+ON CONFLICT (id) DO NOTHING;
+
+-- SQLite can't add a column with both a foreign key and a default, the repository checks the tenant
+ALTER TABLE users ADD COLUMN tenant_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+-- SQLite can't change a primary key, the tables keyed on course names are
+-- rebuilt. Children are dropped before their parents, and renaming the new
+-- parents rewrites the foreign keys of the new children
+CREATE TABLE courses_new (
+    tenant_id     text NOT NULL REFERENCES tenants (id),
+    name          text NOT NULL,
+    grading_scale text,
+    created_at    datetime,
+    updated_at    datetime,
+    PRIMARY KEY (tenant_id, name)
+);
+
+INSERT INTO courses_new (tenant_id, name, grading_scale, created_at, updated_at)
+SELECT '00000000-0000-0000-0000-000000000001', name, grading_scale, created_at, updated_at FROM courses;
+
+CREATE TABLE students_new (
+    student_id   text    PRIMARY KEY,
+    tenant_id    text    NOT NULL REFERENCES tenants (id),
+    student_name text    NOT NULL,
+    subject      text    NOT NULL,
+    grade        integer NOT NULL,
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses_new (tenant_id, name) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+INSERT INTO students_new (student_id, tenant_id, student_name, subject, grade)
+SELECT student_id, '00000000-0000-0000-0000-000000000001', student_name, subject, grade FROM students;
+
+CREATE TABLE student_profiles_new (
+    id        text PRIMARY KEY,
+    tenant_id text NOT NULL REFERENCES tenants (id),
+    name      text NOT NULL
+);
+
+INSERT INTO student_profiles_new (id, tenant_id, name)
+SELECT id, '00000000-0000-0000-0000-000000000001', name FROM student_profiles;
+
+CREATE TABLE grade_records_new (
+    id          text     PRIMARY KEY,
+    tenant_id   text     NOT NULL REFERENCES tenants (id),
+    profile_id  text     NOT NULL REFERENCES student_profiles_new (id) ON DELETE CASCADE,
+    subject     text     NOT NULL,
+    grade       integer  NOT NULL,
+    term        text,
+    recorded_at datetime NOT NULL,
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses_new (tenant_id, name) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+INSERT INTO grade_records_new (id, tenant_id, profile_id, subject, grade, term, recorded_at)
+SELECT id, '00000000-0000-0000-0000-000000000001', profile_id, subject, grade, term, recorded_at FROM grade_records;
+
+CREATE TABLE user_subjects_new (
+    user_id   text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id text NOT NULL REFERENCES tenants (id),
+    subject   text NOT NULL,
+    PRIMARY KEY (user_id, subject),
+    FOREIGN KEY (tenant_id, subject) REFERENCES courses_new (tenant_id, name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+INSERT INTO user_subjects_new (user_id, tenant_id, subject)
+SELECT user_id, '00000000-0000-0000-0000-000000000001', subject FROM user_subjects;
+
+DROP TABLE user_subjects;
+DROP TABLE grade_records;
+DROP TABLE student_profiles;
+DROP TABLE students;
+DROP TABLE courses;
+
+ALTER TABLE courses_new RENAME TO courses;
+ALTER TABLE students_new RENAME TO students;
+ALTER TABLE student_profiles_new RENAME TO student_profiles;
+ALTER TABLE grade_records_new RENAME TO grade_records;
+ALTER TABLE user_subjects_new RENAME TO user_subjects;
+
+CREATE INDEX IF NOT EXISTS idx_students_student_name ON students (student_name);
+CREATE INDEX IF NOT EXISTS idx_students_subject ON students (subject);
+CREATE INDEX IF NOT EXISTS idx_students_tenant_id ON students (tenant_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_profiles_tenant_name ON student_profiles (tenant_id, name);
+
+CREATE INDEX IF NOT EXISTS idx_grade_records_profile_id ON grade_records (profile_id);
+CREATE INDEX IF NOT EXISTS idx_grade_records_subject ON grade_records (subject);
+CREATE INDEX IF NOT EXISTS idx_grade_records_term ON grade_records (term);
+CREATE INDEX IF NOT EXISTS idx_grade_records_tenant_id ON grade_records (tenant_id);
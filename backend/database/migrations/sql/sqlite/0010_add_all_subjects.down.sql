@@ -0,0 +1,2 @@
+ALTER TABLE import_jobs DROP COLUMN all_subjects;
+ALTER TABLE users DROP COLUMN all_subjects;
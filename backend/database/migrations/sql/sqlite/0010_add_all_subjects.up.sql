@@ -0,0 +1,13 @@
+-- Seeing every subject is granted explicitly, a user without assigned
+-- subjects sees none
+ALTER TABLE users ADD COLUMN all_subjects boolean NOT NULL DEFAULT false;
+
+-- Users without subjects saw every subject so far, they keep doing so
+UPDATE users SET all_subjects = true
+WHERE NOT EXISTS (SELECT 1 FROM user_subjects WHERE user_subjects.user_id = users.id);
+
+-- Jobs record whether their uploader was unscoped, an empty scope no longer
+-- means every subject
+ALTER TABLE import_jobs ADD COLUMN all_subjects boolean NOT NULL DEFAULT false;
+
+UPDATE import_jobs SET all_subjects = true WHERE scope = '[]';
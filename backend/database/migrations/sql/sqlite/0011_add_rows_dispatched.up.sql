@@ -0,0 +1,3 @@
+-- Records handed to the inserts, the ones past rows_committed may have been
+-- stored before a crash and are replayed skipping duplicates
+ALTER TABLE import_files ADD COLUMN rows_dispatched bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX idx_students_import_id;
+
+ALTER TABLE students DROP COLUMN import_id;
@@ -0,0 +1,39 @@
+package model
+
+import "github.com/google/uuid"
+
+// StudentRank places a student record within its subject
+type StudentRank struct {
+	StudentID   uuid.UUID `gorm:"column:student_id" json:"student_id"`
+	StudentName string    `gorm:"column:student_name" json:"student_name"`
+	Subject     string    `gorm:"column:subject" json:"subject"`
+	Grade       uint      `gorm:"column:grade" json:"grade"`
+
+	// Rank is 1 for the best grade of the subject, ties share a rank
+	Rank int64 `gorm:"column:subject_rank" json:"rank"`
+
+	// Percentile is the percentage of the subject's other records graded lower, from 0 to 100
+	Percentile float64 `gorm:"column:percentile" json:"percentile"`
+}
+
+// SubjectStats summarizes the grades of a subject against the other subjects
+type SubjectStats struct {
+	Subject string  `gorm:"column:subject" json:"subject"`
+	Count   int64   `gorm:"column:students" json:"count"`
+	Average float64 `gorm:"column:average" json:"average"`
+	Min     uint    `gorm:"column:min_grade" json:"min"`
+	Max     uint    `gorm:"column:max_grade" json:"max"`
+
+	// Rank orders subjects by average, 1 being the highest
+	Rank int64 `gorm:"column:subject_rank" json:"rank"`
+
+	// Difference is the average minus the mean of all subject averages
+	Difference float64 `gorm:"column:difference" json:"difference"`
+}
+
+// LetterCount is the number of records of a subject graded with a letter
+type LetterCount struct {
+	Subject string `gorm:"column:subject" json:"subject"`
+	Letter  string `gorm:"column:letter" json:"letter"`
+	Count   int64  `gorm:"column:students" json:"count"`
+}
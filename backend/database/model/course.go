@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Course names are unique within a tenant
+type Course struct {
+	TenantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Name     string    `gorm:"primaryKey" json:"name"`
+
+	// GradingScale names the scale of the course, nil uses the default scale
+	GradingScale *string   `json:"grading_scale"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StudentProfile is a person, identified by name since the flat CSV has no person id
+type StudentProfile struct {
+	ID       uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID uuid.UUID     `gorm:"type:uuid;uniqueIndex:idx_student_profiles_tenant_name;not null" json:"-"`
+	Name     string        `gorm:"uniqueIndex:idx_student_profiles_tenant_name;not null" json:"name"`
+	Grades   []GradeRecord `gorm:"foreignKey:ProfileID;constraint:OnDelete:CASCADE" json:"grades"`
+}
+
+// GradeRecord is a single grade of a student in a course, its id is the
+// Student_id of the flat row it was imported from
+type GradeRecord struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID   uuid.UUID `gorm:"type:uuid;index;not null" json:"-"`
+	ProfileID  uuid.UUID `gorm:"type:uuid;index;not null" json:"-"`
+	Subject    string    `gorm:"index;not null" json:"subject"`
+	Grade      uint      `gorm:"not null" json:"grade"`
+	Term       string    `gorm:"index" json:"term"`
+	RecordedAt time.Time `gorm:"not null" json:"recorded_at"`
+
+	Course *Course `gorm:"foreignKey:TenantID,Subject;references:TenantID,Name;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+}
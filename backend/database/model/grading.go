@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// GradingScale interprets raw grades as letters, courses without a scale use
+// the default one
+type GradingScale struct {
+	Name      string      `gorm:"primaryKey" json:"name"`
+	Bands     []GradeBand `gorm:"foreignKey:ScaleName;references:Name;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"bands"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// GradeBand gives a letter to grades at or above MinGrade, up to the next band
+type GradeBand struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	ScaleName string `gorm:"not null" json:"-"`
+	Letter    string `gorm:"not null" json:"letter"`
+	MinGrade  uint   `gorm:"not null" json:"min_grade"`
+	Passing   bool   `gorm:"not null" json:"passing"`
+}
+
+// Band returns the band of a grade, bands must be ordered from the highest minimum
+func (s *GradingScale) Band(grade uint) (GradeBand, bool) {
+	for _, band := range s.Bands {
+		if grade >= band.MinGrade {
+			return band, true
+		}
+	}
+	return GradeBand{}, false
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"file-uploader/config"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJob is an upload waiting for or going through the job queue, it
+// outlives restarts so interrupted uploads can resume
+type ImportJob struct {
+	ID       uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID uuid.UUID           `gorm:"type:uuid;not null" json:"-"`
+	Status   config.ImportStatus `gorm:"not null;index" json:"status"`
+	Priority int                 `gorm:"not null;default:0" json:"priority"`
+	Term     string              `gorm:"not null;default:''" json:"term"`
+
+	// Scope holds the subjects the uploader was limited to, unless
+	// AllSubjects
+	Scope       []string `gorm:"serializer:json;not null" json:"-"`
+	AllSubjects bool     `gorm:"not null;default:false" json:"-"`
+
+	Error     string        `gorm:"not null;default:''" json:"error,omitempty"`
+	Files     []*ImportFile `gorm:"foreignKey:JobID" json:"files"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ImportFile is a spooled file of an import job, RowsCommitted counts the
+// records already stored so a resumed import skips them. Records up to
+// RowsDispatched may be stored too, a resume replays them skipping duplicates
+type ImportFile struct {
+	JobID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Position       int       `gorm:"primaryKey;autoIncrement:false" json:"position"`
+	Path           string    `gorm:"not null" json:"-"`
+	RowsCommitted  int64     `gorm:"not null;default:0" json:"rows_committed"`
+	RowsDispatched int64     `gorm:"not null;default:0" json:"-"`
+}
@@ -2,18 +2,44 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Student struct {
-	Student_id   uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Student_id   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Tenant_id    uuid.UUID `gorm:"type:uuid;index;not null" json:"-"`
 	Student_name string    `gorm:"index;not null"`
 	Subject      string    `gorm:"index;not null"`
 	Grade        uint      `gorm:"not null"`
+
+	// Import_id is the upload that stored the row, nil for rows stored
+	// otherwise
+	Import_id *uuid.UUID `gorm:"type:uuid;index" json:"-"`
+
+	// Subject references the course catalog of the tenant
+	Course *Course `gorm:"foreignKey:Tenant_id,Subject;references:TenantID,Name;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
 }
 
 type StudentTest struct {
-	Student_id   uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Student_name string    `gorm:"index;not null"`
-	Subject      string    `gorm:"index;not null"`
-	Grade        uint      `gorm:"not null"`
+	Student_id   uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Tenant_id    uuid.UUID  `gorm:"type:uuid;index;not null" json:"-"`
+	Student_name string     `gorm:"index;not null"`
+	Subject      string     `gorm:"index;not null"`
+	Grade        uint       `gorm:"not null"`
+	Import_id    *uuid.UUID `gorm:"type:uuid;index" json:"-"`
+}
+
+// BeforeCreate generates ids in Go, databases without gen_random_uuid() rely on it
+func (s *Student) BeforeCreate(tx *gorm.DB) error {
+	if s.Student_id == uuid.Nil {
+		s.Student_id = uuid.New()
+	}
+	return nil
+}
+
+func (s *StudentTest) BeforeCreate(tx *gorm.DB) error {
+	if s.Student_id == uuid.Nil {
+		s.Student_id = uuid.New()
+	}
+	return nil
 }
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"file-uploader/config"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type User struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID     uuid.UUID   `gorm:"type:uuid;not null" json:"tenant_id"`
+	Email        string      `gorm:"uniqueIndex;not null" json:"email"`
+	PasswordHash string      `gorm:"not null" json:"-"`
+	Role         config.Role `gorm:"not null;default:viewer" json:"role"`
+
+	// AllSubjects lifts the subject scoping of viewers and uploaders, others
+	// only see their assigned subjects
+	AllSubjects bool      `gorm:"not null;default:false" json:"all_subjects"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserSubject limits a user to the students of a subject
+type UserSubject struct {
+	UserID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null"`
+	Subject  string    `gorm:"primaryKey"`
+}
+
+// Session is a browser login, its id is the hash of the cookie value
+type Session struct {
+	ID        string    `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;index;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// APIToken is a long-lived credential for scripts, only its hash is stored
+type APIToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index;not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"not null" json:"prefix"`
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
+
+func (t *APIToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
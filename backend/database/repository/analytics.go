@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepo computes rankings and statistics with window functions, the
+// filters of the query options are applied before ranking
+type AnalyticsRepo[T any] struct {
+	db     *gorm.DB
+	tenant uuid.UUID
+}
+
+func NewAnalyticsRepository[T any](db *gorm.DB) AnalyticsRepository {
+	return &AnalyticsRepo[T]{db: db, tenant: config.DefaultTenantID}
+}
+
+func (r *AnalyticsRepo[T]) ForTenant(tenantID uuid.UUID) AnalyticsRepository {
+	return &AnalyticsRepo[T]{db: r.db, tenant: tenantOrDefault(tenantID)}
+}
+
+// filtered selects the records of the tenant matching the query
+func (r *AnalyticsRepo[T]) filtered(query Query) *gorm.DB {
+	return applyFilters(r.db.Model(new(T)).Where(fmt.Sprintf("%s = ?", config.TenantId), r.tenant), query)
+}
+
+// ranked selects the filtered records with their rank and percentile within
+// their subject, order is the direction ranks are given in
+func (r *AnalyticsRepo[T]) ranked(opts []QueryOption, order config.SortOrder) *gorm.DB {
+	query := BuildQuery(opts, nil)
+
+	partition := fmt.Sprintf("PARTITION BY %s", config.Subject)
+	return r.filtered(query).Select(fmt.Sprintf(
+		"%s AS student_id, %s AS student_name, %s AS subject, %s AS grade, "+
+			"RANK() OVER (%s ORDER BY %s %s) AS subject_rank, "+
+			"PERCENT_RANK() OVER (%s ORDER BY %s) * 100 AS percentile",
+		config.Id, config.Name, config.Subject, config.Grade,
+		partition, config.Grade, order,
+		partition, config.Grade,
+	))
+}
+
+// Percentiles ranks every record within its subject, keeping the ones at or
+// above minPercentile, e.g. 90 for the top 10%
+func (r *AnalyticsRepo[T]) Percentiles(opts []QueryOption, minPercentile float64) ([]*model.StudentRank, error) {
+	ranks := []*model.StudentRank{}
+	err := r.db.Table("(?) AS ranked", r.ranked(opts, config.SortDesc)).
+		Where("percentile >= ?", minPercentile).
+		Order("subject, subject_rank, student_name, student_id").
+		Scan(&ranks).Error
+	return ranks, err
+}
+
+// Top returns the n best (desc) or worst (asc) records of each subject, ties
+// with the n-th record are kept
+func (r *AnalyticsRepo[T]) Top(n int, order config.SortOrder, opts []QueryOption) ([]*model.StudentRank, error) {
+	ranks := []*model.StudentRank{}
+	if n <= 0 {
+		return ranks, nil
+	}
+
+	err := r.db.Table("(?) AS ranked", r.ranked(opts, order)).
+		Where("subject_rank <= ?", n).
+		Order("subject, subject_rank, student_name, student_id").
+		Scan(&ranks).Error
+	return ranks, err
+}
+
+// CompareSubjects aggregates grades per subject and compares each subject to
+// the mean of all subject averages
+func (r *AnalyticsRepo[T]) CompareSubjects(opts []QueryOption) ([]*model.SubjectStats, error) {
+	query := BuildQuery(opts, nil)
+
+	average := fmt.Sprintf("AVG(CAST(%s AS FLOAT))", config.Grade)
+
+	stats := []*model.SubjectStats{}
+	err := r.filtered(query).
+		Select(fmt.Sprintf(
+			"%s AS subject, COUNT(*) AS students, %s AS average, MIN(%s) AS min_grade, MAX(%s) AS max_grade, "+
+				"RANK() OVER (ORDER BY %s DESC) AS subject_rank, "+
+				"%s - AVG(%s) OVER () AS difference",
+			config.Subject, average, config.Grade, config.Grade,
+			average,
+			average, average,
+		)).
+		Group(string(config.Subject)).
+		Order("subject_rank, subject").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// LetterDistribution counts the records of each subject per letter grade
+func (r *AnalyticsRepo[T]) LetterDistribution(opts []QueryOption, scales map[string]*model.GradingScale) ([]*model.LetterCount, error) {
+	counts := []*model.LetterCount{}
+	if len(scales) == 0 {
+		return counts, nil
+	}
+
+	query := BuildQuery(opts, nil)
+	letter, args := letterCase(scales)
+
+	graded := r.filtered(query).
+		Select(fmt.Sprintf("%s AS subject, %s AS letter", config.Subject, letter), args...)
+
+	err := r.db.Table("(?) AS graded", graded).
+		Select("subject, letter, COUNT(*) AS students").
+		Where("letter IS NOT NULL").
+		Group("subject, letter").
+		Order("subject, letter").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAnalyticsData(t *testing.T) repository.AnalyticsRepository {
+	// Clear any previous test data
+	testDB.Where("1=1").Delete(&model.StudentTest{})
+
+	testData := []*model.StudentTest{
+		{Student_name: "Ali", Subject: string(config.Physics), Grade: 95},
+		{Student_name: "Omar", Subject: string(config.Physics), Grade: 80},
+		{Student_name: "Saad", Subject: string(config.Physics), Grade: 80},
+		{Student_name: "Alaa", Subject: string(config.Physics), Grade: 60},
+		{Student_name: "Mona", Subject: string(config.Physics), Grade: 40},
+		{Student_name: "Ali", Subject: string(config.Art), Grade: 70},
+		{Student_name: "Omar", Subject: string(config.Art), Grade: 50},
+	}
+	require.NoError(t, studentRepo.CreateMany(testData))
+
+	return repository.NewAnalyticsRepository[model.StudentTest](testDB)
+}
+
+func names(ranks []*model.StudentRank) []string {
+	var result []string
+	for _, rank := range ranks {
+		result = append(result, rank.Subject+"/"+rank.StudentName)
+	}
+	return result
+}
+
+func TestPercentiles(t *testing.T) {
+	analytics := setupAnalyticsData(t)
+
+	t.Run("every record is ranked within its subject", func(t *testing.T) {
+		ranks, err := analytics.Percentiles(nil, 0)
+		require.NoError(t, err)
+		require.Len(t, ranks, 7)
+
+		// Art comes first, then Physics from best to worst
+		assert.Equal(t, "Ali", ranks[0].StudentName)
+		assert.Equal(t, int64(1), ranks[0].Rank)
+		assert.InDelta(t, 100, ranks[0].Percentile, 0.001)
+		assert.InDelta(t, 0, ranks[1].Percentile, 0.001)
+
+		physics := ranks[2:]
+		assert.Equal(t, []int64{1, 2, 2, 4, 5}, []int64{physics[0].Rank, physics[1].Rank, physics[2].Rank, physics[3].Rank, physics[4].Rank})
+		assert.InDelta(t, 50, physics[1].Percentile, 0.001)
+		assert.InDelta(t, 50, physics[2].Percentile, 0.001)
+	})
+
+	t.Run("top percentile of a subject", func(t *testing.T) {
+		ranks, err := analytics.Percentiles([]repository.QueryOption{repository.WithSubject(config.Physics)}, 50)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Physics/Ali", "Physics/Omar", "Physics/Saad"}, names(ranks))
+	})
+
+	t.Run("filters apply before ranking", func(t *testing.T) {
+		condition := &repository.Condition{Column: config.Grade, Op: repository.OpLt, Values: []any{uint(90)}}
+		ranks, err := analytics.Percentiles([]repository.QueryOption{
+			repository.WithSubject(config.Physics),
+			repository.WithCondition(condition),
+		}, 0)
+		require.NoError(t, err)
+		require.Len(t, ranks, 4)
+		assert.Equal(t, "Alaa", ranks[2].StudentName)
+		assert.Equal(t, int64(3), ranks[2].Rank)
+		assert.InDelta(t, 100.0/3, ranks[2].Percentile, 0.001)
+	})
+}
+
+func TestTop(t *testing.T) {
+	analytics := setupAnalyticsData(t)
+
+	cases := []struct {
+		name     string
+		n        int
+		order    config.SortOrder
+		expected []string
+	}{
+		{name: "top 1 per subject", n: 1, order: config.SortDesc, expected: []string{"Art/Ali", "Physics/Ali"}},
+		{name: "ties with the last rank are kept", n: 2, order: config.SortDesc, expected: []string{"Art/Ali", "Art/Omar", "Physics/Ali", "Physics/Omar", "Physics/Saad"}},
+		{name: "bottom 1 per subject", n: 1, order: config.SortAsc, expected: []string{"Art/Omar", "Physics/Mona"}},
+		{name: "non positive n is empty", n: 0, order: config.SortDesc, expected: nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ranks, err := analytics.Top(tt.n, tt.order, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names(ranks))
+		})
+	}
+}
+
+func TestCompareSubjects(t *testing.T) {
+	analytics := setupAnalyticsData(t)
+
+	stats, err := analytics.CompareSubjects(nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	physics, art := stats[0], stats[1]
+	assert.Equal(t, string(config.Physics), physics.Subject)
+	assert.Equal(t, int64(5), physics.Count)
+	assert.InDelta(t, 71, physics.Average, 0.001)
+	assert.Equal(t, uint(40), physics.Min)
+	assert.Equal(t, uint(95), physics.Max)
+	assert.Equal(t, int64(1), physics.Rank)
+	assert.InDelta(t, 5.5, physics.Difference, 0.001)
+
+	assert.Equal(t, string(config.Art), art.Subject)
+	assert.Equal(t, int64(2), art.Rank)
+	assert.InDelta(t, -5.5, art.Difference, 0.001)
+
+	// Filters narrow the compared records
+	stats, err = analytics.CompareSubjects([]repository.QueryOption{repository.WithNameFilter("Ali")})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, string(config.Physics), stats[0].Subject)
+	assert.InDelta(t, 95, stats[0].Average, 0.001)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bounds of the adaptive batch size, 5000 rows of a student stay below the
+// bind parameter limits of postgres and SQLite
+const (
+	minInsertBatch = 50
+	maxInsertBatch = 5000
+)
+
+// defaultInsertWorkers applies when neither the workers nor the pool bound
+// the concurrency
+const defaultInsertWorkers = 10
+
+// InsertStats describe the inserts of CreateMany since the start
+type InsertStats struct {
+	// BatchSize is the rows of the next insert statement
+	BatchSize int `json:"batch_size"`
+
+	// Workers inserted the last call concurrently
+	Workers int `json:"workers"`
+
+	// Slots bound the statements running at once across every call of the
+	// repository and its tenants
+	Slots int `json:"slots"`
+
+	Batches int64 `json:"batches"`
+	Rows    int64 `json:"rows"`
+
+	// LastLatency and AverageLatency are per statement, in milliseconds
+	LastLatency    float64 `json:"last_latency_ms"`
+	AverageLatency float64 `json:"average_latency_ms"`
+}
+
+// InsertStatsReporter is implemented by the repositories tracking their
+// inserts
+type InsertStatsReporter interface {
+	InsertStats() InsertStats
+}
+
+// batchSizer moves the batch size towards the rows a statement inserts in
+// the target latency, it is shared by the tenants of a repository so what
+// it learns is kept
+type batchSizer struct {
+	mu     sync.Mutex
+	target time.Duration
+	stats  InsertStats
+	total  time.Duration
+}
+
+func newBatchSizer(size int, target time.Duration) *batchSizer {
+	return &batchSizer{target: target, stats: InsertStats{BatchSize: size}}
+}
+
+func (b *batchSizer) size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats.BatchSize
+}
+
+func (b *batchSizer) started(workers int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Workers = workers
+}
+
+// observe records an insert of rows that took took, and resizes the batches
+// half way to the size matching the target so a slow statement doesn't
+// swing the size
+func (b *batchSizer) observe(rows int, took time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Batches++
+	b.stats.Rows += int64(rows)
+	b.total += took
+	b.stats.LastLatency = milliseconds(took)
+	b.stats.AverageLatency = milliseconds(b.total) / float64(b.stats.Batches)
+
+	if b.target <= 0 || rows == 0 || took <= 0 {
+		return
+	}
+	ideal := int(float64(rows) * float64(b.target) / float64(took))
+	b.stats.BatchSize = min(max((b.stats.BatchSize+ideal)/2, minInsertBatch), maxInsertBatch)
+}
+
+func (b *batchSizer) snapshot() InsertStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// insertSlots bound the insert statements of a repository by the
+// connections of the pool, whatever the calls running at once. Unless
+// configured, the inserts take half the pool so reads still get a
+// connection. The pool is read on first use, repositories are built before
+// it is configured
+type insertSlots struct {
+	db         *gorm.DB
+	configured int
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+func newInsertSlots(db *gorm.DB, configured int) *insertSlots {
+	return &insertSlots{db: db, configured: configured}
+}
+
+func (s *insertSlots) get() chan struct{} {
+	s.once.Do(func() {
+		slots := s.configured
+		if sqlDB, err := s.db.DB(); err == nil {
+			if open := sqlDB.Stats().MaxOpenConnections; open > 0 {
+				if slots <= 0 {
+					slots = max(open/2, 1)
+				}
+				slots = min(slots, open)
+			}
+		}
+		if slots <= 0 {
+			slots = defaultInsertWorkers
+		}
+		s.slots = make(chan struct{}, slots)
+	})
+	return s.slots
+}
+
+func (s *insertSlots) size() int {
+	return cap(s.get())
+}
+
+func (s *insertSlots) acquire() {
+	s.get() <- struct{}{}
+}
+
+func (s *insertSlots) release() {
+	<-s.get()
+}
+
+// insertWorkers bounds the workers of a call by the batches there are and
+// the slots of the repository
+func insertWorkers(slots *insertSlots, rows, batchSize int) int {
+	batches := (rows + batchSize - 1) / batchSize
+	return max(min(slots.size(), batches), 1)
+}
@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func insertStats(t *testing.T, repo repository.StudentRepository[model.StudentTest]) repository.InsertStats {
+	reporter, ok := repo.(repository.InsertStatsReporter)
+	require.True(t, ok)
+	return reporter.InsertStats()
+}
+
+func TestInsertBatching(t *testing.T) {
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	maxOpen := sqlDB.Stats().MaxOpenConnections
+
+	t.Run("small inserts use a single worker", func(t *testing.T) {
+		repo := repository.NewStudentRepository[model.StudentTest](testDB, repository.WithInsertBatches(100, 8))
+		require.NoError(t, repo.ForTenant(uuid.New()).CreateMany(seededStudents(t, 3, 3)))
+
+		stats := insertStats(t, repo)
+		assert.Equal(t, 1, stats.Workers)
+		assert.EqualValues(t, 1, stats.Batches)
+		assert.EqualValues(t, 3, stats.Rows)
+	})
+
+	t.Run("workers never exceed the pool", func(t *testing.T) {
+		repo := repository.NewStudentRepository[model.StudentTest](testDB, repository.WithInsertBatches(100, 8))
+		require.NoError(t, repo.ForTenant(uuid.New()).CreateMany(seededStudents(t, 4, 1000)))
+
+		stats := insertStats(t, repo)
+		if maxOpen > 0 {
+			assert.LessOrEqual(t, stats.Workers, maxOpen)
+		} else {
+			assert.Equal(t, 8, stats.Workers)
+		}
+		assert.EqualValues(t, 1000, stats.Rows)
+	})
+
+	t.Run("concurrent calls share the slots", func(t *testing.T) {
+		var running, peak atomic.Int32
+		observe := func(int, time.Duration) {
+			n := running.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}
+		repo := repository.NewStudentRepository[model.StudentTest](testDB,
+			repository.WithInsertBatches(50, 2), repository.WithInsertObserver(observe))
+
+		// Tenants and replays of the repository insert at once
+		copies := []repository.StudentRepository[model.StudentTest]{
+			repo.ForTenant(uuid.New()),
+			repo.ForTenant(uuid.New()),
+			repo.ForTenant(uuid.New()).SkipDuplicates(),
+			repo.ForTenant(uuid.New()).SkipDuplicates(),
+		}
+		var wg sync.WaitGroup
+		for i, tenant := range copies {
+			students := seededStudents(t, int64(20+i), 300)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, tenant.CreateMany(students))
+			}()
+		}
+		wg.Wait()
+
+		stats := insertStats(t, repo)
+		assert.LessOrEqual(t, stats.Slots, 2)
+		if maxOpen > 0 {
+			assert.LessOrEqual(t, stats.Slots, maxOpen)
+		}
+		assert.LessOrEqual(t, int(peak.Load()), stats.Slots)
+		assert.EqualValues(t, 1200, stats.Rows)
+	})
+
+	cases := []struct {
+		name    string
+		latency time.Duration
+		check   func(t *testing.T, size int)
+	}{
+		{name: "fast inserts grow the batches", latency: time.Hour, check: func(t *testing.T, size int) { assert.Greater(t, size, 500) }},
+		{name: "slow inserts shrink the batches", latency: time.Nanosecond, check: func(t *testing.T, size int) { assert.Less(t, size, 500) }},
+		{name: "no target keeps the batches", latency: 0, check: func(t *testing.T, size int) { assert.Equal(t, 500, size) }},
+	}
+
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewStudentRepository[model.StudentTest](testDB,
+				repository.WithInsertBatches(500, 1), repository.WithInsertLatency(tt.latency))
+			tenant := repo.ForTenant(uuid.New())
+			require.NoError(t, tenant.CreateMany(seededStudents(t, int64(10+i), 3000)))
+
+			tt.check(t, insertStats(t, repo).BatchSize)
+
+			// Every row is inserted whatever the sizes
+			_, count, err := tenant.Query(nil, nil)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3000, count)
+		})
+	}
+}
+
+func TestInsertSlots(t *testing.T) {
+	cases := []struct {
+		name     string
+		workers  int
+		maxOpen  int
+		expected int
+	}{
+		{name: "half the pool by default", workers: 0, maxOpen: 8, expected: 4},
+		{name: "a pool of one still inserts", workers: 0, maxOpen: 1, expected: 1},
+		{name: "configured workers within the pool", workers: 3, maxOpen: 8, expected: 3},
+		{name: "configured workers never exceed the pool", workers: 12, maxOpen: 8, expected: 8},
+		{name: "configured workers on an unlimited pool", workers: 12, maxOpen: 0, expected: 12},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// The pool of a postgres database is sized without connecting
+			db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=slots"}), &gorm.Config{DisableAutomaticPing: true})
+			require.NoError(t, err)
+			sqlDB, err := db.DB()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			// The pool is configured after the repository is built, as the
+			// server does
+			repo := repository.NewStudentRepository[model.StudentTest](db, repository.WithInsertBatches(100, tt.workers))
+			sqlDB.SetMaxOpenConns(tt.maxOpen)
+
+			assert.Equal(t, tt.expected, insertStats(t, repo.ForTenant(uuid.New())).Slots)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"file-uploader/config"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type Operator string
+type Logic string
+
+const (
+	OpEq       Operator = "="
+	OpNe       Operator = "!="
+	OpGt       Operator = ">"
+	OpGte      Operator = ">="
+	OpLt       Operator = "<"
+	OpLte      Operator = "<="
+	OpIn       Operator = "in"
+	OpBetween  Operator = "between"
+	OpContains Operator = "contains"
+	OpPrefix   Operator = "prefix"
+
+	LogicAnd Logic = "and"
+	LogicOr  Logic = "or"
+)
+
+// Condition is either a comparison on a column or a group of conditions
+// joined by AND/OR. Columns must come from a whitelist, values are always
+// passed as query parameters
+type Condition struct {
+	Column config.StudentCol
+	Op     Operator
+	Values []any
+
+	Logic    Logic
+	Children []Condition
+}
+
+func (c Condition) IsGroup() bool {
+	return c.Logic != ""
+}
+
+// WithCondition adds a condition, multiple conditions are joined by AND
+func WithCondition(condition *Condition) QueryOption {
+	return func(q *Query) {
+		if condition != nil {
+			q.Conditions = append(q.Conditions, *condition)
+		}
+	}
+}
+
+// conditionSQL translates a condition to a parameterized SQL fragment
+func conditionSQL(db *gorm.DB, c Condition) (string, []any) {
+	if c.IsGroup() {
+		parts := make([]string, 0, len(c.Children))
+		var args []any
+		for _, child := range c.Children {
+			sql, childArgs := conditionSQL(db, child)
+			parts = append(parts, sql)
+			args = append(args, childArgs...)
+		}
+		separator := " AND "
+		if c.Logic == LogicOr {
+			separator = " OR "
+		}
+		return "(" + strings.Join(parts, separator) + ")", args
+	}
+
+	column := string(c.Column)
+	switch c.Op {
+	case OpIn:
+		return column + " IN ?", []any{c.Values}
+	case OpBetween:
+		return column + " BETWEEN ? AND ?", c.Values
+	case OpContains:
+		return likeEscaped(db, column), []any{"%" + escapeLike(c.Values[0].(string)) + "%"}
+	case OpPrefix:
+		return likeEscaped(db, column), []any{escapeLike(c.Values[0].(string)) + "%"}
+	default:
+		return column + " " + string(c.Op) + " ?", c.Values
+	}
+}
+
+// likeEscaped is a case insensitive LIKE honoring backslash escapes on every dialect
+func likeEscaped(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == string(config.DriverPostgres) {
+		return column + ` ILIKE ? ESCAPE '\'`
+	}
+	return "LOWER(" + column + `) LIKE LOWER(?) ESCAPE '\'`
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// matchesCondition evaluates a condition against a struct in Go
+func matchesCondition[T any](item *T, c Condition) bool {
+	if c.IsGroup() {
+		for _, child := range c.Children {
+			matched := matchesCondition(item, child)
+			if c.Logic == LogicOr && matched {
+				return true
+			}
+			if c.Logic == LogicAnd && !matched {
+				return false
+			}
+		}
+		return c.Logic == LogicAnd
+	}
+
+	field := fieldOf(item, c.Column)
+
+	switch c.Op {
+	case OpIn:
+		for _, value := range c.Values {
+			if compareValues(field, reflect.ValueOf(value)) == 0 {
+				return true
+			}
+		}
+		return false
+	case OpBetween:
+		return compareValues(field, reflect.ValueOf(c.Values[0])) >= 0 &&
+			compareValues(field, reflect.ValueOf(c.Values[1])) <= 0
+	case OpContains:
+		return strings.Contains(strings.ToLower(field.String()), strings.ToLower(c.Values[0].(string)))
+	case OpPrefix:
+		return strings.HasPrefix(strings.ToLower(field.String()), strings.ToLower(c.Values[0].(string)))
+	}
+
+	result := compareValues(field, reflect.ValueOf(c.Values[0]))
+	switch c.Op {
+	case OpEq:
+		return result == 0
+	case OpNe:
+		return result != 0
+	case OpGt:
+		return result > 0
+	case OpGte:
+		return result >= 0
+	case OpLt:
+		return result < 0
+	case OpLte:
+		return result <= 0
+	}
+	return false
+}
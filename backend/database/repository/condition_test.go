@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditions(t *testing.T) {
+	setupTestData(t)
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	stored, _, err := studentRepo.Query(nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, memoryRepo.CreateMany(stored))
+
+	cases := []struct {
+		name          string
+		condition     repository.Condition
+		expectedNames []string
+	}{
+		{
+			name:          "grade comparison",
+			condition:     repository.Condition{Column: config.Grade, Op: repository.OpGte, Values: []any{uint(92)}},
+			expectedNames: []string{"Student07", "Student09", "Student10"},
+		},
+		{
+			name: "IN list and range",
+			condition: repository.Condition{Logic: repository.LogicAnd, Children: []repository.Condition{
+				{Column: config.Subject, Op: repository.OpIn, Values: []any{string(config.Physics), string(config.Chemistry), string(config.Art)}},
+				{Column: config.Grade, Op: repository.OpBetween, Values: []any{uint(85), uint(90)}},
+			}},
+			expectedNames: []string{"Student02", "Student03"},
+		},
+		{
+			name: "OR group",
+			condition: repository.Condition{Logic: repository.LogicOr, Children: []repository.Condition{
+				{Column: config.Subject, Op: repository.OpEq, Values: []any{string(config.Music)}},
+				{Column: config.Grade, Op: repository.OpLt, Values: []any{uint(70)}},
+			}},
+			expectedNames: []string{"Student06", "Student08"},
+		},
+		{
+			name:          "contains is case insensitive",
+			condition:     repository.Condition{Column: config.Name, Op: repository.OpContains, Values: []any{"DENT1"}},
+			expectedNames: []string{"Student10"},
+		},
+		{
+			name:          "contains escapes like wildcards",
+			condition:     repository.Condition{Column: config.Name, Op: repository.OpContains, Values: []any{"%"}},
+			expectedNames: []string{},
+		},
+		{
+			name:          "prefix and not equal",
+			condition:     repository.Condition{Column: config.Subject, Op: repository.OpNe, Values: []any{string(config.Art)}},
+			expectedNames: []string{"Student01", "Student02", "Student03", "Student04", "Student05", "Student06", "Student08", "Student09", "Student10"},
+		},
+	}
+
+	repos := map[string]repository.StudentRepository[model.StudentTest]{
+		"database": studentRepo,
+		"memory":   memoryRepo,
+	}
+
+	for repoName, repo := range repos {
+		for _, tt := range cases {
+			t.Run(repoName+" "+tt.name, func(t *testing.T) {
+				students, count, err := repo.Query(
+					[]repository.QueryOption{repository.WithCondition(&tt.condition)},
+					nil,
+				)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(tt.expectedNames)), count)
+
+				names := make([]string, len(students))
+				for i, student := range students {
+					names[i] = student.Student_name
+				}
+				assert.ElementsMatch(t, tt.expectedNames, names)
+			})
+		}
+	}
+}
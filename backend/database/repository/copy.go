@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"file-uploader/config"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+)
+
+// copyMany streams the items with COPY FROM STDIN into a staging table and
+// moves them into the table with a single INSERT, duplicated ids then fail
+// the whole call instead of aborting the copy halfway through, unless
+// skipDuplicates
+func (r *StudentRepo[T]) copyMany(items []*T, skipDuplicates bool) error {
+	ctx := context.Background()
+
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	columns := stmt.Schema.DBNames
+
+	// COPY skips the gorm hooks, run them for the ids generated in Go. Rows
+	// are read from the items as they are sent
+	rows := pgx.CopyFromSlice(len(items), func(i int) ([]any, error) {
+		if hook, ok := any(items[i]).(callbacks.BeforeCreateInterface); ok {
+			if err := hook.BeforeCreate(r.db); err != nil {
+				return nil, err
+			}
+		}
+
+		value := reflect.ValueOf(items[i]).Elem()
+		row := make([]any, len(columns))
+		for j, column := range columns {
+			row[j], _ = stmt.Schema.FieldsByDBName[column].ValueOf(ctx, value)
+		}
+		return row, nil
+	})
+
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("copy needs the pgx driver, got %T", driverConn)
+		}
+
+		tx, err := stdConn.Conn().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		return copyThroughStaging(ctx, tx, stmt.Schema.Table, columns, rows, skipDuplicates)
+	})
+}
+
+// copyThroughStaging copies rows into a temporary table shaped like table,
+// without its constraints, then inserts them all unless some id is taken.
+// With skipDuplicates the rows whose id is taken are left out instead
+func copyThroughStaging(ctx context.Context, tx pgx.Tx, table string, columns []string, rows pgx.CopyFromSource, skipDuplicates bool) error {
+	target := pgx.Identifier{table}.Sanitize()
+	staging := pgx.Identifier{"staging_" + table}
+
+	_, err := tx.Exec(ctx, fmt.Sprintf(
+		"CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP",
+		staging.Sanitize(), target,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	copied, err := tx.CopyFrom(ctx, staging, columns, rows)
+	if err != nil {
+		return fmt.Errorf("failed to copy rows: %w", err)
+	}
+
+	list := make([]string, len(columns))
+	for i, column := range columns {
+		list[i] = pgx.Identifier{column}.Sanitize()
+	}
+	names := strings.Join(list, ", ")
+
+	// Conflicting ids, with stored rows or within the copy, are skipped by
+	// the insert and counted to reject the call
+	tag, err := tx.Exec(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT DO NOTHING",
+		target, names, names, staging.Sanitize(),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to insert staged rows: %w", err)
+	}
+	if skipped := copied - tag.RowsAffected(); skipped > 0 && !skipDuplicates {
+		return fmt.Errorf("%w: %d of %d students", config.ErrDuplicateStudent, skipped, copied)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	processor "file-uploader/internal/service/csv"
+	Seeder "file-uploader/internal/service/csv/seeder"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var insertMethods = []config.InsertMethod{config.InsertBatches, config.InsertCopy}
+
+// seededStudents draws count valid students, the same ones for a seed
+func seededStudents(t testing.TB, seed int64, count int) []*model.StudentTest {
+	generator, err := Seeder.NewGenerator(Seeder.Options{Seed: seed})
+	require.NoError(t, err)
+
+	students := make([]*model.StudentTest, count)
+	for i := range students {
+		students[i], err = processor.StudentTestMapper(generator.Next().Record())
+		require.NoError(t, err)
+	}
+	return students
+}
+
+func TestInsertMethods(t *testing.T) {
+	for _, method := range insertMethods {
+		repo := repository.NewStudentRepository[model.StudentTest](testDB, repository.WithInsertMethod(method)).
+			ForTenant(uuid.New())
+
+		t.Run(fmt.Sprintf("%s inserts every row", method), func(t *testing.T) {
+			students := seededStudents(t, 1, 1200)
+			require.NoError(t, repo.CreateMany(students))
+
+			_, count, err := repo.Query(nil, nil)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(students), count)
+
+			deleted, err := repo.DeleteAll()
+			require.NoError(t, err)
+			assert.EqualValues(t, len(students), deleted)
+		})
+
+		t.Run(fmt.Sprintf("%s rejects duplicated ids", method), func(t *testing.T) {
+			students := seededStudents(t, 2, 10)
+			require.NoError(t, repo.CreateMany(students[:1]))
+
+			copied := *students[0]
+			assert.Error(t, repo.CreateMany([]*model.StudentTest{students[1], &copied}))
+
+			// COPY inserts nothing when one of the rows conflicts
+			if method == config.InsertCopy && testDB.Dialector.Name() == string(config.DriverPostgres) {
+				_, count, err := repo.Query(nil, nil)
+				require.NoError(t, err)
+				assert.EqualValues(t, 1, count)
+			}
+
+			_, err := repo.DeleteAll()
+			require.NoError(t, err)
+		})
+
+		t.Run(fmt.Sprintf("%s skips duplicated ids when replaying", method), func(t *testing.T) {
+			students := seededStudents(t, 5, 10)
+			require.NoError(t, repo.CreateMany(students[:4]))
+
+			copied := *students[0]
+			require.NoError(t, repo.SkipDuplicates().CreateMany(append(students[2:], &copied)))
+
+			_, count, err := repo.Query(nil, nil)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(students), count)
+
+			_, err = repo.DeleteAll()
+			require.NoError(t, err)
+		})
+	}
+}
+
+// BenchmarkCreateMany compares the insert methods on seeded students, run
+// with TEST_DB_DRIVER=postgres for COPY, SQLite always uses batches
+func BenchmarkCreateMany(b *testing.B) {
+	const rows = 20_000
+
+	for _, method := range insertMethods {
+		repo := repository.NewStudentRepository[model.StudentTest](testDB, repository.WithInsertMethod(method)).
+			ForTenant(uuid.New())
+
+		b.Run(string(method), func(b *testing.B) {
+			for i := range b.N {
+				b.StopTimer()
+				students := seededStudents(b, int64(i+1), rows)
+				b.StartTimer()
+
+				require.NoError(b, repo.CreateMany(students))
+
+				b.StopTimer()
+				_, err := repo.DeleteAll()
+				require.NoError(b, err)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(rows*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
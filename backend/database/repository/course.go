@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseRepo manages the course catalog of a single tenant
+type CourseRepo struct {
+	db     *gorm.DB
+	tenant uuid.UUID
+}
+
+func NewCourseRepository(db *gorm.DB) CourseRepository {
+	return &CourseRepo{db: db, tenant: config.DefaultTenantID}
+}
+
+func (r *CourseRepo) ForTenant(tenantID uuid.UUID) CourseRepository {
+	return &CourseRepo{db: r.db, tenant: tenantOrDefault(tenantID)}
+}
+
+// scope starts every query on the courses of the tenant
+func (r *CourseRepo) scope() *gorm.DB {
+	return r.db.Where("tenant_id = ?", r.tenant)
+}
+
+func (r *CourseRepo) Create(course *model.Course) error {
+	if course == nil || strings.TrimSpace(course.Name) == "" {
+		return config.ErrMissingCourseData
+	}
+	course.Name = strings.TrimSpace(course.Name)
+
+	exists, err := r.Exists(course.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return config.ErrCourseAlreadyExist
+	}
+
+	if err := r.checkScale(course.GradingScale); err != nil {
+		return err
+	}
+
+	course.TenantID = r.tenant
+	return r.db.Create(course).Error
+}
+
+func (r *CourseRepo) List() ([]*model.Course, error) {
+	var courses []*model.Course
+	result := r.scope().Order("name asc").Find(&courses)
+	return courses, result.Error
+}
+
+func (r *CourseRepo) Get(name string) (*model.Course, error) {
+	var course model.Course
+	err := r.scope().Where("name = ?", name).First(&course).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrCourseNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+// Update renames a course and sets its grading scale, student records follow
+// the rename through the cascading foreign key
+func (r *CourseRepo) Update(name string, course *model.Course) error {
+	if course == nil || strings.TrimSpace(course.Name) == "" {
+		return config.ErrMissingCourseData
+	}
+	newName := strings.TrimSpace(course.Name)
+
+	if _, err := r.Get(name); err != nil {
+		return err
+	}
+
+	if newName != name {
+		exists, err := r.Exists(newName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return config.ErrCourseAlreadyExist
+		}
+	}
+
+	if err := r.checkScale(course.GradingScale); err != nil {
+		return err
+	}
+
+	result := r.scope().Model(&model.Course{}).Where("name = ?", name).Updates(map[string]any{
+		"name":          newName,
+		"grading_scale": course.GradingScale,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	course.TenantID = r.tenant
+	return r.scope().Where("name = ?", newName).First(course).Error
+}
+
+func (r *CourseRepo) Delete(name string) error {
+	if _, err := r.Get(name); err != nil {
+		return err
+	}
+
+	// Refuse to delete courses that still have student records
+	var count int64
+	if err := r.scope().Model(&model.Student{}).Where("subject = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return config.ErrCourseInUse
+	}
+
+	return r.scope().Where("name = ?", name).Delete(&model.Course{}).Error
+}
+
+func (r *CourseRepo) Exists(name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+
+	var count int64
+	err := r.scope().Model(&model.Course{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// checkScale makes sure an assigned grading scale exists
+func (r *CourseRepo) checkScale(scale *string) error {
+	if scale == nil {
+		return nil
+	}
+
+	var count int64
+	if err := r.db.Model(&model.GradingScale{}).Where("name = ?", *scale).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return config.ErrGradingScaleNotExist
+	}
+	return nil
+}
+
+// CourseSet loads the catalog into a lookup set used by validators
+func CourseSet(repo CourseRepository) (map[string]bool, error) {
+	courses, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(courses))
+	for _, course := range courses {
+		set[course.Name] = true
+	}
+	return set, nil
+}
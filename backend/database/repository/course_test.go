@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCourseCRUD(t *testing.T) {
+	courseRepo := repository.NewCourseRepository(testDB)
+	const name = "Test Economics"
+
+	// Clear any previous test data
+	testDB.Where("name IN ?", []string{name, name + " II"}).Delete(&model.Course{})
+
+	t.Run("default catalog is seeded", func(t *testing.T) {
+		for _, course := range config.DefaultCourses {
+			exists, err := courseRepo.Exists(string(course))
+			require.NoError(t, err)
+			assert.True(t, exists, course)
+		}
+	})
+
+	t.Run("create a new course", func(t *testing.T) {
+		err := courseRepo.Create(&model.Course{Name: name})
+		assert.NoError(t, err)
+
+		err = courseRepo.Create(&model.Course{Name: name})
+		assert.Equal(t, config.ErrCourseAlreadyExist, err)
+
+		err = courseRepo.Create(&model.Course{Name: " "})
+		assert.Equal(t, config.ErrMissingCourseData, err)
+	})
+
+	t.Run("rename a course", func(t *testing.T) {
+		course := &model.Course{Name: name + " II"}
+		err := courseRepo.Update(name, course)
+		require.NoError(t, err)
+		assert.Equal(t, name+" II", course.Name)
+
+		_, err = courseRepo.Get(name)
+		assert.Equal(t, config.ErrCourseNotExist, err)
+	})
+
+	t.Run("delete a course", func(t *testing.T) {
+		err := courseRepo.Delete(name + " II")
+		assert.NoError(t, err)
+
+		err = courseRepo.Delete(name + " II")
+		assert.Equal(t, config.ErrCourseNotExist, err)
+	})
+}
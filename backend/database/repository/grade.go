@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GradeRepo manages the profiles and grade records of a single tenant
+type GradeRepo struct {
+	db     *gorm.DB
+	tenant uuid.UUID
+}
+
+func NewGradeRepository(db *gorm.DB) GradeRepository {
+	return &GradeRepo{db: db, tenant: config.DefaultTenantID}
+}
+
+func (r *GradeRepo) ForTenant(tenantID uuid.UUID) GradeRepository {
+	return &GradeRepo{db: r.db, tenant: tenantOrDefault(tenantID)}
+}
+
+// Import maps flat student rows into profiles and grade records. Rows sharing
+// a name belong to the same profile, and re-importing a row is a no-op since
+// grade records reuse the flat Student_id
+func (r *GradeRepo) Import(rows []*model.Student, term string) error {
+	const batchSize = 500
+
+	if len(rows) == 0 {
+		return config.ErrMissingStudentData
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Create missing profiles
+		seen := make(map[string]bool)
+		var names []string
+		var profiles []*model.StudentProfile
+		for _, row := range rows {
+			if seen[row.Student_name] {
+				continue
+			}
+			seen[row.Student_name] = true
+			names = append(names, row.Student_name)
+			profiles = append(profiles, &model.StudentProfile{ID: uuid.New(), TenantID: r.tenant, Name: row.Student_name})
+		}
+
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "name"}}, DoNothing: true}).
+			CreateInBatches(profiles, batchSize).Error
+		if err != nil {
+			return err
+		}
+
+		// Resolve profile ids, including the ones created by earlier imports
+		var stored []*model.StudentProfile
+		if err := tx.Select("id", "name").Where("tenant_id = ? AND name IN ?", r.tenant, names).Find(&stored).Error; err != nil {
+			return err
+		}
+
+		profileIDs := make(map[string]uuid.UUID, len(stored))
+		for _, profile := range stored {
+			profileIDs[profile.Name] = profile.ID
+		}
+
+		now := time.Now()
+		records := make([]*model.GradeRecord, 0, len(rows))
+		for _, row := range rows {
+			id := row.Student_id
+			if id == uuid.Nil {
+				id = uuid.New()
+			}
+
+			records = append(records, &model.GradeRecord{
+				ID:         id,
+				TenantID:   r.tenant,
+				ProfileID:  profileIDs[row.Student_name],
+				Subject:    row.Subject,
+				Grade:      row.Grade,
+				Term:       term,
+				RecordedAt: now,
+			})
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(records, batchSize).Error
+	})
+}
+
+// DeleteAll removes the grade records and profiles of the tenant, it returns
+// the number of grade records
+// Delete removes the grade records of the ids, and the profiles left
+// without grades. Grade records share the ids of the flat rows
+func (r *GradeRepo) Delete(ids []uuid.UUID) (int64, error) {
+	const chunkSize = 1000
+
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for chunk := range slices.Chunk(ids, chunkSize) {
+			result := tx.Where("tenant_id = ? AND id IN ?", r.tenant, chunk).Delete(&model.GradeRecord{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += result.RowsAffected
+		}
+
+		return tx.Where("tenant_id = ? AND NOT EXISTS (?)", r.tenant,
+			tx.Model(&model.GradeRecord{}).Select("1").Where("grade_records.profile_id = student_profiles.id"),
+		).Delete(&model.StudentProfile{}).Error
+	})
+	return deleted, err
+}
+
+func (r *GradeRepo) DeleteAll() (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ?", r.tenant).Delete(&model.GradeRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		return tx.Where("tenant_id = ?", r.tenant).Delete(&model.StudentProfile{}).Error
+	})
+	return deleted, err
+}
+
+// Backfill imports every row of the tenant in the flat students table
+func (r *GradeRepo) Backfill(term string) error {
+	const batchSize = 2000
+
+	var batch []*model.Student
+	result := r.db.Model(&model.Student{}).Where("tenant_id = ?", r.tenant).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return r.Import(batch, term)
+	})
+	return result.Error
+}
+
+func (r *GradeRepo) QueryNested(query NestedQuery) ([]*model.StudentProfile, int64, error) {
+	db := r.db.Model(&model.StudentProfile{}).Where("tenant_id = ?", r.tenant)
+
+	if query.Name != "" {
+		db = db.Where(ILike(db, "name"), query.Name+"%")
+	}
+
+	if query.Subject != "" {
+		db = db.Where("id IN (?)", r.db.Model(&model.GradeRecord{}).Select("profile_id").Where("subject = ?", query.Subject))
+	}
+
+	if query.Scope != nil {
+		db = db.Where("id IN (?)", r.db.Model(&model.GradeRecord{}).Select("profile_id").Where("subject IN ?", query.Scope))
+	}
+
+	// Get total count before pagination
+	var totalCount int64
+	if err := db.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page > 0 && query.Size > 0 {
+		db = db.Offset((query.Page - 1) * query.Size).Limit(query.Size)
+	}
+
+	// Only preload the grades matching the subject filter
+	db = db.Preload("Grades", func(db *gorm.DB) *gorm.DB {
+		if query.Subject != "" {
+			db = db.Where("subject = ?", query.Subject)
+		}
+		if query.Scope != nil {
+			db = db.Where("subject IN ?", query.Scope)
+		}
+		return db.Order("subject asc, recorded_at asc")
+	})
+
+	var profiles []*model.StudentProfile
+	result := db.Order("name asc").Find(&profiles)
+	return profiles, totalCount, result.Error
+}
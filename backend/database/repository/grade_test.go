@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGradeImport(t *testing.T) {
+	gradeRepo := repository.NewGradeRepository(testDB)
+	names := []string{"Normalized Ali", "Normalized Omar"}
+
+	// Clear any previous test data
+	testDB.Where("name IN ?", names).Delete(&model.StudentProfile{})
+
+	rows := []*model.Student{
+		{Student_id: uuid.New(), Student_name: names[0], Subject: string(config.Physics), Grade: 90},
+		{Student_id: uuid.New(), Student_name: names[0], Subject: string(config.Chemistry), Grade: 80},
+		{Student_id: uuid.New(), Student_name: names[0], Subject: string(config.Art), Grade: 70},
+		{Student_id: uuid.New(), Student_name: names[1], Subject: string(config.Physics), Grade: 60},
+	}
+
+	t.Run("rows of the same student share a profile", func(t *testing.T) {
+		require.NoError(t, gradeRepo.Import(rows, "2024-fall"))
+
+		profiles, count, err := gradeRepo.QueryNested(repository.NestedQuery{Name: "Normalized"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		assert.Equal(t, names[0], profiles[0].Name)
+		assert.Len(t, profiles[0].Grades, 3)
+		assert.Equal(t, "2024-fall", profiles[0].Grades[0].Term)
+		assert.Len(t, profiles[1].Grades, 1)
+	})
+
+	t.Run("re-importing the same rows is a no-op", func(t *testing.T) {
+		require.NoError(t, gradeRepo.Import(rows, "2024-fall"))
+
+		profiles, _, err := gradeRepo.QueryNested(repository.NestedQuery{Name: names[0]})
+		require.NoError(t, err)
+		require.Len(t, profiles, 1)
+		assert.Len(t, profiles[0].Grades, 3)
+	})
+
+	t.Run("subject filter keeps only matching grades", func(t *testing.T) {
+		profiles, count, err := gradeRepo.QueryNested(repository.NestedQuery{Name: "Normalized", Subject: string(config.Chemistry)})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+		require.Len(t, profiles[0].Grades, 1)
+		assert.Equal(t, uint(80), profiles[0].Grades[0].Grade)
+	})
+
+	t.Run("no rows, should return error", func(t *testing.T) {
+		err := gradeRepo.Import(nil, "")
+		assert.Equal(t, config.ErrMissingStudentData, err)
+	})
+}
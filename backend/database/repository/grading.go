@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GradingScaleRepo manages grading scales, scales are shared by every tenant
+// while ForCourses resolves the courses of a single tenant
+type GradingScaleRepo struct {
+	db     *gorm.DB
+	tenant uuid.UUID
+}
+
+func NewGradingScaleRepository(db *gorm.DB) GradingScaleRepository {
+	return &GradingScaleRepo{db: db, tenant: config.DefaultTenantID}
+}
+
+func (r *GradingScaleRepo) ForTenant(tenantID uuid.UUID) GradingScaleRepository {
+	return &GradingScaleRepo{db: r.db, tenant: tenantOrDefault(tenantID)}
+}
+
+// bandsByMinGrade preloads bands from the highest minimum, the order Band expects
+func bandsByMinGrade(db *gorm.DB) *gorm.DB {
+	return db.Order("min_grade desc")
+}
+
+func (r *GradingScaleRepo) Create(scale *model.GradingScale) error {
+	if err := validateScale(scale); err != nil {
+		return err
+	}
+
+	exists, err := r.exists(scale.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return config.ErrGradingScaleAlreadyExist
+	}
+
+	if err := r.db.Create(scale).Error; err != nil {
+		return err
+	}
+	sortBands(scale)
+	return nil
+}
+
+func (r *GradingScaleRepo) List() ([]*model.GradingScale, error) {
+	var scales []*model.GradingScale
+	result := r.db.Preload("Bands", bandsByMinGrade).Order("name asc").Find(&scales)
+	return scales, result.Error
+}
+
+func (r *GradingScaleRepo) Get(name string) (*model.GradingScale, error) {
+	var scale model.GradingScale
+	err := r.db.Preload("Bands", bandsByMinGrade).Where("name = ?", name).First(&scale).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrGradingScaleNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scale, nil
+}
+
+// Update replaces the bands of a scale, the name can't change
+func (r *GradingScaleRepo) Update(name string, scale *model.GradingScale) error {
+	if scale == nil {
+		return config.ErrMissingGradingScaleData
+	}
+	scale.Name = name
+
+	if err := validateScale(scale); err != nil {
+		return err
+	}
+
+	existing, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	scale.CreatedAt = existing.CreatedAt
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scale_name = ?", name).Delete(&model.GradeBand{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(scale).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	sortBands(scale)
+	return nil
+}
+
+// Delete refuses to delete the default scale and scales assigned to courses
+func (r *GradingScaleRepo) Delete(name string) error {
+	if _, err := r.Get(name); err != nil {
+		return err
+	}
+
+	if name == config.DefaultGradingScale {
+		return config.ErrGradingScaleInUse
+	}
+
+	var count int64
+	if err := r.db.Model(&model.Course{}).Where("grading_scale = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return config.ErrGradingScaleInUse
+	}
+
+	return r.db.Where("name = ?", name).Delete(&model.GradingScale{}).Error
+}
+
+// ForCourses resolves the grading scale of every course of the catalog
+func (r *GradingScaleRepo) ForCourses() (map[string]*model.GradingScale, error) {
+	scales, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*model.GradingScale, len(scales))
+	for _, scale := range scales {
+		byName[scale.Name] = scale
+	}
+
+	var courses []*model.Course
+	if err := r.db.Where("tenant_id = ?", r.tenant).Find(&courses).Error; err != nil {
+		return nil, err
+	}
+
+	byCourse := make(map[string]*model.GradingScale, len(courses))
+	for _, course := range courses {
+		name := config.DefaultGradingScale
+		if course.GradingScale != nil {
+			name = *course.GradingScale
+		}
+		if scale, ok := byName[name]; ok {
+			byCourse[course.Name] = scale
+		}
+	}
+	return byCourse, nil
+}
+
+func (r *GradingScaleRepo) exists(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.GradingScale{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// validateScale requires unique letters and thresholds with a band starting at 0,
+// so every grade has a letter
+func validateScale(scale *model.GradingScale) error {
+	if scale == nil || strings.TrimSpace(scale.Name) == "" || len(scale.Bands) == 0 {
+		return config.ErrMissingGradingScaleData
+	}
+	scale.Name = strings.TrimSpace(scale.Name)
+
+	letters := make(map[string]bool, len(scale.Bands))
+	minGrades := make(map[uint]bool, len(scale.Bands))
+	for i := range scale.Bands {
+		band := &scale.Bands[i]
+		band.ID = 0
+		band.ScaleName = scale.Name
+		band.Letter = strings.TrimSpace(band.Letter)
+
+		if band.Letter == "" {
+			return fmt.Errorf("%w: band %d has no letter", config.ErrInvalidGradingScale, i+1)
+		}
+		if letters[strings.ToUpper(band.Letter)] {
+			return fmt.Errorf("%w: letter %s is used twice", config.ErrInvalidGradingScale, band.Letter)
+		}
+		if minGrades[band.MinGrade] {
+			return fmt.Errorf("%w: minimum grade %d is used twice", config.ErrInvalidGradingScale, band.MinGrade)
+		}
+		letters[strings.ToUpper(band.Letter)] = true
+		minGrades[band.MinGrade] = true
+	}
+
+	if !minGrades[0] {
+		return fmt.Errorf("%w: a band must start at grade 0", config.ErrInvalidGradingScale)
+	}
+	return nil
+}
+
+func sortBands(scale *model.GradingScale) {
+	slices.SortFunc(scale.Bands, func(a, b model.GradeBand) int {
+		return int(b.MinGrade) - int(a.MinGrade)
+	})
+}
+
+// WithLetters keeps records whose grade maps to one of the letters under the
+// scale of their subject
+func WithLetters(scales map[string]*model.GradingScale, letters ...string) QueryOption {
+	return func(q *Query) {
+		if len(letters) == 0 {
+			return
+		}
+		q.Conditions = append(q.Conditions, letterCondition(scales, letters))
+	}
+}
+
+// letterCondition translates letters to grade ranges per subject
+func letterCondition(scales map[string]*model.GradingScale, letters []string) Condition {
+	wanted := make(map[string]bool, len(letters))
+	for _, letter := range letters {
+		wanted[strings.ToUpper(strings.TrimSpace(letter))] = true
+	}
+
+	// Deterministic SQL for the same scales
+	courses := make([]string, 0, len(scales))
+	for course := range scales {
+		courses = append(courses, course)
+	}
+	slices.Sort(courses)
+
+	var ranges []Condition
+	for _, course := range courses {
+		bands := scales[course].Bands
+		for i, band := range bands {
+			if !wanted[strings.ToUpper(band.Letter)] {
+				continue
+			}
+
+			children := []Condition{
+				{Column: config.Subject, Op: OpEq, Values: []any{course}},
+				{Column: config.Grade, Op: OpGte, Values: []any{band.MinGrade}},
+			}
+			if i > 0 {
+				children = append(children, Condition{Column: config.Grade, Op: OpLt, Values: []any{bands[i-1].MinGrade}})
+			}
+			ranges = append(ranges, Condition{Logic: LogicAnd, Children: children})
+		}
+	}
+
+	// No band has the letters, match nothing
+	if len(ranges) == 0 {
+		return Condition{Column: config.Subject, Op: OpIn, Values: []any{}}
+	}
+	return Condition{Logic: LogicOr, Children: ranges}
+}
+
+// letterCase builds a CASE expression giving the letter of a record under the
+// scale of its subject
+func letterCase(scales map[string]*model.GradingScale) (string, []any) {
+	courses := make([]string, 0, len(scales))
+	for course := range scales {
+		courses = append(courses, course)
+	}
+	slices.Sort(courses)
+
+	var sb strings.Builder
+	var args []any
+	sb.WriteString("CASE")
+	for _, course := range courses {
+		for _, band := range scales[course].Bands {
+			sb.WriteString(fmt.Sprintf(" WHEN %s = ? AND %s >= ? THEN ?", config.Subject, config.Grade))
+			args = append(args, course, band.MinGrade, band.Letter)
+		}
+	}
+	sb.WriteString(" END")
+	return sb.String(), args
+}
@@ -0,0 +1,136 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGradingScaleCRUD(t *testing.T) {
+	const name = "test_honors"
+	scales := repository.NewGradingScaleRepository(testDB)
+
+	// Clear any previous test data
+	testDB.Where("name = ?", name).Delete(&model.GradingScale{})
+
+	scale := &model.GradingScale{Name: name, Bands: []model.GradeBand{
+		{Letter: "Pass", MinGrade: 0, Passing: true},
+		{Letter: "Honors", MinGrade: 85, Passing: true},
+	}}
+	require.NoError(t, scales.Create(scale))
+	assert.ErrorIs(t, scales.Create(scale), config.ErrGradingScaleAlreadyExist)
+
+	stored, err := scales.Get(name)
+	require.NoError(t, err)
+	require.Len(t, stored.Bands, 2)
+
+	band, ok := stored.Band(90)
+	require.True(t, ok)
+	assert.Equal(t, "Honors", band.Letter)
+	band, _ = stored.Band(84)
+	assert.Equal(t, "Pass", band.Letter)
+
+	// Scales must cover every grade with distinct letters
+	invalid := []*model.GradingScale{
+		{Name: "gaps", Bands: []model.GradeBand{{Letter: "A", MinGrade: 50}}},
+		{Name: "twice", Bands: []model.GradeBand{{Letter: "A", MinGrade: 0}, {Letter: "A", MinGrade: 50}}},
+	}
+	for _, scale := range invalid {
+		assert.ErrorIs(t, scales.Create(scale), config.ErrInvalidGradingScale, scale.Name)
+	}
+	assert.ErrorIs(t, scales.Create(&model.GradingScale{Name: "empty"}), config.ErrMissingGradingScaleData)
+
+	// Assigned scales can't be deleted
+	courses := repository.NewCourseRepository(testDB)
+	course := &model.Course{Name: string(config.Music), GradingScale: &scale.Name}
+	require.NoError(t, courses.Update(string(config.Music), course))
+	t.Cleanup(func() {
+		require.NoError(t, courses.Update(string(config.Music), &model.Course{Name: string(config.Music)}))
+		testDB.Where("name = ?", name).Delete(&model.GradingScale{})
+	})
+
+	byCourse, err := scales.ForCourses()
+	require.NoError(t, err)
+	assert.Equal(t, name, byCourse[string(config.Music)].Name)
+	assert.Equal(t, config.DefaultGradingScale, byCourse[string(config.Art)].Name)
+
+	assert.ErrorIs(t, scales.Delete(name), config.ErrGradingScaleInUse)
+	assert.ErrorIs(t, scales.Delete(config.DefaultGradingScale), config.ErrGradingScaleInUse)
+
+	missing := "not_a_scale"
+	err = courses.Update(string(config.Music), &model.Course{Name: string(config.Music), GradingScale: &missing})
+	assert.ErrorIs(t, err, config.ErrGradingScaleNotExist)
+}
+
+func TestLetterGrades(t *testing.T) {
+	// Clear any previous test data
+	testDB.Where("1=1").Delete(&model.StudentTest{})
+
+	testData := []*model.StudentTest{
+		{Student_name: "Ali", Subject: string(config.Physics), Grade: 95},
+		{Student_name: "Omar", Subject: string(config.Physics), Grade: 85},
+		{Student_name: "Saad", Subject: string(config.Physics), Grade: 40},
+		{Student_name: "Ali", Subject: string(config.Art), Grade: 55},
+		{Student_name: "Omar", Subject: string(config.Art), Grade: 45},
+	}
+	require.NoError(t, studentRepo.CreateMany(testData))
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	require.NoError(t, memoryRepo.CreateMany(testData))
+
+	scaleRepo := repository.NewGradingScaleRepository(testDB)
+	letter, err := scaleRepo.Get(config.DefaultGradingScale)
+	require.NoError(t, err)
+	passFail, err := scaleRepo.Get("pass_fail")
+	require.NoError(t, err)
+
+	// Art is graded pass/fail
+	scales := map[string]*model.GradingScale{
+		string(config.Physics): letter,
+		string(config.Art):     passFail,
+	}
+
+	cases := []struct {
+		name     string
+		letters  []string
+		expected int64
+	}{
+		{name: "single letter", letters: []string{"A"}, expected: 1},
+		{name: "letters are case insensitive", letters: []string{"a", "b"}, expected: 2},
+		{name: "a letter shared by scales", letters: []string{"F"}, expected: 2},
+		{name: "a letter of one scale", letters: []string{"P"}, expected: 1},
+		{name: "unknown letter", letters: []string{"Z"}, expected: 0},
+	}
+
+	repos := map[string]repository.StudentRepository[model.StudentTest]{
+		"database": studentRepo,
+		"memory":   memoryRepo,
+	}
+
+	for repoName, repo := range repos {
+		for _, tt := range cases {
+			t.Run(repoName+" "+tt.name, func(t *testing.T) {
+				_, count, err := repo.Query([]repository.QueryOption{repository.WithLetters(scales, tt.letters...)}, nil)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, count)
+			})
+		}
+	}
+
+	t.Run("distribution", func(t *testing.T) {
+		analytics := repository.NewAnalyticsRepository[model.StudentTest](testDB)
+		counts, err := analytics.LetterDistribution(nil, scales)
+		require.NoError(t, err)
+
+		var result []string
+		for _, count := range counts {
+			result = append(result, count.Subject+"/"+count.Letter)
+			assert.Equal(t, int64(1), count.Count)
+		}
+		assert.Equal(t, []string{"Art/F", "Art/P", "Physics/A", "Physics/B", "Physics/F"}, result)
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepo struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &ImportJobRepo{db: db}
+}
+
+// Create stores the job with its files, a nil scope is stored as every
+// subject
+func (r *ImportJobRepo) Create(job *model.ImportJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.TenantID = tenantOrDefault(job.TenantID)
+	if job.Status == "" {
+		job.Status = config.ImportQueued
+	}
+	if job.Scope == nil {
+		job.AllSubjects = true
+		job.Scope = []string{}
+	}
+	for _, file := range job.Files {
+		file.JobID = job.ID
+	}
+	return r.db.Create(job).Error
+}
+
+func (r *ImportJobRepo) Get(id uuid.UUID) (*model.ImportJob, error) {
+	var job model.ImportJob
+	err := r.db.Preload("Files", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&job, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrImportJobNotExist
+	}
+	return &job, err
+}
+
+// ListByStatus returns the jobs in a status, oldest first
+func (r *ImportJobRepo) ListByStatus(status config.ImportStatus) ([]*model.ImportJob, error) {
+	jobs := []*model.ImportJob{}
+	err := r.db.Preload("Files", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("status = ?", status).Order("created_at, id").Find(&jobs).Error
+	return jobs, err
+}
+
+// SetStatus moves the job to a status, message is the error of failed jobs
+func (r *ImportJobRepo) SetStatus(id uuid.UUID, status config.ImportStatus, message string) error {
+	result := r.db.Model(&model.ImportJob{}).Where("id = ?", id).
+		Updates(map[string]any{"status": status, "error": message})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return config.ErrImportJobNotExist
+	}
+	return nil
+}
+
+// Checkpoint records how many records of a file are stored
+func (r *ImportJobRepo) Checkpoint(id uuid.UUID, position int, rows int64) error {
+	return r.db.Model(&model.ImportFile{}).
+		Where("job_id = ? AND position = ?", id, position).
+		Update("rows_committed", rows).Error
+}
+
+// Dispatch records how many records of a file were handed to the inserts,
+// the ones past the checkpoint may be stored already
+func (r *ImportJobRepo) Dispatch(id uuid.UUID, position int, rows int64) error {
+	return r.db.Model(&model.ImportFile{}).
+		Where("job_id = ? AND position = ?", id, position).
+		Update("rows_dispatched", rows).Error
+}
+
+func (r *ImportJobRepo) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.ImportJob{}, "id = ?", id).Error
+}
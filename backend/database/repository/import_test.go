@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportJobRepository(t *testing.T) {
+	imports := repository.NewImportJobRepository(testDB)
+
+	job := &model.ImportJob{
+		Term:  "2024-fall",
+		Scope: []string{string(config.Physics)},
+		Files: []*model.ImportFile{
+			{Position: 1, Path: "/spool/1.csv"},
+			{Position: 0, Path: "/spool/0.csv"},
+		},
+	}
+	require.NoError(t, imports.Create(job))
+	assert.Equal(t, config.ImportQueued, job.Status)
+	assert.Equal(t, config.DefaultTenantID, job.TenantID)
+
+	require.NoError(t, imports.Checkpoint(job.ID, 1, 2000))
+	require.NoError(t, imports.SetStatus(job.ID, config.ImportInterrupted, ""))
+
+	stored, err := imports.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportInterrupted, stored.Status)
+	assert.Equal(t, []string{string(config.Physics)}, stored.Scope)
+	require.Len(t, stored.Files, 2)
+	assert.Equal(t, "/spool/0.csv", stored.Files[0].Path)
+	assert.Equal(t, int64(2000), stored.Files[1].RowsCommitted)
+
+	interrupted, err := imports.ListByStatus(config.ImportInterrupted)
+	require.NoError(t, err)
+	ids := []uuid.UUID{}
+	for _, job := range interrupted {
+		ids = append(ids, job.ID)
+	}
+	assert.Contains(t, ids, job.ID)
+
+	require.NoError(t, imports.Delete(job.ID))
+	_, err = imports.Get(job.ID)
+	assert.ErrorIs(t, err, config.ErrImportJobNotExist)
+	assert.ErrorIs(t, imports.SetStatus(job.ID, config.ImportFailed, "gone"), config.ErrImportJobNotExist)
+}
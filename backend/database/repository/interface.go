@@ -1,15 +1,122 @@
 package repository
 
 import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"time"
+
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-// Query option defines filter/sort/pagination actions
-type QueryOption func(*gorm.DB) *gorm.DB
-
 type StudentRepository[T any] interface {
 	Create(item *T) (uuid.UUID, error)
 	CreateMany(item []*T) error
 	Query(opts []QueryOption, paginationOpt QueryOption) ([]*T, int64, error)
+	Suggest(term string, limit int, opts ...QueryOption) ([]string, error)
+	DeleteAll() (int64, error)
+	ForTenant(tenantID uuid.UUID) StudentRepository[T]
+
+	// SkipDuplicates returns a copy whose CreateMany skips the items whose
+	// id is already stored instead of failing, to replay inserts that may
+	// have been stored
+	SkipDuplicates() StudentRepository[T]
+
+	// ForImport returns a copy recording importID on the rows it stores, so
+	// the upload can be rolled back
+	ForImport(importID uuid.UUID) StudentRepository[T]
+
+	// Delete removes the rows of the tenant matching opts and returns their
+	// ids
+	Delete(opts []QueryOption) ([]uuid.UUID, error)
+}
+
+type CourseRepository interface {
+	Create(course *model.Course) error
+	List() ([]*model.Course, error)
+	Get(name string) (*model.Course, error)
+	Update(name string, course *model.Course) error
+	Delete(name string) error
+	Exists(name string) (bool, error)
+	ForTenant(tenantID uuid.UUID) CourseRepository
+}
+
+// NestedQuery filters and paginates the per-student view of grade records
+type NestedQuery struct {
+	Name    string
+	Subject string
+	Page    int
+	Size    int
+
+	// Scope limits the view to these subjects unless nil
+	Scope []string
+}
+
+type GradeRepository interface {
+	Import(rows []*model.Student, term string) error
+	Backfill(term string) error
+	QueryNested(query NestedQuery) ([]*model.StudentProfile, int64, error)
+	Delete(ids []uuid.UUID) (int64, error)
+	DeleteAll() (int64, error)
+	ForTenant(tenantID uuid.UUID) GradeRepository
+}
+
+type AnalyticsRepository interface {
+	Percentiles(opts []QueryOption, minPercentile float64) ([]*model.StudentRank, error)
+	Top(n int, order config.SortOrder, opts []QueryOption) ([]*model.StudentRank, error)
+	CompareSubjects(opts []QueryOption) ([]*model.SubjectStats, error)
+	LetterDistribution(opts []QueryOption, scales map[string]*model.GradingScale) ([]*model.LetterCount, error)
+	ForTenant(tenantID uuid.UUID) AnalyticsRepository
+}
+
+type GradingScaleRepository interface {
+	Create(scale *model.GradingScale) error
+	List() ([]*model.GradingScale, error)
+	Get(name string) (*model.GradingScale, error)
+	Update(name string, scale *model.GradingScale) error
+	Delete(name string) error
+	ForCourses() (map[string]*model.GradingScale, error)
+	ForTenant(tenantID uuid.UUID) GradingScaleRepository
+}
+
+type TenantRepository interface {
+	Create(tenant *model.Tenant) error
+	List() ([]*model.Tenant, error)
+	GetByName(name string) (*model.Tenant, error)
+}
+
+// ImportJobRepository persists uploads across restarts, jobs keep their
+// tenant but are listed for all tenants when resuming
+type ImportJobRepository interface {
+	Create(job *model.ImportJob) error
+	Get(id uuid.UUID) (*model.ImportJob, error)
+	ListByStatus(status config.ImportStatus) ([]*model.ImportJob, error)
+	SetStatus(id uuid.UUID, status config.ImportStatus, message string) error
+	Checkpoint(id uuid.UUID, position int, rows int64) error
+	Dispatch(id uuid.UUID, position int, rows int64) error
+	Delete(id uuid.UUID) error
+}
+
+type UserRepository interface {
+	Create(user *model.User) error
+	Get(id uuid.UUID) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	SetRole(id uuid.UUID, role config.Role) error
+	SetAllSubjects(id uuid.UUID, all bool) error
+	Subjects(id uuid.UUID) ([]string, error)
+	SetSubjects(id uuid.UUID, subjects []string) error
+}
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	Get(id string) (*model.Session, error)
+	Delete(id string) error
+	DeleteExpired() (int64, error)
+}
+
+type APITokenRepository interface {
+	Create(token *model.APIToken) error
+	List(userID uuid.UUID) ([]*model.APIToken, error)
+	GetByHash(hash string) (*model.APIToken, error)
+	Touch(id uuid.UUID, at time.Time) error
+	Revoke(userID, id uuid.UUID) error
 }
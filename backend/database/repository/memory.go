@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"cmp"
+	"file-uploader/config"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStudentRepo keeps students in memory, it honors the same query
+// semantics as StudentRepo and is meant for tests and demos
+type MemoryStudentRepo[T any] struct {
+	*memoryStore[T]
+	tenant         uuid.UUID
+	skipDuplicates bool
+	importID       uuid.UUID
+}
+
+// memoryStore is shared by the repositories of every tenant
+type memoryStore[T any] struct {
+	mu    sync.RWMutex
+	items []T
+	ids   map[uuid.UUID]bool
+}
+
+func NewMemoryStudentRepository[T any]() StudentRepository[T] {
+	return &MemoryStudentRepo[T]{
+		memoryStore: &memoryStore[T]{ids: make(map[uuid.UUID]bool)},
+		tenant:      config.DefaultTenantID,
+	}
+}
+
+func (r *MemoryStudentRepo[T]) ForTenant(tenantID uuid.UUID) StudentRepository[T] {
+	copied := *r
+	copied.tenant = tenantOrDefault(tenantID)
+	return &copied
+}
+
+func (r *MemoryStudentRepo[T]) SkipDuplicates() StudentRepository[T] {
+	copied := *r
+	copied.skipDuplicates = true
+	return &copied
+}
+
+func (r *MemoryStudentRepo[T]) ForImport(importID uuid.UUID) StudentRepository[T] {
+	copied := *r
+	copied.importID = importID
+	return &copied
+}
+
+func (r *MemoryStudentRepo[T]) owns(item *T) bool {
+	return fieldOf(item, config.TenantId).Interface().(uuid.UUID) == r.tenant
+}
+
+func (r *MemoryStudentRepo[T]) Create(item *T) (uuid.UUID, error) {
+	studentId, err := validateStudent(item)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ids[studentId] {
+		return uuid.Nil, config.ErrDuplicateStudent
+	}
+
+	setTenant(item, r.tenant)
+	setImport(item, r.importID)
+	r.ids[studentId] = true
+	r.items = append(r.items, *item)
+	return studentId, nil
+}
+
+// CreateMany inserts all items or none of them, like a failed batch insert
+func (r *MemoryStudentRepo[T]) CreateMany(items []*T) error {
+	if len(items) == 0 {
+		return config.ErrMissingStudentData
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch := make(map[uuid.UUID]bool, len(items))
+	kept := make([]*T, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			return config.ErrMissingStudentData
+		}
+
+		// Database inserts generate missing ids
+		studentId := fieldOf(item, config.Id).Interface().(uuid.UUID)
+		if studentId == uuid.Nil {
+			studentId = uuid.New()
+			fieldOf(item, config.Id).Set(reflect.ValueOf(studentId))
+		}
+
+		if r.ids[studentId] || batch[studentId] {
+			if r.skipDuplicates {
+				continue
+			}
+			return config.ErrDuplicateStudent
+		}
+		batch[studentId] = true
+		kept = append(kept, item)
+	}
+
+	for _, item := range kept {
+		setTenant(item, r.tenant)
+		setImport(item, r.importID)
+		r.items = append(r.items, *item)
+	}
+	for studentId := range batch {
+		r.ids[studentId] = true
+	}
+	return nil
+}
+
+func (r *MemoryStudentRepo[T]) DeleteAll() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.items[:0]
+	var deleted int64
+	for i := range r.items {
+		if !r.owns(&r.items[i]) {
+			kept = append(kept, r.items[i])
+			continue
+		}
+		delete(r.ids, fieldOf(&r.items[i], config.Id).Interface().(uuid.UUID))
+		deleted++
+	}
+	r.items = kept
+	return deleted, nil
+}
+
+// Delete removes the rows of the tenant matching opts and returns their ids
+func (r *MemoryStudentRepo[T]) Delete(opts []QueryOption) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := BuildQuery(opts, nil)
+	kept := r.items[:0]
+	ids := []uuid.UUID{}
+	for i := range r.items {
+		if !r.owns(&r.items[i]) || !matchesFilters(&r.items[i], query) {
+			kept = append(kept, r.items[i])
+			continue
+		}
+		id := fieldOf(&r.items[i], config.Id).Interface().(uuid.UUID)
+		delete(r.ids, id)
+		ids = append(ids, id)
+	}
+	r.items = kept
+	return ids, nil
+}
+
+func (r *MemoryStudentRepo[T]) Query(
+	opts []QueryOption,
+	paginationOpt QueryOption,
+) ([]*T, int64, error) {
+
+	query := BuildQuery(opts, paginationOpt)
+
+	r.mu.RLock()
+	students := make([]*T, 0, len(r.items))
+	for i := range r.items {
+		if r.owns(&r.items[i]) && matchesFilters(&r.items[i], query) {
+			// Copy so callers can't mutate the store
+			student := r.items[i]
+			students = append(students, &student)
+		}
+	}
+	r.mu.RUnlock()
+
+	totalCount := int64(len(students))
+
+	// Searches are ordered by relevance unless sorted explicitly
+	if query.Search != nil && len(query.Sorts) == 0 {
+		slices.SortStableFunc(students, func(a, b *T) int {
+			return compareRelevance(a, b, *query.Search)
+		})
+	}
+
+	if sorts := query.OrderedSorts(); len(sorts) > 0 {
+		slices.SortStableFunc(students, func(a, b *T) int {
+			return compareStudents(a, b, sorts)
+		})
+	}
+
+	// Apply pagination
+	if query.Paginated() {
+		start := min((query.Page-1)*query.Size, len(students))
+		end := min(start+query.Size, len(students))
+		students = students[start:end]
+	}
+
+	return students, totalCount, nil
+}
+
+func matchesFilters[T any](item *T, query Query) bool {
+	if query.NamePrefix != "" {
+		name := fieldOf(item, config.Name).String()
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(query.NamePrefix)) {
+			return false
+		}
+	}
+
+	if query.Subject != "" && fieldOf(item, config.Subject).String() != string(query.Subject) {
+		return false
+	}
+
+	for _, condition := range query.Conditions {
+		if !matchesCondition(item, condition) {
+			return false
+		}
+	}
+
+	if query.Search != nil && !matchesSearch(fieldOf(item, config.Name).String(), *query.Search) {
+		return false
+	}
+
+	return true
+}
+
+func compareRelevance[T any](a, b *T, search Search) int {
+	rankA, similarityA := searchRank(fieldOf(a, config.Name).String(), search)
+	rankB, similarityB := searchRank(fieldOf(b, config.Name).String(), search)
+
+	if result := cmp.Compare(rankB, rankA); result != 0 {
+		return result
+	}
+	if result := cmp.Compare(similarityB, similarityA); result != 0 {
+		return result
+	}
+	return compareValues(fieldOf(a, config.Id), fieldOf(b, config.Id))
+}
+
+// Suggest returns distinct names matching a fuzzy search, most relevant
+// first, among the students matching opts
+func (r *MemoryStudentRepo[T]) Suggest(term string, limit int, opts ...QueryOption) ([]string, error) {
+	term = strings.TrimSpace(term)
+	if term == "" || limit <= 0 {
+		return []string{}, nil
+	}
+
+	search := Search{Term: term, Mode: config.SearchFuzzy}
+	query := BuildQuery(opts, nil)
+
+	r.mu.RLock()
+	seen := make(map[string]bool)
+	names := []string{}
+	for i := range r.items {
+		name := fieldOf(&r.items[i], config.Name).String()
+		if !seen[name] && r.owns(&r.items[i]) && matchesSearch(name, search) && matchesFilters(&r.items[i], query) {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(names, func(a, b string) int {
+		rankA, similarityA := searchRank(a, search)
+		rankB, similarityB := searchRank(b, search)
+		if result := cmp.Compare(rankB, rankA); result != 0 {
+			return result
+		}
+		if result := cmp.Compare(similarityB, similarityA); result != 0 {
+			return result
+		}
+		return cmp.Compare(a, b)
+	})
+
+	return names[:min(limit, len(names))], nil
+}
+
+func compareStudents[T any](a, b *T, sorts []Sort) int {
+	for _, sort := range sorts {
+		result := compareValues(fieldOf(a, sort.Column), fieldOf(b, sort.Column))
+		if sort.Order == config.SortDesc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b reflect.Value) int {
+	// Nullable columns sort first, like NULLS FIRST
+	if a.Kind() == reflect.Pointer {
+		if a.IsNil() {
+			return -1
+		}
+		a = a.Elem()
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	default:
+		// uuids compare like their text representation in the database
+		return cmp.Compare(a.Interface().(uuid.UUID).String(), b.Interface().(uuid.UUID).String())
+	}
+}
+
+func fieldOf[T any](item *T, col config.StudentCol) reflect.Value {
+	return reflect.ValueOf(item).Elem().FieldByName(string(col))
+}
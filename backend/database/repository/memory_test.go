@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryRepositoryParity runs the same queries against the in-memory and
+// the database repositories and expects the same results
+func TestMemoryRepositoryParity(t *testing.T) {
+	setupTestData(t)
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	stored, _, err := studentRepo.Query(nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, memoryRepo.CreateMany(stored))
+
+	cases := []struct {
+		name       string
+		opts       []repository.QueryOption
+		pagination repository.QueryOption
+		checkOrder bool
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name: "name prefix is case insensitive",
+			opts: []repository.QueryOption{repository.WithNameFilter("student0")},
+		},
+		{
+			name: "subject filter",
+			opts: []repository.QueryOption{repository.WithSubject(config.Physics)},
+		},
+		{
+			name:       "sort by grade desc with pagination",
+			opts:       []repository.QueryOption{repository.WithSort(config.Grade, config.SortDesc)},
+			pagination: repository.WithPagination(2, 3),
+			checkOrder: true,
+		},
+		{
+			name:       "sort by subject asc",
+			opts:       []repository.QueryOption{repository.WithSort(config.Subject, config.SortAsc)},
+			checkOrder: true,
+		},
+		{
+			name:       "page past the end",
+			opts:       []repository.QueryOption{repository.WithSort(config.Name, config.SortAsc)},
+			pagination: repository.WithPagination(5, 3),
+			checkOrder: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, expectedCount, err := studentRepo.Query(tt.opts, tt.pagination)
+			require.NoError(t, err)
+
+			actual, actualCount, err := memoryRepo.Query(tt.opts, tt.pagination)
+			require.NoError(t, err)
+
+			assert.Equal(t, expectedCount, actualCount)
+			if tt.checkOrder {
+				assert.Equal(t, expected, actual)
+			} else {
+				assert.ElementsMatch(t, expected, actual)
+			}
+		})
+	}
+}
+
+func TestMemoryRepository(t *testing.T) {
+	t.Run("invalid students data, should return error", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+
+		_, err := memoryRepo.Create(&model.StudentTest{Student_name: "incomplete data"})
+		assert.Equal(t, config.ErrMissingStudentData, err)
+
+		err = memoryRepo.CreateMany(nil)
+		assert.Equal(t, config.ErrMissingStudentData, err)
+	})
+
+	t.Run("duplicate ids reject the whole batch", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+		id := uuid.New()
+
+		err := memoryRepo.CreateMany([]*model.StudentTest{
+			{Student_id: uuid.New(), Student_name: "Ali", Subject: string(config.Art), Grade: 10},
+			{Student_id: id, Student_name: "Omar", Subject: string(config.Art), Grade: 20},
+			{Student_id: id, Student_name: "Saad", Subject: string(config.Art), Grade: 30},
+		})
+		assert.Equal(t, config.ErrDuplicateStudent, err)
+
+		_, count, err := memoryRepo.Query(nil, nil)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("concurrent inserts", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := memoryRepo.Create(&model.StudentTest{Student_name: "Ali", Subject: string(config.Art), Grade: 10})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		_, count, err := memoryRepo.Query(nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), count)
+	})
+}
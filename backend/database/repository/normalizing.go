@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"file-uploader/database/model"
+
+	"github.com/google/uuid"
+)
+
+// NormalizingRepo writes flat student rows and mirrors them into the
+// normalized grade records. The two writes are not atomic, a failed import
+// can be repaired with GradeRepository.Backfill since imports are idempotent
+type NormalizingRepo struct {
+	StudentRepository[model.Student]
+	grades GradeRepository
+	term   string
+}
+
+func NewNormalizingRepository(
+	flat StudentRepository[model.Student],
+	grades GradeRepository,
+	term string,
+) StudentRepository[model.Student] {
+	return &NormalizingRepo{StudentRepository: flat, grades: grades, term: term}
+}
+
+func (r *NormalizingRepo) ForTenant(tenantID uuid.UUID) StudentRepository[model.Student] {
+	return NewNormalizingRepository(r.StudentRepository.ForTenant(tenantID), r.grades.ForTenant(tenantID), r.term)
+}
+
+// SkipDuplicates skips the flat rows already stored, their grade records are
+// skipped by Import anyway
+func (r *NormalizingRepo) SkipDuplicates() StudentRepository[model.Student] {
+	return NewNormalizingRepository(r.StudentRepository.SkipDuplicates(), r.grades, r.term)
+}
+
+func (r *NormalizingRepo) ForImport(importID uuid.UUID) StudentRepository[model.Student] {
+	return NewNormalizingRepository(r.StudentRepository.ForImport(importID), r.grades, r.term)
+}
+
+func (r *NormalizingRepo) Create(item *model.Student) (uuid.UUID, error) {
+	id, err := r.StudentRepository.Create(item)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, r.grades.Import([]*model.Student{item}, r.term)
+}
+
+func (r *NormalizingRepo) CreateMany(items []*model.Student) error {
+	if err := r.StudentRepository.CreateMany(items); err != nil {
+		return err
+	}
+
+	return r.grades.Import(items, r.term)
+}
+
+// DeleteAll removes the flat rows and the grade records of the tenant, it
+// returns the number of flat rows
+func (r *NormalizingRepo) DeleteAll() (int64, error) {
+	deleted, err := r.StudentRepository.DeleteAll()
+	if err != nil {
+		return deleted, err
+	}
+
+	_, err = r.grades.DeleteAll()
+	return deleted, err
+}
+
+// Delete removes the flat rows matching opts and their grade records, it
+// returns the ids of the flat rows
+func (r *NormalizingRepo) Delete(opts []QueryOption) ([]uuid.UUID, error) {
+	ids, err := r.StudentRepository.Delete(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.grades.Delete(ids)
+	return ids, err
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"file-uploader/config"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Query is a backend neutral description of filters, sorts and pagination,
+// each repository implementation translates it to its own storage
+type Query struct {
+	NamePrefix string
+	Subject    config.Course
+	Conditions []Condition
+	Search     *Search
+	Sorts      []Sort
+	Page       int
+	Size       int
+}
+
+type Sort struct {
+	Column config.StudentCol
+	Order  config.SortOrder
+}
+
+// Query option defines filter/sort/pagination actions
+type QueryOption func(*Query)
+
+// BuildQuery applies options in order, the pagination option last
+func BuildQuery(opts []QueryOption, paginationOpt QueryOption) Query {
+	var query Query
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&query)
+		}
+	}
+	if paginationOpt != nil {
+		paginationOpt(&query)
+	}
+	return query
+}
+
+func (q Query) Paginated() bool {
+	return q.Page > 0 && q.Size > 0
+}
+
+// OrderedSorts returns the sorts followed by Student_id, a unique tiebreaker
+// that keeps pages stable when sorted values repeat
+func (q Query) OrderedSorts() []Sort {
+	if len(q.Sorts) == 0 {
+		return nil
+	}
+
+	sorts := make([]Sort, 0, len(q.Sorts)+1)
+	for _, sort := range q.Sorts {
+		if sort.Column == config.Id {
+			return append(sorts, sort)
+		}
+		sorts = append(sorts, sort)
+	}
+	return append(sorts, Sort{Column: config.Id, Order: config.SortAsc})
+}
+
+func WithSubject(subject config.Course) QueryOption {
+	return func(q *Query) {
+		if subject != "" {
+			q.Subject = subject
+		}
+	}
+}
+
+// WithSubjectScope limits results to the subjects a user is assigned, nil
+// leaves results unscoped and no subjects matches nothing
+func WithSubjectScope(subjects []string) QueryOption {
+	return func(q *Query) {
+		if subjects == nil {
+			return
+		}
+		values := make([]any, len(subjects))
+		for i, subject := range subjects {
+			values[i] = subject
+		}
+		q.Conditions = append(q.Conditions, Condition{Column: config.Subject, Op: OpIn, Values: values})
+	}
+}
+
+// WithId matches the row of a student id
+func WithId(id uuid.UUID) QueryOption {
+	return func(q *Query) {
+		q.Conditions = append(q.Conditions, Condition{Column: config.Id, Op: OpEq, Values: []any{id}})
+	}
+}
+
+// WithImport matches the rows stored by an upload
+func WithImport(importID uuid.UUID) QueryOption {
+	return func(q *Query) {
+		q.Conditions = append(q.Conditions, Condition{Column: config.ImportId, Op: OpEq, Values: []any{importID}})
+	}
+}
+
+func WithSort(sortedBy config.StudentCol, order config.SortOrder) QueryOption {
+	return func(q *Query) {
+		if sortedBy != "" {
+			q.Sorts = append(q.Sorts, Sort{Column: sortedBy, Order: order})
+		}
+	}
+}
+
+// WithSorts sorts by several columns, in order of precedence
+func WithSorts(sorts ...Sort) QueryOption {
+	return func(q *Query) {
+		for _, sort := range sorts {
+			if sort.Column != "" {
+				q.Sorts = append(q.Sorts, sort)
+			}
+		}
+	}
+}
+
+func WithPagination(page, size int) QueryOption {
+	return func(q *Query) {
+		if page > 0 && size > 0 {
+			q.Page = page
+			q.Size = size
+		}
+	}
+}
+
+func WithNameFilter(name string) QueryOption {
+	return func(q *Query) {
+		if name != "" {
+			q.NamePrefix = name
+		}
+	}
+}
+
+// validateStudent checks the required fields of a student and sets its id
+// when missing, it is shared by every repository implementation
+func validateStudent[T any](item *T) (uuid.UUID, error) {
+	if item == nil {
+		return uuid.Nil, config.ErrMissingStudentData
+	}
+
+	// Validate data
+	value := reflect.ValueOf(item).Elem()
+
+	// Get name field and check if it's empty (AI)
+	nameField := value.FieldByName(string(config.Name))
+	if !nameField.IsValid() || nameField.String() == "" {
+		return uuid.Nil, config.ErrMissingStudentData
+	}
+
+	// Get subject field and check if it's empty (AI)
+	subjectField := value.FieldByName(string(config.Subject))
+	if !subjectField.IsValid() || subjectField.String() == "" {
+		return uuid.Nil, config.ErrMissingStudentData
+	}
+
+	// Get grade field and check if it's zero (AI)
+	gradeField := value.FieldByName(string(config.Grade))
+	if !gradeField.IsValid() || gradeField.Uint() == 0 {
+		return uuid.Nil, config.ErrMissingStudentData
+	}
+
+	// Get id field and check if it's nil
+	idField := value.FieldByName(string(config.Id))
+	var studentId uuid.UUID
+
+	if !idField.IsValid() || idField.Interface() == uuid.Nil {
+		studentId = uuid.New()
+
+		// Make sure the field is settable
+		if idField.IsValid() && idField.CanSet() {
+			idField.Set(reflect.ValueOf(studentId))
+		} else {
+			return uuid.Nil, fmt.Errorf("can't set ID field")
+		}
+	} else {
+		studentId = idField.Interface().(uuid.UUID)
+	}
+
+	return studentId, nil
+}
+
+// setTenant assigns a row to the tenant of the repository writing it
+func setTenant[T any](item *T, tenantID uuid.UUID) {
+	if item == nil {
+		return
+	}
+	if field := fieldOf(item, config.TenantId); field.IsValid() {
+		field.Set(reflect.ValueOf(tenantID))
+	}
+}
+
+// setImport records the upload storing a row, rows stored otherwise keep no
+// upload
+func setImport[T any](item *T, importID uuid.UUID) {
+	if item == nil || importID == uuid.Nil {
+		return
+	}
+	if field := fieldOf(item, config.ImportId); field.IsValid() {
+		field.Set(reflect.ValueOf(&importID))
+	}
+}
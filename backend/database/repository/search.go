@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"file-uploader/config"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FuzzyThreshold matches the default pg_trgm.word_similarity_threshold
+const FuzzyThreshold = 0.6
+
+// Search matches student names and ranks results by relevance:
+//   - substring: the term appears anywhere in the name
+//   - token: every word of the term starts a word of the name
+//   - fuzzy: substring matches and names with a similar word (trigrams), tolerating typos
+type Search struct {
+	Term string
+	Mode config.SearchMode
+}
+
+func WithSearch(term string, mode config.SearchMode) QueryOption {
+	return func(q *Query) {
+		term = strings.TrimSpace(term)
+		if term != "" {
+			q.Search = &Search{Term: term, Mode: mode}
+		}
+	}
+}
+
+// searchSQL translates a search to a parameterized condition and a relevance ordering
+func searchSQL(db *gorm.DB, search Search) (string, []any, clause.Expr) {
+	column := string(config.Name)
+	escaped := escapeLike(search.Term)
+
+	var where string
+	var args []any
+
+	switch search.Mode {
+	case config.SearchToken:
+		var parts []string
+		for _, token := range strings.Fields(escaped) {
+			like := likeEscaped(db, column)
+			parts = append(parts, "("+like+" OR "+like+")")
+			args = append(args, token+"%", "% "+token+"%")
+		}
+		where = strings.Join(parts, " AND ")
+	case config.SearchFuzzy:
+		where = "(" + likeEscaped(db, column) + " OR " + similarSQL(db, column) + ")"
+		args = []any{"%" + escaped + "%", search.Term}
+	default:
+		where = likeEscaped(db, column)
+		args = []any{"%" + escaped + "%"}
+	}
+
+	// Exact matches first, then prefixes, then word prefixes
+	rank := clause.Expr{
+		SQL: "CASE WHEN LOWER(" + column + ") = LOWER(?) THEN 3 " +
+			"WHEN LOWER(" + column + ") LIKE LOWER(?) ESCAPE '\\' THEN 2 " +
+			"WHEN LOWER(" + column + ") LIKE LOWER(?) ESCAPE '\\' THEN 1 ELSE 0 END DESC",
+		Vars: []any{search.Term, escaped + "%", "% " + escaped + "%"},
+	}
+	if search.Mode == config.SearchFuzzy {
+		rank.SQL += ", word_similarity(?, " + column + ") DESC"
+		rank.Vars = append(rank.Vars, search.Term)
+	}
+
+	return where, args, rank
+}
+
+// similarSQL uses the pg_trgm operator on Postgres so the GIN index applies,
+// other dialects call the word_similarity function registered on the connection
+func similarSQL(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == string(config.DriverPostgres) {
+		return "? <% " + column
+	}
+	return "word_similarity(?, " + column + ") >= 0.6"
+}
+
+// matchesSearch evaluates a search in Go
+func matchesSearch(name string, search Search) bool {
+	lowerName := strings.ToLower(name)
+	term := strings.ToLower(search.Term)
+
+	switch search.Mode {
+	case config.SearchToken:
+		words := strings.Fields(lowerName)
+		for _, token := range strings.Fields(term) {
+			if !slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, token) }) &&
+				!strings.HasPrefix(lowerName, token) {
+				return false
+			}
+		}
+		return true
+	case config.SearchFuzzy:
+		return strings.Contains(lowerName, term) || WordSimilarity(search.Term, name) >= FuzzyThreshold
+	default:
+		return strings.Contains(lowerName, term)
+	}
+}
+
+// searchRank mirrors the relevance ordering of searchSQL, higher first
+func searchRank(name string, search Search) (int, float64) {
+	lowerName := strings.ToLower(name)
+	term := strings.ToLower(search.Term)
+
+	rank := 0
+	switch {
+	case lowerName == term:
+		rank = 3
+	case strings.HasPrefix(lowerName, term):
+		rank = 2
+	case strings.Contains(lowerName, " "+term):
+		rank = 1
+	}
+
+	similarity := 0.0
+	if search.Mode == config.SearchFuzzy {
+		similarity = WordSimilarity(search.Term, name)
+	}
+	return rank, similarity
+}
+
+// WordSimilarity follows pg_trgm's word_similarity: the greatest trigram
+// similarity between a and any continuous extent of the trigrams of b
+func WordSimilarity(a, b string) float64 {
+	target := trigramSet(trigrams(a))
+	if len(target) == 0 {
+		return 0
+	}
+
+	sequence := trigrams(b)
+	best := 0.0
+	for start := range sequence {
+		shared := 0
+		extra := 0
+		seen := make(map[string]bool)
+		for end := start; end < len(sequence); end++ {
+			trigram := sequence[end]
+			if !seen[trigram] {
+				seen[trigram] = true
+				if target[trigram] {
+					shared++
+				} else {
+					extra++
+				}
+			}
+
+			similarity := float64(shared) / float64(len(target)+extra)
+			best = max(best, similarity)
+		}
+	}
+	return best
+}
+
+// trigrams returns the ordered trigrams of every word, padded like pg_trgm
+func trigrams(s string) []string {
+	var result []string
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result = append(result, string(padded[i:i+3]))
+		}
+	}
+	return result
+}
+
+func trigramSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, trigram := range list {
+		set[trigram] = true
+	}
+	return set
+}
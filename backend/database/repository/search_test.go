@@ -0,0 +1,120 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordSimilarity(t *testing.T) {
+	// Reference value from the pg_trgm documentation
+	assert.InDelta(t, 0.8, repository.WordSimilarity("word", "two words"), 0.001)
+
+	assert.GreaterOrEqual(t, repository.WordSimilarity("hutch", "James Hutchinson"), repository.FuzzyThreshold)
+	assert.GreaterOrEqual(t, repository.WordSimilarity("Hutchnson", "James Hutchinson"), repository.FuzzyThreshold)
+	assert.Less(t, repository.WordSimilarity("omar", "James Hutchinson"), repository.FuzzyThreshold)
+	assert.Zero(t, repository.WordSimilarity("", "James Hutchinson"))
+}
+
+func TestSearch(t *testing.T) {
+	// Clear any previous test data
+	testDB.Where("1=1").Delete(&model.StudentTest{})
+
+	testData := []*model.StudentTest{
+		{Student_name: "James Hutchinson", Subject: string(config.Art), Grade: 70},
+		{Student_name: "Hutch", Subject: string(config.Art), Grade: 80},
+		{Student_name: "Mary Hutchins", Subject: string(config.Music), Grade: 90},
+		{Student_name: "Omar Saeed", Subject: string(config.Physics), Grade: 60},
+		{Student_name: "Saeed Omar", Subject: string(config.Physics), Grade: 65},
+	}
+	require.NoError(t, studentRepo.CreateMany(testData))
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	require.NoError(t, memoryRepo.CreateMany(testData))
+
+	cases := []struct {
+		name          string
+		term          string
+		mode          config.SearchMode
+		expectedNames []string
+		tied          bool
+	}{
+		{
+			name:          "substring ranks exact and prefix matches first",
+			term:          "hutch",
+			mode:          config.SearchSubstring,
+			expectedNames: []string{"Hutch", "James Hutchinson", "Mary Hutchins"},
+		},
+		{
+			name:          "substring matches inside words",
+			term:          "chin",
+			mode:          config.SearchSubstring,
+			expectedNames: []string{"James Hutchinson", "Mary Hutchins"},
+			tied:          true,
+		},
+		{
+			name:          "token matches word prefixes in any order",
+			term:          "sae om",
+			mode:          config.SearchToken,
+			expectedNames: []string{"Omar Saeed", "Saeed Omar"},
+			tied:          true,
+		},
+		{
+			name:          "token doesn't match inside words",
+			term:          "chin",
+			mode:          config.SearchToken,
+			expectedNames: []string{},
+		},
+		{
+			name:          "fuzzy tolerates typos",
+			term:          "Hutchnson",
+			mode:          config.SearchFuzzy,
+			expectedNames: []string{"James Hutchinson"},
+		},
+	}
+
+	repos := map[string]repository.StudentRepository[model.StudentTest]{
+		"database": studentRepo,
+		"memory":   memoryRepo,
+	}
+
+	for repoName, repo := range repos {
+		for _, tt := range cases {
+			t.Run(repoName+" "+tt.name, func(t *testing.T) {
+				students, count, err := repo.Query(
+					[]repository.QueryOption{repository.WithSearch(tt.term, tt.mode)},
+					nil,
+				)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(tt.expectedNames)), count)
+
+				names := make([]string, len(students))
+				for i, student := range students {
+					names[i] = student.Student_name
+				}
+
+				// Ties in relevance are ordered by id, only check the ranked prefix
+				if tt.tied || len(tt.expectedNames) == 0 {
+					assert.ElementsMatch(t, tt.expectedNames, names)
+				} else {
+					assert.Equal(t, tt.expectedNames[0], names[0])
+					assert.ElementsMatch(t, tt.expectedNames, names)
+				}
+			})
+		}
+
+		t.Run(repoName+" suggest", func(t *testing.T) {
+			names, err := repo.Suggest("hutch", 2)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Hutch", "James Hutchinson"}, names)
+
+			names, err = repo.Suggest("  ", 10)
+			require.NoError(t, err)
+			assert.Empty(t, names)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSort(t *testing.T) {
+	// Clear any previous test data
+	testDB.Where("1=1").Delete(&model.StudentTest{})
+
+	ids := make([]uuid.UUID, 6)
+	for i := range ids {
+		// Ordered ids make the tiebreaker predictable
+		ids[i] = uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1))
+	}
+
+	testData := []*model.StudentTest{
+		{Student_id: ids[5], Student_name: "Omar", Subject: string(config.Physics), Grade: 80},
+		{Student_id: ids[4], Student_name: "Ali", Subject: string(config.Physics), Grade: 80},
+		{Student_id: ids[3], Student_name: "Saad", Subject: string(config.Art), Grade: 70},
+		{Student_id: ids[2], Student_name: "Ali", Subject: string(config.Art), Grade: 90},
+		{Student_id: ids[1], Student_name: "Ali", Subject: string(config.Physics), Grade: 80},
+		{Student_id: ids[0], Student_name: "Ahmed", Subject: string(config.Physics), Grade: 95},
+	}
+	require.NoError(t, studentRepo.CreateMany(testData))
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	require.NoError(t, memoryRepo.CreateMany(testData))
+
+	cases := []struct {
+		name        string
+		sorts       []repository.Sort
+		expectedIds []uuid.UUID
+	}{
+		{
+			name: "subject asc, grade desc, name asc with id tiebreaker",
+			sorts: []repository.Sort{
+				{Column: config.Subject, Order: config.SortAsc},
+				{Column: config.Grade, Order: config.SortDesc},
+				{Column: config.Name, Order: config.SortAsc},
+			},
+			expectedIds: []uuid.UUID{ids[2], ids[3], ids[0], ids[1], ids[4], ids[5]},
+		},
+		{
+			name:        "ties are broken by id",
+			sorts:       []repository.Sort{{Column: config.Grade, Order: config.SortDesc}},
+			expectedIds: []uuid.UUID{ids[0], ids[2], ids[1], ids[4], ids[5], ids[3]},
+		},
+	}
+
+	repos := map[string]repository.StudentRepository[model.StudentTest]{
+		"database": studentRepo,
+		"memory":   memoryRepo,
+	}
+
+	for repoName, repo := range repos {
+		for _, tt := range cases {
+			t.Run(repoName+" "+tt.name, func(t *testing.T) {
+				students, _, err := repo.Query([]repository.QueryOption{repository.WithSorts(tt.sorts...)}, nil)
+				require.NoError(t, err)
+
+				actualIds := make([]uuid.UUID, len(students))
+				for i, student := range students {
+					actualIds[i] = student.Student_id
+				}
+				assert.Equal(t, tt.expectedIds, actualIds)
+			})
+		}
+	}
+}
@@ -3,64 +3,136 @@ package repository
 import (
 	"file-uploader/config"
 	"fmt"
-	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// StudentRepo reads and writes the rows of a single tenant, the default
+// tenant unless scoped with ForTenant
 type StudentRepo[T any] struct {
-	db *gorm.DB
+	db      *gorm.DB
+	tenant  uuid.UUID
+	inserts insertSettings
+
+	// skipDuplicates inserts with ON CONFLICT DO NOTHING
+	skipDuplicates bool
+
+	// importID is recorded on the rows stored, unless nil
+	importID uuid.UUID
 }
 
-func NewStudentRepository[T any](db *gorm.DB) StudentRepository[T] {
-	return &StudentRepo[T]{db: db}
+// insertSettings split CreateMany across workers inserting batches of rows
+// per statement, unless method copies the rows. The batch size starts at
+// batchSize and adapts to the latency target when set
+type insertSettings struct {
+	batchSize int
+	workers   int
+	method    config.InsertMethod
+	target    time.Duration
+	sizer     *batchSizer
+
+	// slots are taken by every insert statement, copies of the repository
+	// share them so concurrent calls stay within the pool
+	slots *insertSlots
+
+	// observe is told about every insert statement
+	observe func(rows int, took time.Duration)
 }
 
-func (r *StudentRepo[T]) Create(item *T) (uuid.UUID, error) {
-	if item == nil {
-		return uuid.Nil, config.ErrMissingStudentData
+// StudentRepoOption tunes a student repository
+type StudentRepoOption func(*insertSettings)
+
+// WithInsertBatches sets the rows per insert statement and the number of
+// insert statements running at once across every CreateMany of the
+// repository, values below 1 keep the default. Workers are derived from the
+// connection pool by default and never exceed it
+func WithInsertBatches(batchSize, workers int) StudentRepoOption {
+	return func(s *insertSettings) {
+		if batchSize > 0 {
+			s.batchSize = batchSize
+		}
+		if workers > 0 {
+			s.workers = workers
+		}
 	}
+}
 
-	// Validate data
-	value := reflect.ValueOf(item).Elem()
+// WithInsertLatency resizes the batches so a statement takes about target,
+// zero keeps the batch size fixed
+func WithInsertLatency(target time.Duration) StudentRepoOption {
+	return func(s *insertSettings) {
+		s.target = max(target, 0)
+	}
+}
 
-	// Get name field and check if it's empty (AI)
-	nameField := value.FieldByName(string(config.Name))
-	if !nameField.IsValid() || nameField.String() == "" {
-		return uuid.Nil, config.ErrMissingStudentData
+// WithInsertMethod picks how CreateMany inserts, InsertCopy falls back to
+// batches on databases other than postgres
+func WithInsertMethod(method config.InsertMethod) StudentRepoOption {
+	return func(s *insertSettings) {
+		if method != "" {
+			s.method = method
+		}
 	}
+}
 
-	// Get subject field and check if it's empty (AI)
-	subjectField := value.FieldByName(string(config.Subject))
-	if !subjectField.IsValid() || subjectField.String() == "" {
-		return uuid.Nil, config.ErrMissingStudentData
+// WithInsertObserver calls observe after every insert statement of
+// CreateMany, with its rows and duration
+func WithInsertObserver(observe func(rows int, took time.Duration)) StudentRepoOption {
+	return func(s *insertSettings) {
+		if observe != nil {
+			s.observe = observe
+		}
 	}
+}
 
-	// Get grade field and check if it's zero (AI)
-	gradeField := value.FieldByName(string(config.Grade))
-	if !gradeField.IsValid() || gradeField.Uint() == 0 {
-		return uuid.Nil, config.ErrMissingStudentData
+func NewStudentRepository[T any](db *gorm.DB, opts ...StudentRepoOption) StudentRepository[T] {
+	inserts := insertSettings{batchSize: 500, method: config.InsertBatches, observe: func(int, time.Duration) {}}
+	for _, opt := range opts {
+		opt(&inserts)
 	}
+	inserts.slots = newInsertSlots(db, inserts.workers)
+	inserts.sizer = newBatchSizer(inserts.batchSize, inserts.target)
+	return &StudentRepo[T]{db: db, tenant: config.DefaultTenantID, inserts: inserts}
+}
 
-	// Get id field and check if it's nil
-	idField := value.FieldByName(string(config.Id))
-	var studentId uuid.UUID
+func (r *StudentRepo[T]) ForTenant(tenantID uuid.UUID) StudentRepository[T] {
+	copied := *r
+	copied.tenant = tenantOrDefault(tenantID)
+	return &copied
+}
 
-	if !idField.IsValid() || idField.Interface() == uuid.Nil {
-		studentId = uuid.New()
+func (r *StudentRepo[T]) SkipDuplicates() StudentRepository[T] {
+	copied := *r
+	copied.skipDuplicates = true
+	return &copied
+}
 
-		// Make sure the field is settable
-		if idField.IsValid() && idField.CanSet() {
-			idField.Set(reflect.ValueOf(studentId))
-		} else {
-			return uuid.Nil, fmt.Errorf("can't set ID field")
-		}
-	} else {
-		studentId = idField.Interface().(uuid.UUID)
+func (r *StudentRepo[T]) ForImport(importID uuid.UUID) StudentRepository[T] {
+	copied := *r
+	copied.importID = importID
+	return &copied
+}
+
+// model starts every query on the rows of the tenant
+func (r *StudentRepo[T]) model() *gorm.DB {
+	return r.db.Model(new(T)).Where(fmt.Sprintf("%s = ?", config.TenantId), r.tenant)
+}
+
+func (r *StudentRepo[T]) Create(item *T) (uuid.UUID, error) {
+	studentId, err := validateStudent(item)
+	if err != nil {
+		return uuid.Nil, err
 	}
 
+	setTenant(item, r.tenant)
+	setImport(item, r.importID)
+
 	// Create student record
 	result := r.db.Create(item)
 	if result.Error != nil {
@@ -71,49 +143,76 @@ func (r *StudentRepo[T]) Create(item *T) (uuid.UUID, error) {
 }
 
 func (r *StudentRepo[T]) CreateMany(items []*T) error {
-	const (
-		batchSize     = 500
-		maxConcurrent = 10
-	)
-
 	if len(items) == 0 {
 		return config.ErrMissingStudentData
 	}
 
-	// Calculate partition size for each worker
-	partitionSize := (len(items) + maxConcurrent - 1) / maxConcurrent
+	for _, item := range items {
+		setTenant(item, r.tenant)
+		setImport(item, r.importID)
+	}
+
+	if r.inserts.method == config.InsertCopy && r.db.Dialector.Name() == string(config.DriverPostgres) {
+		r.inserts.slots.acquire()
+		defer r.inserts.slots.release()
+
+		start := time.Now()
+		if err := r.copyMany(items, r.skipDuplicates); err != nil {
+			return err
+		}
+		r.inserts.observe(len(items), time.Since(start))
+		return nil
+	}
+
+	// Workers take the next batch until the items run out or one fails,
+	// the size of each batch is read as it is taken. Each statement waits
+	// for a slot of the repository
+	workers := insertWorkers(r.inserts.slots, len(items), r.inserts.sizer.size())
+	r.inserts.sizer.started(workers)
 
 	var wg sync.WaitGroup
 	mu := sync.Mutex{}
 	var errors []error
+	next := 0
+
+	take := func() []*T {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(items) || len(errors) > 0 {
+			return nil
+		}
+		end := min(next+r.inserts.sizer.size(), len(items))
+		batch := items[next:end]
+		next = end
+		return batch
+	}
 
-	// Assign paritions to workers
-	for i := range maxConcurrent {
+	for i := range workers {
 		wg.Add(1)
 
 		go func(workerID int) {
 			defer wg.Done()
 
-			// Calculate parition slice indices
-			start := workerID * partitionSize
-			if start >= len(items) {
-				return
+			for batch := take(); batch != nil; batch = take() {
+				db := r.db
+				if r.skipDuplicates {
+					db = db.Clauses(clause.OnConflict{DoNothing: true})
+				}
+
+				r.inserts.slots.acquire()
+				start := time.Now()
+				if err := db.Create(batch).Error; err != nil {
+					r.inserts.slots.release()
+					mu.Lock()
+					errors = append(errors, fmt.Errorf("worker %d batch error: %w", workerID, err))
+					mu.Unlock()
+					return
+				}
+				took := time.Since(start)
+				r.inserts.sizer.observe(len(batch), took)
+				r.inserts.observe(len(batch), took)
+				r.inserts.slots.release()
 			}
-
-			end := min(start+partitionSize, len(items))
-
-			parition := items[start:end]
-
-			// Process partition in batches
-			result := r.db.CreateInBatches(parition, batchSize)
-
-			if result.Error != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("worker %d batch error: %w", workerID, result.Error))
-				mu.Unlock()
-				return
-			}
-
 		}(i)
 	}
 
@@ -124,25 +223,72 @@ func (r *StudentRepo[T]) CreateMany(items []*T) error {
 	return nil
 }
 
+// InsertStats reports the batches inserted by the repository and its tenants
+func (r *StudentRepo[T]) InsertStats() InsertStats {
+	stats := r.inserts.sizer.snapshot()
+	stats.Slots = r.inserts.slots.size()
+	return stats
+}
+
+// DeleteAll removes every student of the tenant and returns how many
+func (r *StudentRepo[T]) DeleteAll() (int64, error) {
+	result := r.db.Where(fmt.Sprintf("%s = ?", config.TenantId), r.tenant).Delete(new(T))
+	return result.RowsAffected, result.Error
+}
+
+// Delete removes the rows of the tenant matching opts and returns their ids,
+// the ids are deleted in chunks below the bind parameter limits
+func (r *StudentRepo[T]) Delete(opts []QueryOption) ([]uuid.UUID, error) {
+	const chunkSize = 1000
+
+	ids := []uuid.UUID{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		scoped := *r
+		scoped.db = tx
+		if err := applyFilters(scoped.model(), BuildQuery(opts, nil)).Pluck(string(config.Id), &ids).Error; err != nil {
+			return err
+		}
+
+		for chunk := range slices.Chunk(ids, chunkSize) {
+			if err := scoped.model().Where(fmt.Sprintf("%s IN ?", config.Id), chunk).Delete(new(T)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *StudentRepo[T]) Query(
 	opts []QueryOption,
 	paginationOpt QueryOption,
 ) ([]*T, int64, error) {
 
-	db := r.db
-	for _, opt := range opts {
-		db = opt(db)
-	}
+	query := BuildQuery(opts, paginationOpt)
+	db := applyFilters(r.model(), query)
 
 	// Get total count before pagiantion
 	var totalCount int64
-	if err := db.Model(new(T)).Count(&totalCount).Error; err != nil {
+	if err := db.Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
+	// Searches are ordered by relevance unless sorted explicitly
+	if query.Search != nil && len(query.Sorts) == 0 {
+		_, _, rank := searchSQL(db, *query.Search)
+		db = db.Clauses(orderByRank(rank, string(config.Id)))
+	}
+
+	for _, sort := range query.OrderedSorts() {
+		db = db.Order(fmt.Sprintf("%s %s", sort.Column, sort.Order))
+	}
+
 	// Apply pagination
-	if paginationOpt != nil {
-		db = paginationOpt(db)
+	if query.Paginated() {
+		db = db.Offset((query.Page - 1) * query.Size).Limit(query.Size)
 	}
 
 	var students []*T
@@ -150,38 +296,60 @@ func (r *StudentRepo[T]) Query(
 	return students, totalCount, result.Error
 }
 
-func WithSubject(subject config.Course) QueryOption {
-	return func(db *gorm.DB) *gorm.DB {
-		if subject != "" {
-			return db.Where(string(config.Subject)+" = ?", subject)
-		}
-		return db
+// applyFilters translates the query filters to SQL conditions
+func applyFilters(db *gorm.DB, query Query) *gorm.DB {
+	if query.NamePrefix != "" {
+		db = db.Where(ILike(db, string(config.Name)), query.NamePrefix+"%")
 	}
-}
 
-func WithSort(sortedBy config.StudentCol, order config.SortOrder) QueryOption {
-	return func(db *gorm.DB) *gorm.DB {
-		if sortedBy != "" {
-			return db.Order(fmt.Sprintf("%s %s", sortedBy, order))
-		}
-		return db
+	if query.Subject != "" {
+		db = db.Where(string(config.Subject)+" = ?", query.Subject)
+	}
+
+	for _, condition := range query.Conditions {
+		sql, args := conditionSQL(db, condition)
+		db = db.Where(sql, args...)
 	}
+
+	if query.Search != nil {
+		sql, args, _ := searchSQL(db, *query.Search)
+		db = db.Where(sql, args...)
+	}
+
+	return db
 }
 
-func WithPagination(page, size int) QueryOption {
-	return func(db *gorm.DB) *gorm.DB {
-		if page > 0 && size > 0 {
-			return db.Offset((page - 1) * size).Limit(size)
-		}
-		return db
+// Suggest returns distinct names matching a fuzzy search, most relevant
+// first, among the students matching opts
+func (r *StudentRepo[T]) Suggest(term string, limit int, opts ...QueryOption) ([]string, error) {
+	term = strings.TrimSpace(term)
+	if term == "" || limit <= 0 {
+		return []string{}, nil
 	}
+
+	search := Search{Term: term, Mode: config.SearchFuzzy}
+	where, args, rank := searchSQL(r.db, search)
+
+	names := []string{}
+	err := applyFilters(r.model(), BuildQuery(opts, nil)).
+		Where(where, args...).
+		Group(string(config.Name)).
+		Clauses(orderByRank(rank, string(config.Name))).
+		Limit(limit).
+		Pluck(string(config.Name), &names).Error
+	return names, err
 }
 
-func WithNameFilter(name string) QueryOption {
-	return func(db *gorm.DB) *gorm.DB {
-		if name != "" {
-			return db.Where(string(config.Name)+" ILIKE ?", name+"%")
-		}
-		return db
+// orderByRank orders by a relevance expression then by the tiebreaker column,
+// gorm's Order only accepts plain columns
+func orderByRank(rank clause.Expr, tiebreaker string) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: rank.SQL + ", " + tiebreaker, Vars: rank.Vars}}
+}
+
+// ILike builds a case insensitive LIKE condition for the dialect of the connection
+func ILike(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == string(config.DriverPostgres) {
+		return column + " ILIKE ?"
 	}
+	return "LOWER(" + column + ") LIKE LOWER(?)"
 }
@@ -4,6 +4,8 @@ import (
 	"file-uploader/config"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
+	processor "file-uploader/internal/service/csv"
+	Seeder "file-uploader/internal/service/csv/seeder"
 	testutils "file-uploader/internal/test-utils"
 	"log"
 	"os"
@@ -40,8 +42,7 @@ func TestMain(m *testing.M) {
 	testDB.Migrator().DropTable(model.StudentTest{})
 
 	// Cleanup
-	sqlDB, _ := testDB.DB()
-	sqlDB.Close()
+	testutils.CloseDb(testDB)
 
 	os.Exit(code)
 }
@@ -427,24 +428,80 @@ func TestFilterBySubject(t *testing.T) {
 }
 
 // [AI]
+func TestDelete(t *testing.T) {
+	repos := map[string]repository.StudentRepository[model.StudentTest]{
+		"database": repository.NewStudentRepository[model.StudentTest](testDB),
+		"memory":   repository.NewMemoryStudentRepository[model.StudentTest](),
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			tenant := repo.ForTenant(uuid.New())
+			other := repo.ForTenant(uuid.New())
+			importID := uuid.New()
+			defer tenant.DeleteAll()
+			defer other.DeleteAll()
+
+			imported := seededStudents(t, 30, 6)
+			kept := seededStudents(t, 31, 4)
+			require.NoError(t, tenant.ForImport(importID).CreateMany(imported))
+			require.NoError(t, tenant.CreateMany(kept))
+			require.NoError(t, other.ForImport(importID).CreateMany(seededStudents(t, 32, 3)))
+
+			// The subject scope of the user limits what is deleted
+			subject := imported[0].Subject
+			var inScope []uuid.UUID
+			for _, student := range imported {
+				if student.Subject == subject {
+					inScope = append(inScope, student.Student_id)
+				}
+			}
+			ids, err := tenant.Delete([]repository.QueryOption{repository.WithImport(importID), repository.WithSubjectScope([]string{subject})})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, inScope, ids)
+
+			// The rest of the upload goes, rows stored otherwise and rows of
+			// other tenants stay
+			ids, err = tenant.Delete([]repository.QueryOption{repository.WithImport(importID)})
+			require.NoError(t, err)
+			assert.Len(t, ids, len(imported)-len(inScope))
+
+			_, count, err := tenant.Query(nil, nil)
+			require.NoError(t, err)
+			assert.EqualValues(t, len(kept), count)
+			_, count, err = other.Query(nil, nil)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, count)
+
+			ids, err = tenant.Delete([]repository.QueryOption{repository.WithId(kept[0].Student_id)})
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{kept[0].Student_id}, ids)
+
+			ids, err = other.Delete([]repository.QueryOption{repository.WithId(kept[1].Student_id)})
+			require.NoError(t, err)
+			assert.Empty(t, ids, "rows of other tenants are never deleted")
+		})
+	}
+}
+
 func setupTestData(t *testing.T) repository.StudentRepository[model.StudentTest] {
-	// Clear any previous test data
-	testDB.Where("1=1").Delete(&model.StudentTest{})
-
-	// Create test data - 10 students with ordered names
-	testData := []*model.StudentTest{
-		{Student_name: "Student01", Subject: string(config.Mathematics), Grade: 70},
-		{Student_name: "Student02", Subject: string(config.Physics), Grade: 85},
-		{Student_name: "Student03", Subject: string(config.Chemistry), Grade: 90},
-		{Student_name: "Student04", Subject: string(config.Biology), Grade: 75},
-		{Student_name: "Student05", Subject: string(config.History), Grade: 80},
-		{Student_name: "Student06", Subject: string(config.Geography), Grade: 65},
-		{Student_name: "Student07", Subject: string(config.Art), Grade: 95},
-		{Student_name: "Student08", Subject: string(config.Music), Grade: 88},
-		{Student_name: "Student09", Subject: string(config.EnglishLit), Grade: 92},
-		{Student_name: "Student10", Subject: string(config.CompSci), Grade: 98},
+	// 10 students with ordered names, replacing any previous test data
+	fixture := &Seeder.Fixture{
+		Students: []Seeder.FixtureStudent{
+			{Name: "Student01", Subject: string(config.Mathematics), Grade: 70},
+			{Name: "Student02", Subject: string(config.Physics), Grade: 85},
+			{Name: "Student03", Subject: string(config.Chemistry), Grade: 90},
+			{Name: "Student04", Subject: string(config.Biology), Grade: 75},
+			{Name: "Student05", Subject: string(config.History), Grade: 80},
+			{Name: "Student06", Subject: string(config.Geography), Grade: 65},
+			{Name: "Student07", Subject: string(config.Art), Grade: 95},
+			{Name: "Student08", Subject: string(config.Music), Grade: 88},
+			{Name: "Student09", Subject: string(config.EnglishLit), Grade: 92},
+			{Name: "Student10", Subject: string(config.CompSci), Grade: 98},
+		},
 	}
-	err := studentRepo.CreateMany(testData)
+	loader := Seeder.Loader[model.StudentTest]{Students: studentRepo, Mapper: processor.StudentTestMapper}
+	_, err := loader.Load(fixture, true)
 	require.NoError(t, err, "Failed to create test data")
 
 	return studentRepo
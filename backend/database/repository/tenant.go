@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scoped returns repo limited to the rows of a tenant, nil repositories stay nil
+func Scoped[R interface{ ForTenant(uuid.UUID) R }](repo R, tenantID uuid.UUID) R {
+	if any(repo) == nil {
+		return repo
+	}
+	return repo.ForTenant(tenantID)
+}
+
+// tenantOrDefault maps a missing tenant to the default one, repositories
+// are always scoped to exactly one tenant
+func tenantOrDefault(tenantID uuid.UUID) uuid.UUID {
+	if tenantID == uuid.Nil {
+		return config.DefaultTenantID
+	}
+	return tenantID
+}
+
+type TenantRepo struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &TenantRepo{db: db}
+}
+
+// Create adds a tenant with its own copy of the default course catalog
+func (r *TenantRepo) Create(tenant *model.Tenant) error {
+	if tenant == nil || strings.TrimSpace(tenant.Name) == "" {
+		return config.ErrMissingTenantData
+	}
+	tenant.Name = strings.TrimSpace(tenant.Name)
+
+	if _, err := r.GetByName(tenant.Name); err == nil {
+		return config.ErrTenantAlreadyExist
+	} else if !errors.Is(err, config.ErrTenantNotExist) {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tenant).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		courses := make([]*model.Course, len(config.DefaultCourses))
+		for i, name := range config.DefaultCourses {
+			courses[i] = &model.Course{TenantID: tenant.ID, Name: string(name), CreatedAt: now, UpdatedAt: now}
+		}
+		return tx.Create(&courses).Error
+	})
+}
+
+func (r *TenantRepo) List() ([]*model.Tenant, error) {
+	tenants := []*model.Tenant{}
+	result := r.db.Order("name asc").Find(&tenants)
+	return tenants, result.Error
+}
+
+func (r *TenantRepo) GetByName(name string) (*model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.Where("name = ?", name).First(&tenant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrTenantNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
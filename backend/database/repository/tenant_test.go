@@ -0,0 +1,200 @@
+package repository_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTenants creates two schools with their own default catalog
+func newTenants(t *testing.T) (uuid.UUID, uuid.UUID) {
+	tenants := repository.NewTenantRepository(testDB)
+
+	first := &model.Tenant{Name: "North School " + uuid.NewString()}
+	second := &model.Tenant{Name: "South School " + uuid.NewString()}
+	require.NoError(t, tenants.Create(first))
+	require.NoError(t, tenants.Create(second))
+
+	return first.ID, second.ID
+}
+
+func TestTenantRepository(t *testing.T) {
+	tenants := repository.NewTenantRepository(testDB)
+	name := "Tenant " + uuid.NewString()
+
+	tenant := &model.Tenant{Name: "  " + name + " "}
+	require.NoError(t, tenants.Create(tenant))
+	assert.Equal(t, name, tenant.Name)
+
+	assert.ErrorIs(t, tenants.Create(&model.Tenant{Name: name}), config.ErrTenantAlreadyExist)
+	assert.ErrorIs(t, tenants.Create(&model.Tenant{Name: " "}), config.ErrMissingTenantData)
+
+	_, err := tenants.GetByName("Unknown " + uuid.NewString())
+	assert.ErrorIs(t, err, config.ErrTenantNotExist)
+
+	// New tenants start with the default catalog
+	courses, err := repository.NewCourseRepository(testDB).ForTenant(tenant.ID).List()
+	require.NoError(t, err)
+	assert.Len(t, courses, len(config.DefaultCourses))
+}
+
+func TestTenantIsolation(t *testing.T) {
+	north, south := newTenants(t)
+
+	t.Run("students", func(t *testing.T) {
+		for _, repo := range map[string]repository.StudentRepository[model.StudentTest]{
+			"database": studentRepo,
+			"memory":   repository.NewMemoryStudentRepository[model.StudentTest](),
+		} {
+			northRepo := repo.ForTenant(north)
+			southRepo := repo.ForTenant(south)
+
+			_, err := northRepo.Create(&model.StudentTest{Student_name: "Isolated Ali", Subject: string(config.Physics), Grade: 90})
+			require.NoError(t, err)
+			require.NoError(t, southRepo.CreateMany([]*model.StudentTest{
+				{Student_name: "Isolated Omar", Subject: string(config.Physics), Grade: 80},
+				{Student_name: "Isolated Saad", Subject: string(config.Art), Grade: 70},
+			}))
+
+			students, count, err := northRepo.Query([]repository.QueryOption{repository.WithNameFilter("Isolated")}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			assert.Equal(t, "Isolated Ali", students[0].Student_name)
+			assert.Equal(t, north, students[0].Tenant_id)
+
+			// Filters can't reach rows of another tenant
+			condition := &repository.Condition{Column: config.Name, Op: repository.OpEq, Values: []any{"Isolated Omar"}}
+			_, count, err = northRepo.Query([]repository.QueryOption{repository.WithCondition(condition)}, nil)
+			require.NoError(t, err)
+			assert.Zero(t, count)
+
+			_, count, err = southRepo.Query([]repository.QueryOption{repository.WithNameFilter("Isolated")}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+
+			names, err := northRepo.Suggest("isolated omar", 10)
+			require.NoError(t, err)
+			assert.NotContains(t, names, "Isolated Omar")
+		}
+	})
+
+	t.Run("analytics", func(t *testing.T) {
+		analytics := repository.NewAnalyticsRepository[model.StudentTest](testDB)
+
+		ranks, err := analytics.ForTenant(north).Top(10, config.SortDesc, []repository.QueryOption{repository.WithNameFilter("Isolated")})
+		require.NoError(t, err)
+		require.Len(t, ranks, 1)
+		assert.Equal(t, "Isolated Ali", ranks[0].StudentName)
+
+		stats, err := analytics.ForTenant(south).CompareSubjects([]repository.QueryOption{repository.WithNameFilter("Isolated")})
+		require.NoError(t, err)
+		assert.Len(t, stats, 2)
+	})
+
+	t.Run("courses", func(t *testing.T) {
+		courses := repository.NewCourseRepository(testDB)
+
+		// The same name can exist in both catalogs
+		require.NoError(t, courses.ForTenant(north).Create(&model.Course{Name: "Robotics"}))
+		require.NoError(t, courses.ForTenant(south).Create(&model.Course{Name: "Robotics"}))
+
+		require.NoError(t, courses.ForTenant(north).Delete("Robotics"))
+		exists, err := courses.ForTenant(south).Exists("Robotics")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		require.NoError(t, courses.ForTenant(north).Create(&model.Course{Name: "North Only"}))
+		_, err = courses.ForTenant(south).Get("North Only")
+		assert.ErrorIs(t, err, config.ErrCourseNotExist)
+	})
+
+	t.Run("grade records", func(t *testing.T) {
+		grades := repository.NewGradeRepository(testDB)
+
+		// Students sharing a name in different schools are different people
+		require.NoError(t, grades.ForTenant(north).Import([]*model.Student{
+			{Student_id: uuid.New(), Student_name: "Tenant Profile", Subject: string(config.Physics), Grade: 90},
+		}, ""))
+		require.NoError(t, grades.ForTenant(south).Import([]*model.Student{
+			{Student_id: uuid.New(), Student_name: "Tenant Profile", Subject: string(config.Art), Grade: 60},
+			{Student_id: uuid.New(), Student_name: "Tenant Profile", Subject: string(config.Music), Grade: 50},
+		}, ""))
+
+		profiles, count, err := grades.ForTenant(north).QueryNested(repository.NestedQuery{Name: "Tenant Profile"})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+		require.Len(t, profiles[0].Grades, 1)
+		assert.Equal(t, string(config.Physics), profiles[0].Grades[0].Subject)
+
+		profiles, _, err = grades.ForTenant(south).QueryNested(repository.NestedQuery{Name: "Tenant Profile"})
+		require.NoError(t, err)
+		require.Len(t, profiles, 1)
+		assert.Len(t, profiles[0].Grades, 2)
+	})
+
+	t.Run("flat rows need a course of their own tenant", func(t *testing.T) {
+		require.NoError(t, repository.NewCourseRepository(testDB).ForTenant(north).Create(&model.Course{Name: "North Elective"}))
+
+		students := repository.NewStudentRepository[model.Student](testDB)
+		_, err := students.ForTenant(north).Create(&model.Student{Student_name: "Elective Ali", Subject: "North Elective", Grade: 80})
+		require.NoError(t, err)
+
+		_, err = students.ForTenant(south).Create(&model.Student{Student_name: "Elective Omar", Subject: "North Elective", Grade: 80})
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteAll(t *testing.T) {
+	north, south := newTenants(t)
+
+	for name, repo := range map[string]repository.StudentRepository[model.Student]{
+		"database": repository.NewNormalizingRepository(
+			repository.NewStudentRepository[model.Student](testDB),
+			repository.NewGradeRepository(testDB),
+			"",
+		),
+		"memory": repository.NewMemoryStudentRepository[model.Student](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, tenant := range []uuid.UUID{north, south} {
+				require.NoError(t, repo.ForTenant(tenant).CreateMany([]*model.Student{
+					{Student_name: "Reset Ali", Subject: string(config.Physics), Grade: 90},
+					{Student_name: "Reset Omar", Subject: string(config.Art), Grade: 80},
+				}))
+			}
+
+			// Only the rows of the tenant go
+			deleted, err := repo.ForTenant(north).DeleteAll()
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), deleted)
+
+			_, count, err := repo.ForTenant(north).Query(nil, nil)
+			require.NoError(t, err)
+			assert.Zero(t, count)
+
+			_, count, err = repo.ForTenant(south).Query(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+
+			// Deleting again is a no-op
+			deleted, err = repo.ForTenant(north).DeleteAll()
+			require.NoError(t, err)
+			assert.Zero(t, deleted)
+		})
+	}
+
+	// The grade records follow the flat rows
+	grades := repository.NewGradeRepository(testDB)
+	_, count, err := grades.ForTenant(north).QueryNested(repository.NestedQuery{Name: "Reset"})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, count, err = grades.ForTenant(south).QueryNested(repository.NestedQuery{Name: "Reset"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
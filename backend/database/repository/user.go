@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserRepo struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(user *model.User) error {
+	if user == nil || strings.TrimSpace(user.Email) == "" || user.PasswordHash == "" {
+		return config.ErrMissingUserData
+	}
+
+	if _, err := r.GetByEmail(user.Email); err == nil {
+		return config.ErrUserAlreadyExist
+	} else if !errors.Is(err, config.ErrUserNotExist) {
+		return err
+	}
+
+	if user.Role == "" {
+		user.Role = config.RoleViewer
+	}
+	user.TenantID = tenantOrDefault(user.TenantID)
+	if !ValidRole(user.Role) {
+		return config.ErrInvalidRole
+	}
+
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepo) Get(id uuid.UUID) (*model.User, error) {
+	return r.first("id = ?", id)
+}
+
+func (r *UserRepo) GetByEmail(email string) (*model.User, error) {
+	return r.first("email = ?", email)
+}
+
+func (r *UserRepo) SetRole(id uuid.UUID, role config.Role) error {
+	if !ValidRole(role) {
+		return config.ErrInvalidRole
+	}
+
+	result := r.db.Model(&model.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return config.ErrUserNotExist
+	}
+	return nil
+}
+
+// SetAllSubjects lets a user see every subject, or only the assigned ones
+func (r *UserRepo) SetAllSubjects(id uuid.UUID, all bool) error {
+	result := r.db.Model(&model.User{}).Where("id = ?", id).Update("all_subjects", all)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return config.ErrUserNotExist
+	}
+	return nil
+}
+
+// Subjects returns the subjects assigned to a user
+func (r *UserRepo) Subjects(id uuid.UUID) ([]string, error) {
+	subjects := []string{}
+	result := r.db.Model(&model.UserSubject{}).Where("user_id = ?", id).Order("subject asc").Pluck("subject", &subjects)
+	return subjects, result.Error
+}
+
+// SetSubjects replaces the subjects of a user and limits the user to them,
+// subjects must be in the course catalog of the user's tenant
+func (r *UserRepo) SetSubjects(id uuid.UUID, subjects []string) error {
+	user, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&model.UserSubject{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", id).Update("all_subjects", false).Error; err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(subjects))
+		for _, subject := range subjects {
+			if seen[subject] {
+				continue
+			}
+			seen[subject] = true
+
+			var count int64
+			if err := tx.Model(&model.Course{}).Where("tenant_id = ? AND name = ?", user.TenantID, subject).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return config.ErrCourseNotExist
+			}
+
+			if err := tx.Create(&model.UserSubject{UserID: id, TenantID: user.TenantID, Subject: subject}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func ValidRole(role config.Role) bool {
+	return role == config.RoleViewer || role == config.RoleUploader || role == config.RoleAdmin || role == config.RolePlatformAdmin
+}
+
+func (r *UserRepo) first(query string, args ...any) (*model.User, error) {
+	var user model.User
+	err := r.db.Where(query, args...).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrUserNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+type SessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &SessionRepo{db: db}
+}
+
+func (r *SessionRepo) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+// Get returns an unexpired session
+func (r *SessionRepo) Get(id string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.Where("id = ? AND expires_at > ?", id, time.Now()).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepo) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&model.Session{}).Error
+}
+
+// DeleteExpired removes expired sessions and returns how many were removed
+func (r *SessionRepo) DeleteExpired() (int64, error) {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
+
+type APITokenRepo struct {
+	db *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &APITokenRepo{db: db}
+}
+
+func (r *APITokenRepo) Create(token *model.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *APITokenRepo) List(userID uuid.UUID) ([]*model.APIToken, error) {
+	tokens := []*model.APIToken{}
+	result := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&tokens)
+	return tokens, result.Error
+}
+
+// GetByHash returns an unexpired token
+func (r *APITokenRepo) GetByHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", hash, time.Now()).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, config.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Touch records the last use of a token
+func (r *APITokenRepo) Touch(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// Revoke deletes a token of the user
+func (r *APITokenRepo) Revoke(userID, id uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.APIToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return config.ErrTokenNotExist
+	}
+	return nil
+}
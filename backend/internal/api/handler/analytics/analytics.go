@@ -0,0 +1,181 @@
+package analytics
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	expression "file-uploader/internal/service/filter"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AnalyticsFilter narrows the records analytics are computed on, like the students listing
+type AnalyticsFilter struct {
+	Name          string            `query:"name"`
+	Subject       config.Course     `query:"subject"`
+	Filter        string            `query:"filter"`
+	Search        string            `query:"search"`
+	SearchMode    config.SearchMode `query:"search_mode"`
+	MinPercentile float64           `query:"min_percentile"`
+	N             int               `query:"n"`
+}
+
+const (
+	DefaultTopN = 10
+	MaxTopN     = 100
+)
+
+var validSearchModes = map[config.SearchMode]bool{
+	config.SearchSubstring: true,
+	config.SearchToken:     true,
+	config.SearchFuzzy:     true,
+}
+
+// Percentiles handles GET /analytics/percentiles requests, e.g. min_percentile=90 for the top 10%
+func (h *Handler) Percentiles(c echo.Context) error {
+	h = h.forTenant(c)
+
+	filter, opts, err := h.bind(c)
+	if err != nil {
+		return err
+	}
+
+	if filter.MinPercentile < 0 || filter.MinPercentile > 100 {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+	}
+
+	ranks, err := h.Repo.Percentiles(opts, filter.MinPercentile)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to compute percentiles: "+err.Error())
+	}
+
+	return rankings(c, ranks)
+}
+
+// Top handles GET /analytics/top requests, the n best records of each subject
+func (h *Handler) Top(c echo.Context) error {
+	return h.top(c, config.SortDesc)
+}
+
+// Bottom handles GET /analytics/bottom requests, the n worst records of each subject
+func (h *Handler) Bottom(c echo.Context) error {
+	return h.top(c, config.SortAsc)
+}
+
+func (h *Handler) top(c echo.Context, order config.SortOrder) error {
+	h = h.forTenant(c)
+
+	filter, opts, err := h.bind(c)
+	if err != nil {
+		return err
+	}
+
+	if filter.N <= 0 || filter.N > MaxTopN {
+		filter.N = DefaultTopN
+	}
+
+	ranks, err := h.Repo.Top(filter.N, order, opts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to compute rankings: "+err.Error())
+	}
+
+	return rankings(c, ranks)
+}
+
+// Subjects handles GET /analytics/subjects requests, comparing grades across subjects
+func (h *Handler) Subjects(c echo.Context) error {
+	h = h.forTenant(c)
+
+	_, opts, err := h.bind(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.Repo.CompareSubjects(opts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to compare subjects: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Subjects []*model.SubjectStats `json:"subjects"`
+	}{
+		Subjects: stats,
+	})
+}
+
+// Letters handles GET /analytics/letters requests, counting records per subject and letter grade
+func (h *Handler) Letters(c echo.Context) error {
+	h = h.forTenant(c)
+
+	_, opts, err := h.bind(c)
+	if err != nil {
+		return err
+	}
+
+	scales, err := h.Scales.ForCourses()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch grading scales: "+err.Error())
+	}
+
+	counts, err := h.Repo.LetterDistribution(opts, scales)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to count letter grades: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Letters []*model.LetterCount `json:"letters"`
+	}{
+		Letters: counts,
+	})
+}
+
+// bind validates the filters shared by every analytics endpoint
+func (h *Handler) bind(c echo.Context) (AnalyticsFilter, []repository.QueryOption, error) {
+	var filter AnalyticsFilter
+	if err := c.Bind(&filter); err != nil {
+		return filter, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+	}
+
+	if filter.SearchMode == "" {
+		filter.SearchMode = config.SearchToken
+	}
+	if !validSearchModes[filter.SearchMode] {
+		return filter, nil, echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidSearchParamHttp)
+	}
+
+	// Validate subject against the course catalog
+	if filter.Subject != "" {
+		exists, err := h.Courses.Exists(string(filter.Subject))
+		if err != nil {
+			return filter, nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch courses: "+err.Error())
+		}
+		if !exists {
+			return filter, nil, echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+		}
+	}
+
+	condition, err := expression.Parse(filter.Filter)
+	if err != nil {
+		return filter, nil, echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp+": "+err.Error())
+	}
+
+	return filter, []repository.QueryOption{
+		repository.WithNameFilter(filter.Name),
+		repository.WithSubject(filter.Subject),
+		repository.WithCondition(condition),
+		repository.WithSearch(filter.Search, filter.SearchMode),
+		repository.WithSubjectScope(middleware.SubjectScope(c)),
+	}, nil
+}
+
+func rankings(c echo.Context, ranks []*model.StudentRank) error {
+	return c.JSON(http.StatusOK, struct {
+		Count   int                  `json:"count"`
+		Records []*model.StudentRank `json:"records"`
+	}{
+		Count:   len(ranks),
+		Records: ranks,
+	})
+}
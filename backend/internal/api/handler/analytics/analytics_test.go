@@ -0,0 +1,151 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	processor "file-uploader/internal/service/csv"
+	Seeder "file-uploader/internal/service/csv/seeder"
+	testutils "file-uploader/internal/test-utils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestData(t *testing.T) {
+	fixture := &Seeder.Fixture{
+		Students: []Seeder.FixtureStudent{
+			{Name: "Ali", Subject: string(config.Physics), Grade: 95},
+			{Name: "Omar", Subject: string(config.Physics), Grade: 70},
+			{Name: "Saad", Subject: string(config.Physics), Grade: 50},
+			{Name: "Ali", Subject: string(config.Art), Grade: 60},
+			{Name: "Omar", Subject: string(config.Art), Grade: 85},
+		},
+	}
+	loader := Seeder.Loader[model.StudentTest]{Students: testStudentsRepo, Mapper: processor.StudentTestMapper}
+	_, err := loader.Load(fixture, true)
+	require.NoError(t, err)
+}
+
+type rankingsResponse struct {
+	Count   int                  `json:"count"`
+	Records []*model.StudentRank `json:"records"`
+}
+
+func TestRankings(t *testing.T) {
+	setupTestData(t)
+
+	cases := []struct {
+		name     string
+		target   string
+		handler  func(c echo.Context) error
+		expected []string
+	}{
+		{
+			name:     "top percentile of a subject",
+			target:   "/analytics/percentiles?subject=Physics&min_percentile=90",
+			handler:  testAnalyticsHandler.Percentiles,
+			expected: []string{"Physics/Ali"},
+		},
+		{
+			name:     "top student per subject",
+			target:   "/analytics/top?n=1",
+			handler:  testAnalyticsHandler.Top,
+			expected: []string{"Art/Omar", "Physics/Ali"},
+		},
+		{
+			name:     "bottom student per subject",
+			target:   "/analytics/bottom?n=1",
+			handler:  testAnalyticsHandler.Bottom,
+			expected: []string{"Art/Ali", "Physics/Saad"},
+		},
+		{
+			name:     "rankings honor filter expressions",
+			target:   "/analytics/top?n=1&filter=" + url.QueryEscape("grade < 90"),
+			handler:  testAnalyticsHandler.Top,
+			expected: []string{"Art/Omar", "Physics/Omar"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := testutils.NewTestContext(http.MethodGet, tt.target, nil)
+			require.NoError(t, tt.handler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var response rankingsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+			var names []string
+			for _, record := range response.Records {
+				names = append(names, record.Subject+"/"+record.StudentName)
+			}
+			assert.Equal(t, tt.expected, names)
+			assert.Equal(t, len(tt.expected), response.Count)
+		})
+	}
+}
+
+func TestSubjects(t *testing.T) {
+	setupTestData(t)
+
+	c, rec := testutils.NewTestContext(http.MethodGet, "/analytics/subjects", nil)
+	require.NoError(t, testAnalyticsHandler.Subjects(c))
+
+	var response struct {
+		Subjects []*model.SubjectStats `json:"subjects"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Subjects, 2)
+
+	assert.Equal(t, string(config.Art), response.Subjects[0].Subject)
+	assert.InDelta(t, 72.5, response.Subjects[0].Average, 0.001)
+	assert.Equal(t, string(config.Physics), response.Subjects[1].Subject)
+	assert.InDelta(t, 71.67, response.Subjects[1].Average, 0.01)
+}
+
+func TestAnalyticsValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		target  string
+		handler func(c echo.Context) error
+	}{
+		{name: "percentile above 100", target: "/analytics/percentiles?min_percentile=150", handler: testAnalyticsHandler.Percentiles},
+		{name: "unknown subject", target: "/analytics/top?subject=Alchemy", handler: testAnalyticsHandler.Top},
+		{name: "invalid filter expression", target: "/analytics/subjects?filter=" + url.QueryEscape("grade >"), handler: testAnalyticsHandler.Subjects},
+		{name: "invalid search mode", target: "/analytics/bottom?search=ali&search_mode=regex", handler: testAnalyticsHandler.Bottom},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testutils.NewTestContext(http.MethodGet, tt.target, nil)
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, tt.handler(c), &httpErr)
+			assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		})
+	}
+}
+
+func TestLetters(t *testing.T) {
+	setupTestData(t)
+
+	c, rec := testutils.NewTestContext(http.MethodGet, "/analytics/letters", nil)
+	require.NoError(t, testAnalyticsHandler.Letters(c))
+
+	var response struct {
+		Letters []*model.LetterCount `json:"letters"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	var counts []string
+	for _, count := range response.Letters {
+		counts = append(counts, fmt.Sprintf("%s/%s=%d", count.Subject, count.Letter, count.Count))
+	}
+	assert.Equal(t, []string{"Art/B=1", "Art/D=1", "Physics/A=1", "Physics/C=1", "Physics/F=1"}, counts)
+}
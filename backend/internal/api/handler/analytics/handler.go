@@ -0,0 +1,36 @@
+package analytics
+
+import (
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	Repo    repository.AnalyticsRepository
+	Courses repository.CourseRepository
+	Scales  repository.GradingScaleRepository
+}
+
+func NewHandler(
+	repo repository.AnalyticsRepository,
+	courses repository.CourseRepository,
+	scales repository.GradingScaleRepository,
+) AnalyticsHandler {
+	return &Handler{
+		Repo:    repo,
+		Courses: courses,
+		Scales:  scales,
+	}
+}
+
+// forTenant returns the handler with repositories scoped to the tenant of the request
+func (h *Handler) forTenant(c echo.Context) *Handler {
+	tenantID := middleware.TenantID(c)
+	return &Handler{
+		Repo:    repository.Scoped(h.Repo, tenantID),
+		Courses: repository.Scoped(h.Courses, tenantID),
+		Scales:  repository.Scoped(h.Scales, tenantID),
+	}
+}
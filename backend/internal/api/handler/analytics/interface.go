@@ -0,0 +1,11 @@
+package analytics
+
+import "github.com/labstack/echo/v4"
+
+type AnalyticsHandler interface {
+	Percentiles(c echo.Context) error
+	Top(c echo.Context) error
+	Bottom(c echo.Context) error
+	Subjects(c echo.Context) error
+	Letters(c echo.Context) error
+}
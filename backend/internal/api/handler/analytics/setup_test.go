@@ -0,0 +1,49 @@
+package analytics_test
+
+import (
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/analytics"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testAnalyticsHandler analytics.AnalyticsHandler
+var testStudentsRepo repository.StudentRepository[model.StudentTest]
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, repo, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testStudentsRepo = repo
+	testAnalyticsHandler = analytics.NewHandler(
+		repository.NewAnalyticsRepository[model.StudentTest](db),
+		repository.NewCourseRepository(db),
+		repository.NewGradingScaleRepository(db),
+	)
+
+	// Run tests
+	code := m.Run()
+
+	// Drop table after tests
+	testDB.Migrator().DropTable(model.StudentTest{})
+
+	// Cleanup
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
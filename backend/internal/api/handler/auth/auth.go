@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type LoginBody struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type TokenBody struct {
+	Name string `json:"name"`
+
+	// ExpiresIn is a duration like "720h", empty never expires
+	ExpiresIn string `json:"expires_in"`
+}
+
+// Login handles POST /auth/login requests, setting the session cookie and
+// returning the session token for clients without cookies
+func (h *Handler) Login(c echo.Context) error {
+	var body LoginBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	token, session, err := h.Service.Login(body.Email, body.Password)
+	if errors.Is(err, config.ErrInvalidCredentials) {
+		return echo.NewHTTPError(http.StatusUnauthorized, config.ErrInvalidCredentialsHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to log in: "+err.Error())
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     config.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   c.Request().TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.JSON(http.StatusOK, struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+	})
+}
+
+// Logout handles POST /auth/logout requests, ending the current session
+func (h *Handler) Logout(c echo.Context) error {
+	if err := h.Service.Logout(middleware.Credential(c)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to log out: "+err.Error())
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     config.SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Me handles GET /auth/me requests, returning the user with what it may do
+func (h *Handler) Me(c echo.Context) error {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+	}
+
+	permissions := []auth.Permission{}
+	for _, permission := range auth.Permissions {
+		if principal.Can(permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		*model.User
+		Subjects    []string          `json:"subjects"`
+		Permissions []auth.Permission `json:"permissions"`
+	}{
+		User:        principal.User,
+		Subjects:    principal.Scope(),
+		Permissions: permissions,
+	})
+}
+
+// ListTokens handles GET /auth/tokens requests
+func (h *Handler) ListTokens(c echo.Context) error {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+	}
+
+	tokens, err := h.Service.Tokens.List(principal.User.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch api tokens: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count   int               `json:"count"`
+		Records []*model.APIToken `json:"records"`
+	}{
+		Count:   len(tokens),
+		Records: tokens,
+	})
+}
+
+// CreateToken handles POST /auth/tokens requests, the token is only shown once
+func (h *Handler) CreateToken(c echo.Context) error {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+	}
+
+	var body TokenBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	var ttl time.Duration
+	if body.ExpiresIn != "" {
+		var err error
+		ttl, err = time.ParseDuration(body.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+		}
+	}
+
+	token, apiToken, err := h.Service.CreateAPIToken(principal.User.ID, body.Name, ttl)
+	if errors.Is(err, config.ErrMissingUserData) {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create api token: "+err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, struct {
+		*model.APIToken
+		Token string `json:"token"`
+	}{
+		APIToken: apiToken,
+		Token:    token,
+	})
+}
+
+// RevokeToken handles DELETE /auth/tokens/:id requests
+func (h *Handler) RevokeToken(c echo.Context) error {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	err = h.Service.Tokens.Revoke(principal.User.ID, id)
+	if errors.Is(err, config.ErrTokenNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, config.ErrTokenNotFoundHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to revoke api token: "+err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/internal/api/middleware"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin(t *testing.T) {
+	testDB.Where("email = ?", "login@example.com").Delete(&model.User{})
+	_, err := testService.Register("login@example.com", "correct horse")
+	require.NoError(t, err)
+
+	t.Run("wrong password, should return unauthorized", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"login@example.com","password":"wrong horse"}`))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, testAuthHandler.Login(c), &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	})
+
+	t.Run("login sets the session cookie", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"login@example.com","password":"correct horse"}`))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		require.NoError(t, testAuthHandler.Login(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Token string `json:"token"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, config.SessionCookie, cookies[0].Name)
+		assert.Equal(t, response.Token, cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+
+		_, err := testService.Authenticate(response.Token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTokens(t *testing.T) {
+	testDB.Where("email = ?", "tokens@example.com").Delete(&model.User{})
+	user, err := testService.Register("tokens@example.com", "correct horse")
+	require.NoError(t, err)
+	session, _, err := testService.Login("tokens@example.com", "correct horse")
+	require.NoError(t, err)
+
+	// authenticated runs a handler behind the auth middleware
+	authenticated := func(handler echo.HandlerFunc, c echo.Context) error {
+		c.Request().Header.Set(echo.HeaderAuthorization, "Bearer "+session)
+		return middleware.RequireAuth(testService)(handler)(c)
+	}
+
+	c, rec := testutils.NewTestContext(http.MethodPost, "/auth/tokens", strings.NewReader(`{"name":"nightly import","expires_in":"720h"}`))
+	c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	require.NoError(t, authenticated(testAuthHandler.CreateToken, c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created struct {
+		ID        string  `json:"id"`
+		Token     string  `json:"token"`
+		ExpiresAt *string `json:"expires_at"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Token, config.APITokenPrefix))
+	assert.NotNil(t, created.ExpiresAt)
+
+	principal, err := testService.Authenticate(created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, principal.User.ID)
+
+	c, rec = testutils.NewTestContext(http.MethodGet, "/auth/tokens", nil)
+	require.NoError(t, authenticated(testAuthHandler.ListTokens, c))
+	assert.NotContains(t, rec.Body.String(), created.Token, "tokens are only shown once")
+
+	c, rec = testutils.NewTestContext(http.MethodDelete, "/auth/tokens/", nil)
+	c.SetParamNames("id")
+	c.SetParamValues(created.ID)
+	require.NoError(t, authenticated(testAuthHandler.RevokeToken, c))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = testService.Authenticate(created.Token)
+	assert.ErrorIs(t, err, config.ErrUnauthenticated)
+}
@@ -0,0 +1,13 @@
+package auth
+
+import "file-uploader/internal/service/auth"
+
+type Handler struct {
+	Service *auth.Service
+}
+
+func NewHandler(service *auth.Service) AuthHandler {
+	return &Handler{
+		Service: service,
+	}
+}
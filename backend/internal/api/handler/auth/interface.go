@@ -0,0 +1,12 @@
+package auth
+
+import "github.com/labstack/echo/v4"
+
+type AuthHandler interface {
+	Login(c echo.Context) error
+	Logout(c echo.Context) error
+	Me(c echo.Context) error
+	ListTokens(c echo.Context) error
+	CreateToken(c echo.Context) error
+	RevokeToken(c echo.Context) error
+}
@@ -0,0 +1,46 @@
+package auth_test
+
+import (
+	"file-uploader/database/repository"
+	authhandler "file-uploader/internal/api/handler/auth"
+	"file-uploader/internal/service/auth"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testService *auth.Service
+var testAuthHandler authhandler.AuthHandler
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, _, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testService = auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+	testAuthHandler = authhandler.NewHandler(testService)
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
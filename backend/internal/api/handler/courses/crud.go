@@ -0,0 +1,113 @@
+package courses
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CourseBody struct {
+	Name         string  `json:"name"`
+	GradingScale *string `json:"grading_scale"`
+}
+
+// List handles GET /courses requests
+func (h *Handler) List(c echo.Context) error {
+	courses, err := h.repo(c).List()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch courses: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count   int             `json:"count"`
+		Records []*model.Course `json:"records"`
+	}{
+		Count:   len(courses),
+		Records: courses,
+	})
+}
+
+// Get handles GET /courses/:name requests
+func (h *Handler) Get(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	course, err := h.repo(c).Get(name)
+	if err != nil {
+		return courseError(err)
+	}
+
+	return c.JSON(http.StatusOK, course)
+}
+
+// Create handles POST /courses requests
+func (h *Handler) Create(c echo.Context) error {
+	var body CourseBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	course := &model.Course{Name: body.Name, GradingScale: body.GradingScale}
+	if err := h.repo(c).Create(course); err != nil {
+		return courseError(err)
+	}
+
+	return c.JSON(http.StatusCreated, course)
+}
+
+// Update handles PUT /courses/:name requests, renaming the course and setting its grading scale
+func (h *Handler) Update(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	var body CourseBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	course := &model.Course{Name: body.Name, GradingScale: body.GradingScale}
+	if err := h.repo(c).Update(name, course); err != nil {
+		return courseError(err)
+	}
+
+	return c.JSON(http.StatusOK, course)
+}
+
+// Delete handles DELETE /courses/:name requests
+func (h *Handler) Delete(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	if err := h.repo(c).Delete(name); err != nil {
+		return courseError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// courseError maps repository errors to http errors
+func courseError(err error) error {
+	switch {
+	case errors.Is(err, config.ErrMissingCourseData):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, config.ErrCourseNotExist):
+		return echo.NewHTTPError(http.StatusNotFound, config.ErrCourseNotFoundHttp)
+	case errors.Is(err, config.ErrCourseAlreadyExist):
+		return echo.NewHTTPError(http.StatusConflict, config.ErrCourseConflictHttp)
+	case errors.Is(err, config.ErrCourseInUse):
+		return echo.NewHTTPError(http.StatusConflict, config.ErrCourseInUseHttp)
+	case errors.Is(err, config.ErrGradingScaleNotExist):
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrGradingScaleNotFoundHttp)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
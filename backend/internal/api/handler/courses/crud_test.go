@@ -0,0 +1,74 @@
+package courses_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoursesCRUD(t *testing.T) {
+	const name = "Handler Economics"
+
+	// Clear any previous test data
+	testDB.Where("name = ?", name).Delete(&model.Course{})
+
+	t.Run("list the seeded catalog", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/courses", nil)
+
+		require.NoError(t, testCoursesHandler.List(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Count   int            `json:"count"`
+			Records []model.Course `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.GreaterOrEqual(t, response.Count, len(config.DefaultCourses))
+	})
+
+	t.Run("create a course", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodPost, "/courses", strings.NewReader(`{"name":"`+name+`"}`))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		require.NoError(t, testCoursesHandler.Create(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("create a duplicate course, should return conflict", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodPost, "/courses", strings.NewReader(`{"name":"`+name+`"}`))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		err := testCoursesHandler.Create(c)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusConflict, httpErr.Code)
+	})
+
+	t.Run("delete the course", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodDelete, "/courses/", nil)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		require.NoError(t, testCoursesHandler.Delete(c))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("get a missing course, should return not found", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodGet, "/courses/", nil)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		err := testCoursesHandler.Get(c)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
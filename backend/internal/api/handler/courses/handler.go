@@ -0,0 +1,23 @@
+package courses
+
+import (
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	Repo repository.CourseRepository
+}
+
+func NewHandler(repo repository.CourseRepository) CoursesHandler {
+	return &Handler{
+		Repo: repo,
+	}
+}
+
+// repo returns the catalog of the tenant of the request
+func (h *Handler) repo(c echo.Context) repository.CourseRepository {
+	return repository.Scoped(h.Repo, middleware.TenantID(c))
+}
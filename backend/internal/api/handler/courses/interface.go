@@ -0,0 +1,11 @@
+package courses
+
+import "github.com/labstack/echo/v4"
+
+type CoursesHandler interface {
+	List(c echo.Context) error
+	Get(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
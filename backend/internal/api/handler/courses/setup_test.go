@@ -0,0 +1,39 @@
+package courses_test
+
+import (
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/courses"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testCoursesHandler courses.CoursesHandler
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, _, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testCoursesHandler = courses.NewHandler(repository.NewCourseRepository(db))
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
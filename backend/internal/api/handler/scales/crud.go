@@ -0,0 +1,129 @@
+package scales
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BandBody struct {
+	Letter   string `json:"letter"`
+	MinGrade uint   `json:"min_grade"`
+	Passing  bool   `json:"passing"`
+}
+
+type ScaleBody struct {
+	Name  string     `json:"name"`
+	Bands []BandBody `json:"bands"`
+}
+
+func (b ScaleBody) toModel() *model.GradingScale {
+	scale := &model.GradingScale{Name: b.Name}
+	for _, band := range b.Bands {
+		scale.Bands = append(scale.Bands, model.GradeBand{
+			Letter:   band.Letter,
+			MinGrade: band.MinGrade,
+			Passing:  band.Passing,
+		})
+	}
+	return scale
+}
+
+// List handles GET /grading-scales requests
+func (h *Handler) List(c echo.Context) error {
+	scales, err := h.Repo.List()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch grading scales: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count   int                   `json:"count"`
+		Records []*model.GradingScale `json:"records"`
+	}{
+		Count:   len(scales),
+		Records: scales,
+	})
+}
+
+// Get handles GET /grading-scales/:name requests
+func (h *Handler) Get(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	scale, err := h.Repo.Get(name)
+	if err != nil {
+		return scaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, scale)
+}
+
+// Create handles POST /grading-scales requests
+func (h *Handler) Create(c echo.Context) error {
+	var body ScaleBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	scale := body.toModel()
+	if err := h.Repo.Create(scale); err != nil {
+		return scaleError(err)
+	}
+
+	return c.JSON(http.StatusCreated, scale)
+}
+
+// Update handles PUT /grading-scales/:name requests, replacing the bands
+func (h *Handler) Update(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	var body ScaleBody
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidRequestBodyHttp)
+	}
+
+	scale := body.toModel()
+	if err := h.Repo.Update(name, scale); err != nil {
+		return scaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, scale)
+}
+
+// Delete handles DELETE /grading-scales/:name requests
+func (h *Handler) Delete(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	if err := h.Repo.Delete(name); err != nil {
+		return scaleError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// scaleError maps repository errors to http errors
+func scaleError(err error) error {
+	switch {
+	case errors.Is(err, config.ErrMissingGradingScaleData), errors.Is(err, config.ErrInvalidGradingScale):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, config.ErrGradingScaleNotExist):
+		return echo.NewHTTPError(http.StatusNotFound, config.ErrGradingScaleNotFoundHttp)
+	case errors.Is(err, config.ErrGradingScaleAlreadyExist):
+		return echo.NewHTTPError(http.StatusConflict, config.ErrGradingScaleConflictHttp)
+	case errors.Is(err, config.ErrGradingScaleInUse):
+		return echo.NewHTTPError(http.StatusConflict, config.ErrGradingScaleInUseHttp)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package scales_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalesCRUD(t *testing.T) {
+	const name = "handler_honors"
+
+	// Clear any previous test data
+	testDB.Where("name = ?", name).Delete(&model.GradingScale{})
+
+	t.Run("list the seeded scales", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/grading-scales", nil)
+		require.NoError(t, testScalesHandler.List(c))
+
+		var response struct {
+			Records []model.GradingScale `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+		var names []string
+		for _, scale := range response.Records {
+			names = append(names, scale.Name)
+		}
+		assert.Contains(t, names, config.DefaultGradingScale)
+	})
+
+	t.Run("create a scale", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodPost, "/grading-scales", strings.NewReader(
+			`{"name":"`+name+`","bands":[{"letter":"Fail","min_grade":0},{"letter":"Honors","min_grade":85,"passing":true}]}`,
+		))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		require.NoError(t, testScalesHandler.Create(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var scale model.GradingScale
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &scale))
+		require.Len(t, scale.Bands, 2)
+		assert.Equal(t, "Honors", scale.Bands[0].Letter)
+	})
+
+	invalid := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "duplicate scale", body: `{"name":"` + name + `","bands":[{"letter":"F","min_grade":0}]}`, code: http.StatusConflict},
+		{name: "no band starts at 0", body: `{"name":"gaps","bands":[{"letter":"A","min_grade":50}]}`, code: http.StatusBadRequest},
+		{name: "duplicate letters", body: `{"name":"twice","bands":[{"letter":"A","min_grade":0},{"letter":"a","min_grade":50}]}`, code: http.StatusBadRequest},
+		{name: "missing bands", body: `{"name":"empty"}`, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range invalid {
+		t.Run("create with "+tt.name, func(t *testing.T) {
+			c, _ := testutils.NewTestContext(http.MethodPost, "/grading-scales", strings.NewReader(tt.body))
+			c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, testScalesHandler.Create(c), &httpErr)
+			assert.Equal(t, tt.code, httpErr.Code)
+		})
+	}
+
+	t.Run("replace the bands", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodPut, "/grading-scales/", strings.NewReader(`{"bands":[{"letter":"F","min_grade":0},{"letter":"P","min_grade":40,"passing":true}]}`))
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		require.NoError(t, testScalesHandler.Update(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var scale model.GradingScale
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &scale))
+		assert.Equal(t, name, scale.Name)
+		assert.Equal(t, []string{"P", "F"}, []string{scale.Bands[0].Letter, scale.Bands[1].Letter})
+	})
+
+	t.Run("delete the default scale, should return conflict", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodDelete, "/grading-scales/", nil)
+		c.SetParamNames("name")
+		c.SetParamValues(config.DefaultGradingScale)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, testScalesHandler.Delete(c), &httpErr)
+		assert.Equal(t, http.StatusConflict, httpErr.Code)
+	})
+
+	t.Run("delete the scale", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodDelete, "/grading-scales/", nil)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		require.NoError(t, testScalesHandler.Delete(c))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("get a missing scale, should return not found", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodGet, "/grading-scales/", nil)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, testScalesHandler.Get(c), &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
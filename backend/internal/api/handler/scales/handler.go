@@ -0,0 +1,13 @@
+package scales
+
+import "file-uploader/database/repository"
+
+type Handler struct {
+	Repo repository.GradingScaleRepository
+}
+
+func NewHandler(repo repository.GradingScaleRepository) ScalesHandler {
+	return &Handler{
+		Repo: repo,
+	}
+}
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	DB *gorm.DB
+
+	// Inserts may be nil when the repository doesn't track its inserts
+	Inserts repository.InsertStatsReporter
+}
+
+func NewHandler(db *gorm.DB, inserts repository.InsertStatsReporter) StatsHandler {
+	return &Handler{
+		DB:      db,
+		Inserts: inserts,
+	}
+}
+
+// PoolStats are the counters of the pool of connections, durations in
+// milliseconds
+type PoolStats struct {
+	MaxOpen      int     `json:"max_open"`
+	Open         int     `json:"open"`
+	InUse        int     `json:"in_use"`
+	Idle         int     `json:"idle"`
+	WaitCount    int64   `json:"wait_count"`
+	WaitDuration float64 `json:"wait_duration_ms"`
+}
+
+type DatabaseStats struct {
+	Pool    PoolStats               `json:"pool"`
+	Inserts *repository.InsertStats `json:"inserts,omitempty"`
+}
+
+// Database handles GET /stats/database requests, the pool is shared by every
+// tenant
+func (h *Handler) Database(c echo.Context) error {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, config.ErrDBConnectionFailureHttp)
+	}
+
+	pool := sqlDB.Stats()
+	response := DatabaseStats{
+		Pool: PoolStats{
+			MaxOpen:      pool.MaxOpenConnections,
+			Open:         pool.OpenConnections,
+			InUse:        pool.InUse,
+			Idle:         pool.Idle,
+			WaitCount:    pool.WaitCount,
+			WaitDuration: float64(pool.WaitDuration.Microseconds()) / 1000,
+		},
+	}
+	if h.Inserts != nil {
+		inserts := h.Inserts.InsertStats()
+		response.Inserts = &inserts
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/stats"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testStudentsRepo repository.StudentRepository[model.StudentTest]
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, repo, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testStudentsRepo = repo
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	testDB.Migrator().DropTable(model.StudentTest{})
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
+
+func TestDatabase(t *testing.T) {
+	students := []*model.StudentTest{
+		{Student_id: uuid.New(), Student_name: "Ali", Subject: "Physics", Grade: 90},
+		{Student_id: uuid.New(), Student_name: "Omar", Subject: "Physics", Grade: 80},
+	}
+	require.NoError(t, testStudentsRepo.CreateMany(students))
+
+	t.Run("pool and inserts", func(t *testing.T) {
+		handler := stats.NewHandler(testDB, testStudentsRepo.(repository.InsertStatsReporter))
+		c, rec := testutils.NewTestContext(http.MethodGet, "/stats/database", nil)
+		require.NoError(t, handler.Database(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response stats.DatabaseStats
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Positive(t, response.Pool.Open)
+		require.NotNil(t, response.Inserts)
+		assert.EqualValues(t, 2, response.Inserts.Rows)
+		assert.EqualValues(t, 1, response.Inserts.Workers)
+	})
+
+	t.Run("repository without insert stats", func(t *testing.T) {
+		handler := stats.NewHandler(testDB, nil)
+		c, rec := testutils.NewTestContext(http.MethodGet, "/stats/database", nil)
+		require.NoError(t, handler.Database(c))
+
+		var response stats.DatabaseStats
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Nil(t, response.Inserts)
+	})
+}
@@ -0,0 +1,7 @@
+package stats
+
+import "github.com/labstack/echo/v4"
+
+type StatsHandler interface {
+	Database(c echo.Context) error
+}
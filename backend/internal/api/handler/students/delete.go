@@ -0,0 +1,40 @@
+package students
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Delete handles DELETE /students/:id requests, removing a grade row and its
+// grade record. Rows of other tenants or out of the subjects of the user
+// look like unknown rows
+func (h *Handler[T]) Delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	scoped := h.forTenant(c)
+	ids, err := scoped.Repo.Delete([]repository.QueryOption{
+		repository.WithId(id),
+		repository.WithSubjectScope(middleware.SubjectScope(c)),
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete student: "+err.Error())
+	}
+	if len(ids) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, config.ErrStudentNotFoundHttp)
+	}
+
+	if scoped.Grades != nil {
+		if _, err := scoped.Grades.Delete(ids); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete grade records: "+err.Error())
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package students
+
+import (
+	"file-uploader/config"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// StudentDTO is the JSON contract of a student record, independent from the
+// database model
+type StudentDTO struct {
+	StudentID   uuid.UUID `json:"student_id"`
+	StudentName string    `json:"student_name"`
+	Subject     string    `json:"subject"`
+	Grade       uint      `json:"grade"`
+
+	// LetterGrade is the grade under the scale of the subject, empty when unknown
+	LetterGrade string `json:"letter_grade,omitempty"`
+}
+
+// Record is a shaped student record, sparse fields plus included data
+type Record map[string]any
+
+// Includer attaches related data to records, students[i] is the source of records[i]
+type Includer func(c echo.Context, students []StudentDTO, records []Record) error
+
+const (
+	FieldStudentID   = "student_id"
+	FieldStudentName = "student_name"
+	FieldSubject     = "subject"
+	FieldGrade       = "grade"
+	FieldLetterGrade = "letter_grade"
+)
+
+var allFields = []string{FieldStudentID, FieldStudentName, FieldSubject, FieldGrade, FieldLetterGrade}
+
+// ToStudentDTO maps any student model sharing the Student columns
+func ToStudentDTO[T any](item *T) StudentDTO {
+	value := reflect.ValueOf(item).Elem()
+
+	return StudentDTO{
+		StudentID:   value.FieldByName(string(config.Id)).Interface().(uuid.UUID),
+		StudentName: value.FieldByName(string(config.Name)).String(),
+		Subject:     value.FieldByName(string(config.Subject)).String(),
+		Grade:       uint(value.FieldByName(string(config.Grade)).Uint()),
+	}
+}
+
+// Shape keeps only the requested fields
+func (d StudentDTO) Shape(fields []string) Record {
+	record := make(Record, len(fields))
+	for _, field := range fields {
+		switch field {
+		case FieldStudentID:
+			record[field] = d.StudentID
+		case FieldStudentName:
+			record[field] = d.StudentName
+		case FieldSubject:
+			record[field] = d.Subject
+		case FieldGrade:
+			record[field] = d.Grade
+		case FieldLetterGrade:
+			if d.LetterGrade != "" {
+				record[field] = d.LetterGrade
+			}
+		}
+	}
+	return record
+}
+
+// ParseFields parses a comma separated fieldset, empty means every field
+func ParseFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return allFields, nil
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+func isField(field string) bool {
+	for _, known := range allFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterInclude makes related data available through include=<name>
+func (h *Handler[T]) RegisterInclude(name string, includer Includer) {
+	h.Includes[name] = includer
+}
+
+// parseIncludes validates a comma separated list of includes
+func (h *Handler[T]) parseIncludes(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var includes []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := h.Includes[name]; !ok {
+			return nil, fmt.Errorf("unknown include %q", name)
+		}
+		includes = append(includes, name)
+	}
+	return includes, nil
+}
+
+// includeCourse attaches the catalog entry of each record's subject
+func (h *Handler[T]) includeCourse(c echo.Context, students []StudentDTO, records []Record) error {
+	courses, err := h.forTenant(c).Courses.List()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]any, len(courses))
+	for _, course := range courses {
+		byName[course.Name] = course
+	}
+
+	for i, student := range students {
+		records[i]["course"] = byName[student.Subject]
+	}
+	return nil
+}
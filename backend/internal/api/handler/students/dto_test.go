@@ -0,0 +1,116 @@
+package students_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/students"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "empty means all fields", value: "", expected: []string{"student_id", "student_name", "subject", "grade", "letter_grade"}},
+		{name: "subset keeps order", value: "grade, student_name", expected: []string{"grade", "student_name"}},
+		{name: "duplicates are dropped", value: "grade,GRADE", expected: []string{"grade"}},
+		{name: "field names are case insensitive", value: "Student_name", expected: []string{"student_name"}},
+		{name: "unknown field", value: "grade,password", wantErr: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := students.ParseFields(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fields)
+		})
+	}
+}
+
+func TestGetAllShaping(t *testing.T) {
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	handler := students.NewHandler(memoryRepo, repository.NewCourseRepository(testDB), nil, repository.NewGradingScaleRepository(testDB))
+
+	_, err := memoryRepo.Create(&model.StudentTest{Student_name: "Ali", Subject: string(config.Physics), Grade: 90})
+	require.NoError(t, err)
+
+	t.Run("sparse fieldset with include", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/students?fields=student_name,grade&include=course", nil)
+		require.NoError(t, handler.GetAll(c))
+
+		var response struct {
+			Records []map[string]any `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Records, 1)
+
+		record := response.Records[0]
+		assert.Len(t, record, 3)
+		assert.Equal(t, "Ali", record["student_name"])
+		assert.Equal(t, float64(90), record["grade"])
+		assert.Equal(t, string(config.Physics), record["course"].(map[string]any)["name"])
+	})
+
+	t.Run("default fields use snake case keys", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/students", nil)
+		require.NoError(t, handler.GetAll(c))
+
+		var response struct {
+			Records []map[string]any `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Records, 1)
+
+		for _, key := range []string{"student_id", "student_name", "subject", "grade", "letter_grade"} {
+			assert.Contains(t, response.Records[0], key)
+		}
+		assert.Equal(t, "A", response.Records[0]["letter_grade"])
+	})
+
+	t.Run("filter by letter grade", func(t *testing.T) {
+		for letters, expected := range map[string]int{"A": 1, "b,C": 0, "a,F": 1} {
+			c, rec := testutils.NewTestContext(http.MethodGet, "/students?letter="+letters, nil)
+			require.NoError(t, handler.GetAll(c))
+
+			var response struct {
+				Count int64 `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, int64(expected), response.Count, letters)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		target string
+	}{
+		{name: "unknown field", target: "/students?fields=secret"},
+		{name: "unknown include", target: "/students?include=teacher"},
+		{name: "shaping the nested view", target: "/students?view=nested&fields=grade"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testutils.NewTestContext(http.MethodGet, tt.target, nil)
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, handler.GetAll(c), &httpErr)
+			assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		})
+	}
+}
@@ -2,40 +2,40 @@ package students
 
 import (
 	"file-uploader/config"
+	"file-uploader/database/model"
 	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	expression "file-uploader/internal/service/filter"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
 type StudentsFilter struct {
-	Page      int               `query:"page"`
-	Size      int               `query:"size"`
-	SortBy    config.StudentCol `query:"sort_by"`
-	SortOrder config.SortOrder  `query:"sort_order"`
-	Name      string            `query:"name"`
-	Subject   config.Course     `query:"subject"`
+	Page       int               `query:"page"`
+	Size       int               `query:"size"`
+	SortBy     config.StudentCol `query:"sort_by"`
+	SortOrder  config.SortOrder  `query:"sort_order"`
+	Sort       string            `query:"sort"`
+	Name       string            `query:"name"`
+	Subject    config.Course     `query:"subject"`
+	View       config.View       `query:"view"`
+	Filter     string            `query:"filter"`
+	Search     string            `query:"search"`
+	SearchMode config.SearchMode `query:"search_mode"`
+	Fields     string            `query:"fields"`
+	Include    string            `query:"include"`
+	Letter     string            `query:"letter"`
 }
 
+// Page sizes unless configured otherwise
 const (
 	DefaultPageSize = 100
 	MaxPageSize     = 1000
 )
 
-// Validate subject
-var validCourses = map[config.Course]bool{
-	config.Mathematics: true,
-	config.Physics:     true,
-	config.Chemistry:   true,
-	config.Biology:     true,
-	config.History:     true,
-	config.EnglishLit:  true,
-	config.CompSci:     true,
-	config.Art:         true,
-	config.Music:       true,
-	config.Geography:   true,
-}
-
 var validSortBys = map[config.StudentCol]bool{
 	config.Id:      false,
 	config.Name:    true,
@@ -43,13 +43,34 @@ var validSortBys = map[config.StudentCol]bool{
 	config.Subject: true,
 }
 
+// Column names accepted by the sort parameter
+var sortAliases = map[string]config.StudentCol{
+	"name":         config.Name,
+	"student_name": config.Name,
+	"subject":      config.Subject,
+	"grade":        config.Grade,
+}
+
 var validSortOrders = map[config.SortOrder]bool{
 	config.SortAsc:  true,
 	config.SortDesc: true,
 }
 
+var validSearchModes = map[config.SearchMode]bool{
+	config.SearchSubstring: true,
+	config.SearchToken:     true,
+	config.SearchFuzzy:     true,
+}
+
+var validViews = map[config.View]bool{
+	config.ViewFlat:   true,
+	config.ViewNested: true,
+}
+
 // GetAll handles GET /students requests with filtering, sorting, and pagination
 func (h *Handler[T]) GetAll(c echo.Context) error {
+	h = h.forTenant(c)
+
 	var filter StudentsFilter
 	if err := c.Bind(&filter); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters: "+err.Error())
@@ -60,8 +81,8 @@ func (h *Handler[T]) GetAll(c echo.Context) error {
 		filter.Page = 1
 	}
 
-	if filter.Size <= 0 || filter.Size > MaxPageSize {
-		filter.Size = DefaultPageSize
+	if filter.Size <= 0 || filter.Size > h.Pages.MaxPageSize {
+		filter.Size = h.Pages.DefaultPageSize
 	}
 
 	// Validate sort bys
@@ -78,17 +99,93 @@ func (h *Handler[T]) GetAll(c echo.Context) error {
 		}
 	}
 
+	if filter.SearchMode == "" {
+		filter.SearchMode = config.SearchToken
+	}
+	if !validSearchModes[filter.SearchMode] {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidSearchParamHttp)
+	}
+
+	// sort replaces sort_by/sort_order, they can't be combined
+	if filter.Sort != "" && filter.SortBy != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+	}
+
+	sorts, err := ParseSorts(filter.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp+": "+err.Error())
+	}
+
+	// Validate subject against the course catalog
 	if filter.Subject != "" {
-		if !validCourses[filter.Subject] {
+		exists, err := h.Courses.Exists(string(filter.Subject))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch courses: "+err.Error())
+		}
+		if !exists {
+			return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+		}
+	}
+
+	if filter.View != "" {
+		if !validViews[filter.View] {
+			return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+		}
+	}
+
+	condition, err := expression.Parse(filter.Filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp+": "+err.Error())
+	}
+
+	fields, err := ParseFields(filter.Fields)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp+": "+err.Error())
+	}
+
+	includes, err := h.parseIncludes(filter.Include)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp+": "+err.Error())
+	}
+
+	// Letter grades need the grading scale of every course
+	var scales map[string]*model.GradingScale
+	if h.Scales != nil {
+		scales, err = h.Scales.ForCourses()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch grading scales: "+err.Error())
+		}
+	}
+
+	var letters []string
+	if filter.Letter != "" {
+		if h.Scales == nil {
 			return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
 		}
+		for _, letter := range strings.Split(filter.Letter, ",") {
+			if letter = strings.TrimSpace(letter); letter != "" {
+				letters = append(letters, letter)
+			}
+		}
+	}
+
+	if filter.View == config.ViewNested {
+		if filter.Fields != "" || len(includes) > 0 || len(letters) > 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+		}
+		return h.getNested(c, filter)
 	}
 
 	records, count, err := h.Repo.Query(
 		[]repository.QueryOption{
 			repository.WithNameFilter(filter.Name),
 			repository.WithSubject(filter.Subject),
+			repository.WithCondition(condition),
+			repository.WithSearch(filter.Search, filter.SearchMode),
+			repository.WithLetters(scales, letters...),
+			repository.WithSubjectScope(middleware.SubjectScope(c)),
 			repository.WithSort(filter.SortBy, filter.SortOrder),
+			repository.WithSorts(sorts...),
 		},
 		repository.WithPagination(filter.Page, filter.Size),
 	)
@@ -96,11 +193,94 @@ func (h *Handler[T]) GetAll(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch records: "+err.Error())
 	}
 
+	// Shape records into the API contract
+	students := make([]StudentDTO, len(records))
+	shaped := make([]Record, len(records))
+	for i, record := range records {
+		students[i] = ToStudentDTO(record)
+		if scale, ok := scales[students[i].Subject]; ok {
+			if band, ok := scale.Band(students[i].Grade); ok {
+				students[i].LetterGrade = band.Letter
+			}
+		}
+		shaped[i] = students[i].Shape(fields)
+	}
+
+	for _, include := range includes {
+		if err := h.Includes[include](c, students, shaped); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to include "+include+": "+err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count   int64    `json:"count"`
+		Records []Record `json:"records"`
+	}{
+		Count:   count,
+		Records: shaped,
+	})
+}
+
+// getNested returns one entry per student with the list of their grades
+func (h *Handler[T]) getNested(c echo.Context, filter StudentsFilter) error {
+	if h.Grades == nil || filter.Filter != "" || filter.Search != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidFilterHttp)
+	}
+
+	profiles, count, err := h.Grades.QueryNested(repository.NestedQuery{
+		Name:    filter.Name,
+		Subject: string(filter.Subject),
+		Page:    filter.Page,
+		Size:    filter.Size,
+		Scope:   middleware.SubjectScope(c),
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch records: "+err.Error())
+	}
+
 	return c.JSON(http.StatusOK, struct {
-		Count   int64 `json:"count"`
-		Records []*T  `json:"records"`
+		Count   int64                   `json:"count"`
+		Records []*model.StudentProfile `json:"records"`
 	}{
 		Count:   count,
-		Records: records,
+		Records: profiles,
 	})
 }
+
+// ParseSorts parses "subject,-grade,name" into sorts, a leading '-' sorts descending
+func ParseSorts(value string) ([]repository.Sort, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var sorts []repository.Sort
+	seen := make(map[config.StudentCol]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+
+		order := config.SortAsc
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			part = name
+			order = config.SortDesc
+		} else if name, ok := strings.CutPrefix(part, "+"); ok {
+			part = name
+		}
+
+		column, ok := sortAliases[strings.ToLower(part)]
+		if !ok {
+			column = config.StudentCol(part)
+		}
+		if !validSortBys[column] {
+			return nil, fmt.Errorf("can't sort by %q", part)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%q is sorted more than once", part)
+		}
+		seen[column] = true
+
+		sorts = append(sorts, repository.Sort{Column: column, Order: order})
+	}
+
+	return sorts, nil
+}
@@ -4,13 +4,21 @@ import (
 	"encoding/json"
 	"file-uploader/config"
 	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/students"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
 	Seeder "file-uploader/internal/service/csv/seeder"
 	testutils "file-uploader/internal/test-utils"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAll(t *testing.T) {
@@ -36,13 +44,24 @@ func TestGetAll(t *testing.T) {
 
 			// unmarshal response
 			var response struct {
-				Count   int64               `json:"count"`
-				Records []model.StudentTest `json:"records"`
+				Count   int64                 `json:"count"`
+				Records []students.StudentDTO `json:"records"`
 			}
 
 			err := json.Unmarshal(rec.Body.Bytes(), &response)
 			assert.NoError(t, err)
-			assert.Equal(t, data, response.Records)
+
+			// Seeded subjects use the default scale
+			scale, err := repository.NewGradingScaleRepository(testDB).Get(config.DefaultGradingScale)
+			require.NoError(t, err)
+
+			expected := make([]students.StudentDTO, len(data))
+			for i := range data {
+				expected[i] = students.ToStudentDTO(&data[i])
+				band, _ := scale.Band(data[i].Grade)
+				expected[i].LetterGrade = band.Letter
+			}
+			assert.Equal(t, expected, response.Records)
 			assert.Equal(t, int64(len(data)), response.Count)
 
 		}
@@ -67,6 +86,15 @@ func TestGetAll(t *testing.T) {
 				name:    "invalid filter, should pass",
 				filters: fmt.Sprintf("?iam_invalid=let_me_in"),
 			},
+			{
+				name:    "valid filter expression",
+				filters: "?filter=" + url.QueryEscape(`grade >= 50 AND subject IN (Art, "English Literature")`),
+			},
+			{
+				name:          "invalid filter expression, should return error",
+				filters:       "?filter=" + url.QueryEscape("grade >= fifty"),
+				expectedError: config.ErrInvalidFilterHttp,
+			},
 		}
 
 		for _, tt := range cases {
@@ -76,7 +104,10 @@ func TestGetAll(t *testing.T) {
 				// Call the handler
 				err := testStudentsHandler.GetAll(c)
 				if tt.expectedError != "" {
-					assert.Error(t, err)
+					var httpErr *echo.HTTPError
+					require.ErrorAs(t, err, &httpErr)
+					assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+					assert.Contains(t, httpErr.Message, tt.expectedError)
 				} else {
 					assert.NoError(t, err)
 				}
@@ -84,3 +115,145 @@ func TestGetAll(t *testing.T) {
 		}
 	})
 }
+
+func TestGetAllInMemory(t *testing.T) {
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	handler := students.NewHandler(memoryRepo, nil, nil, nil)
+
+	for _, name := range []string{"Omar", "Ali", "Saad", "Alaa"} {
+		_, err := memoryRepo.Create(&model.StudentTest{Student_name: name, Subject: string(config.Art), Grade: 50})
+		require.NoError(t, err)
+	}
+
+	c, rec := testutils.NewTestContext(http.MethodGet, "/students?name=al&sort_by=Student_name&sort_order=desc", nil)
+	require.NoError(t, handler.GetAll(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response struct {
+		Count   int64                 `json:"count"`
+		Records []students.StudentDTO `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	assert.Equal(t, int64(2), response.Count)
+	assert.Equal(t, "Ali", response.Records[0].StudentName)
+	assert.Equal(t, "Alaa", response.Records[1].StudentName)
+}
+
+func TestGetAllScoped(t *testing.T) {
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	handler := students.NewHandler(memoryRepo, nil, nil, nil)
+
+	for _, student := range []model.StudentTest{
+		{Student_name: "Ali", Subject: string(config.Physics), Grade: 90},
+		{Student_name: "Alaa", Subject: string(config.Chemistry), Grade: 80},
+		{Student_name: "Omar", Subject: string(config.Art), Grade: 70},
+	} {
+		_, err := memoryRepo.Create(&student)
+		require.NoError(t, err)
+	}
+
+	teacher := &auth.Principal{
+		User:     &model.User{Role: config.RoleViewer},
+		Subjects: []string{string(config.Physics), string(config.Chemistry)},
+	}
+	admin := &auth.Principal{
+		User:     &model.User{Role: config.RoleAdmin},
+		Subjects: []string{string(config.Physics)},
+	}
+
+	cases := []struct {
+		name      string
+		principal *auth.Principal
+		path      string
+		expected  []string
+	}{
+		{
+			name:      "teacher sees assigned subjects",
+			principal: teacher,
+			path:      "/students?sort=name",
+			expected:  []string{"Alaa", "Ali"},
+		},
+		{
+			name:      "teacher filtering on another subject",
+			principal: teacher,
+			path:      "/students?filter=subject%20%3D%20Art",
+			expected:  []string{},
+		},
+		{
+			name:      "admins are never scoped",
+			principal: admin,
+			path:      "/students?sort=name",
+			expected:  []string{"Alaa", "Ali", "Omar"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := testutils.NewTestContext(http.MethodGet, tt.path, nil)
+			c.Set(middleware.PrincipalKey, tt.principal)
+			require.NoError(t, handler.GetAll(c))
+
+			var response struct {
+				Records []students.StudentDTO `json:"records"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+			names := []string{}
+			for _, record := range response.Records {
+				names = append(names, record.StudentName)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	t.Run("suggestions are scoped", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/students/suggest?q=omar", nil)
+		c.Set(middleware.PrincipalKey, teacher)
+		require.NoError(t, handler.Suggest(c))
+
+		var response struct {
+			Suggestions []string `json:"suggestions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Empty(t, response.Suggestions)
+	})
+}
+
+func TestGetAllTenantIsolation(t *testing.T) {
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	handler := students.NewHandler(memoryRepo, nil, nil, nil)
+
+	north, south := uuid.New(), uuid.New()
+	_, err := memoryRepo.ForTenant(north).Create(&model.StudentTest{Student_name: "Ali", Subject: string(config.Physics), Grade: 90})
+	require.NoError(t, err)
+	_, err = memoryRepo.ForTenant(south).Create(&model.StudentTest{Student_name: "Omar", Subject: string(config.Physics), Grade: 80})
+	require.NoError(t, err)
+
+	for tenantID, expected := range map[uuid.UUID]string{north: "Ali", south: "Omar"} {
+		principal := &auth.Principal{User: &model.User{TenantID: tenantID, Role: config.RoleAdmin}}
+
+		c, rec := testutils.NewTestContext(http.MethodGet, "/students", nil)
+		c.Set(middleware.PrincipalKey, principal)
+		require.NoError(t, handler.GetAll(c))
+
+		var response struct {
+			Count   int64                 `json:"count"`
+			Records []students.StudentDTO `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Equal(t, int64(1), response.Count)
+		assert.Equal(t, expected, response.Records[0].StudentName)
+
+		// Searching for the other tenant's student finds nothing
+		c, rec = testutils.NewTestContext(http.MethodGet, "/students/suggest?q=ali%20omar", nil)
+		c.Set(middleware.PrincipalKey, principal)
+		require.NoError(t, handler.Suggest(c))
+
+		var suggestions struct {
+			Suggestions []string `json:"suggestions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &suggestions))
+		assert.Subset(t, []string{expected}, suggestions.Suggestions)
+	}
+}
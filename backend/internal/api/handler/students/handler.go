@@ -1,13 +1,71 @@
 package students
 
-import "file-uploader/database/repository"
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+
+	"github.com/labstack/echo/v4"
+)
 
 type Handler[T any] struct {
-	Repo repository.StudentRepository[T]
+	Repo    repository.StudentRepository[T]
+	Courses repository.CourseRepository
+	Grades  repository.GradeRepository
+	Scales  repository.GradingScaleRepository
+
+	// Includes are the related data available through include=
+	Includes map[string]Includer
+
+	// Pages bounds the size of a page of students
+	Pages config.Query
+}
+
+// Option tunes a students handler
+type Option func(*config.Query)
+
+// WithPageSizes sets the page size used when none or a too large one is
+// asked for, and the largest page
+func WithPageSizes(defaultSize, maxSize int) Option {
+	return func(pages *config.Query) {
+		pages.DefaultPageSize = defaultSize
+		pages.MaxPageSize = maxSize
+	}
 }
 
-func NewHandler[T any](repo repository.StudentRepository[T]) StudentsHandler {
-	return &Handler[T]{
-		Repo: repo,
+func NewHandler[T any](
+	repo repository.StudentRepository[T],
+	courses repository.CourseRepository,
+	grades repository.GradeRepository,
+	scales repository.GradingScaleRepository,
+	opts ...Option,
+) StudentsHandler {
+	h := &Handler[T]{
+		Repo:     repo,
+		Courses:  courses,
+		Grades:   grades,
+		Scales:   scales,
+		Includes: make(map[string]Includer),
+		Pages:    config.Query{DefaultPageSize: DefaultPageSize, MaxPageSize: MaxPageSize},
 	}
+	for _, opt := range opts {
+		opt(&h.Pages)
+	}
+
+	if courses != nil {
+		h.RegisterInclude("course", h.includeCourse)
+	}
+	return h
+}
+
+// forTenant returns the handler with repositories scoped to the tenant of the request
+func (h *Handler[T]) forTenant(c echo.Context) *Handler[T] {
+	tenantID := middleware.TenantID(c)
+
+	scoped := *h
+	scoped.Repo = repository.Scoped(h.Repo, tenantID)
+	scoped.Courses = repository.Scoped(h.Courses, tenantID)
+	scoped.Grades = repository.Scoped(h.Grades, tenantID)
+	scoped.Scales = repository.Scoped(h.Scales, tenantID)
+	return &scoped
 }
@@ -4,4 +4,6 @@ import "github.com/labstack/echo/v4"
 
 type StudentsHandler interface {
 	GetAll(c echo.Context) error
+	Suggest(c echo.Context) error
+	Delete(c echo.Context) error
 }
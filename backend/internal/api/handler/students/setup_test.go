@@ -29,7 +29,7 @@ func TestMain(m *testing.M) {
 	}
 
 	testDB = db
-	handler := students.NewHandler[model.StudentTest](repo)
+	handler := students.NewHandler[model.StudentTest](repo, repository.NewCourseRepository(db), repository.NewGradeRepository(db), repository.NewGradingScaleRepository(db))
 	testStudentsHandler = handler
 	testStudentsRepo = repo
 	// Run tests
@@ -39,8 +39,7 @@ func TestMain(m *testing.M) {
 	testDB.Migrator().DropTable(model.StudentTest{})
 
 	// Cleanup
-	sqlDB, _ := testDB.DB()
-	sqlDB.Close()
+	testutils.CloseDb(testDB)
 
 	os.Exit(code)
 }
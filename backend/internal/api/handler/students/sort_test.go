@@ -0,0 +1,46 @@
+package students_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/students"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSorts(t *testing.T) {
+	t.Run("multiple columns with directions", func(t *testing.T) {
+		sorts, err := students.ParseSorts("subject,-grade, name")
+		require.NoError(t, err)
+		assert.Equal(t, []repository.Sort{
+			{Column: config.Subject, Order: config.SortAsc},
+			{Column: config.Grade, Order: config.SortDesc},
+			{Column: config.Name, Order: config.SortAsc},
+		}, sorts)
+	})
+
+	t.Run("column constants are accepted", func(t *testing.T) {
+		sorts, err := students.ParseSorts("-Student_name")
+		require.NoError(t, err)
+		assert.Equal(t, []repository.Sort{{Column: config.Name, Order: config.SortDesc}}, sorts)
+	})
+
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{name: "unknown column", value: "subject,password"},
+		{name: "id is not sortable", value: "student_id"},
+		{name: "duplicate column", value: "grade,-grade"},
+		{name: "empty column", value: "grade,,name"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name+", should return error", func(t *testing.T) {
+			_, err := students.ParseSorts(tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package students
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+)
+
+type SuggestFilter struct {
+	Query string `query:"q"`
+	Limit int    `query:"limit"`
+}
+
+// Suggest handles GET /students/suggest requests, returning names for typeahead
+func (h *Handler[T]) Suggest(c echo.Context) error {
+	var filter SuggestFilter
+	if err := c.Bind(&filter); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+	}
+
+	if strings.TrimSpace(filter.Query) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingSearchParamHttp)
+	}
+
+	if filter.Limit <= 0 || filter.Limit > MaxSuggestLimit {
+		filter.Limit = DefaultSuggestLimit
+	}
+
+	names, err := h.forTenant(c).Repo.Suggest(filter.Query, filter.Limit, repository.WithSubjectScope(middleware.SubjectScope(c)))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch suggestions: "+err.Error())
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Suggestions []string `json:"suggestions"`
+	}{
+		Suggestions: names,
+	})
+}
@@ -0,0 +1,55 @@
+package students_test
+
+import (
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/students"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	handler := students.NewHandler(memoryRepo, nil, nil, nil)
+
+	for _, name := range []string{"James Hutchinson", "James Hutchinson", "Hutch", "Omar"} {
+		_, err := memoryRepo.Create(&model.StudentTest{Student_name: name, Subject: string(config.Art), Grade: 50})
+		require.NoError(t, err)
+	}
+
+	t.Run("distinct names ranked by relevance", func(t *testing.T) {
+		c, rec := testutils.NewTestContext(http.MethodGet, "/students/suggest?q=hutch", nil)
+		require.NoError(t, handler.Suggest(c))
+
+		var response struct {
+			Suggestions []string `json:"suggestions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, []string{"Hutch", "James Hutchinson"}, response.Suggestions)
+	})
+
+	t.Run("missing query, should return error", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodGet, "/students/suggest", nil)
+
+		err := handler.Suggest(c)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("invalid search mode, should return error", func(t *testing.T) {
+		c, _ := testutils.NewTestContext(http.MethodGet, "/students?search=hutch&search_mode=regex", nil)
+
+		err := handler.GetAll(c)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
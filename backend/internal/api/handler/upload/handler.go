@@ -7,13 +7,18 @@ import (
 	"file-uploader/config"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
 	processor "file-uploader/internal/service/csv"
+	"file-uploader/internal/service/jobs"
+	"file-uploader/internal/service/metrics"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -21,15 +26,55 @@ import (
 )
 
 type UploadHandler struct {
-	repo           *repository.StudentRepository[model.Student]
-	statusChannels map[uuid.UUID]chan processor.ProcessStatus
-	mu             sync.Mutex
+	repo    *repository.StudentRepository[model.Student]
+	courses repository.CourseRepository
+	grades  repository.GradeRepository
+	imports repository.ImportJobRepository
+	limits  config.Upload
+	jobs    *jobs.Queue
+
+	// importing tunes the processing of the spooled files
+	importing config.Import
+
+	// spool holds a directory of files per upload until it is imported
+	spool string
+
+	// uploads keeps the progress of queued and recent uploads
+	uploads map[uuid.UUID]*progress
+	mu      sync.Mutex
+
+	// Metrics count the uploads and their watchers, nil counts nothing
+	Metrics *metrics.Metrics
 }
 
-func NewUploadHandler(repo *repository.StudentRepository[model.Student]) *UploadHandler {
+// progressRetention is how long the statuses of a finished upload can be read
+const progressRetention = 10 * time.Minute
+
+func NewUploadHandler(
+	repo *repository.StudentRepository[model.Student],
+	courses repository.CourseRepository,
+	grades repository.GradeRepository,
+	imports repository.ImportJobRepository,
+	limits config.Upload,
+	importing config.Import,
+	queue *jobs.Queue,
+) *UploadHandler {
+	spool := limits.SpoolDir
+	if spool == "" {
+		spool = filepath.Join(os.TempDir(), "file-uploader-spool")
+	}
+
 	return &UploadHandler{
-		repo:           repo,
-		statusChannels: make(map[uuid.UUID]chan processor.ProcessStatus),
+		repo:    repo,
+		courses: courses,
+		grades:  grades,
+		imports: imports,
+		limits:  limits,
+		jobs:    queue,
+		spool:   spool,
+		uploads: make(map[uuid.UUID]*progress),
+
+		importing: importing,
 	}
 }
 
@@ -38,28 +83,82 @@ func (uh *UploadHandler) HandleFileUpload(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	uploadID := uuid.New()
+	tenantID := middleware.TenantID(c)
 
-	// Create status channel for this upload
-	uh.mu.Lock()
-	statusChan := make(chan processor.ProcessStatus)
-	uh.statusChannels[uploadID] = statusChan
-	uh.mu.Unlock()
+	// Reject oversized bodies before reading them when the size is known
+	if limit := uh.limits.MaxBodyBytes; limit > 0 {
+		if c.Request().ContentLength > limit {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, config.ErrBodyTooLargeHttp)
+		}
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, limit)
+	}
 
 	// Extract necessary data from the request
 	form, err := c.MultipartForm()
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, config.ErrBodyTooLargeHttp)
+		}
 		return echo.NewHTTPError(http.StatusBadRequest, config.ErrFormParseFailureHttp)
 	}
+	defer form.RemoveAll()
 
 	files := form.File["files"]
 	if len(files) == 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, config.ErrNoFilesProvidedHttp)
 	}
+	if uh.limits.MaxFiles > 0 && len(files) > uh.limits.MaxFiles {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, config.ErrTooManyFilesHttp)
+	}
+	for _, fh := range files {
+		if uh.limits.MaxFileBytes > 0 && fh.Size > uh.limits.MaxFileBytes {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, config.ErrFileTooLargeHttp+": "+fh.Filename)
+		}
+	}
+
+	// Optional term the grades belong to, e.g. "2024-fall"
+	term := c.FormValue("term")
+
+	// Scoped uploaders may only import their own subjects
+	scope := middleware.SubjectScope(c)
+
+	// Jumping the queue is reserved to admins
+	priority, err := jobs.ParsePriority(c.FormValue("priority"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrInvalidPriorityHttp)
+	}
+	if principal := middleware.Principal(c); priority == jobs.PriorityHigh && (principal == nil || !principal.IsAdmin()) {
+		return echo.NewHTTPError(http.StatusForbidden, config.ErrForbiddenHttp)
+	}
+
+	// Spool the files, they are removed here unless the job takes them
+	dir := uh.spoolDir(uploadID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-	// Save templ files
 	var tempFiles []*os.File
+	queued := false
+	defer func() {
+		for _, f := range tempFiles {
+			f.Close()
+		}
+		if !queued {
+			os.RemoveAll(dir)
+		}
+	}()
 
-	for _, fh := range files {
+	job := &model.ImportJob{
+		ID:       uploadID,
+		TenantID: tenantID,
+		Status:   config.ImportQueued,
+		Priority: int(priority),
+		Term:     term,
+		Scope:    scope,
+	}
+
+	for i, fh := range files {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -72,52 +171,51 @@ func (uh *UploadHandler) HandleFileUpload(c echo.Context) error {
 		}
 		defer src.Close()
 
-		tmp, err := os.CreateTemp("", "upload-*.csv")
+		tmp, err := os.Create(filepath.Join(dir, fmt.Sprintf("%d.csv", i)))
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
+		tempFiles = append(tempFiles, tmp)
+		job.Files = append(job.Files, &model.ImportFile{Position: i, Path: tmp.Name()})
 
 		if _, err := io.Copy(tmp, src); err != nil {
-			tmp.Close()
-			os.Remove(tmp.Name())
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 
 		tmp.Seek(0, io.SeekStart)
-		tempFiles = append(tempFiles, tmp)
 	}
 
-	// Process file in the background
-	go func(tempFiles []*os.File, uploadID uuid.UUID) {
-		// Create a new background context that won't be canceled when the HTTP request ends
-		bgCtx := context.Background()
-
-		// Reclean temp files to ensure they are closed and removed
-		defer func() {
-			for _, f := range tempFiles {
-				f.Close()
-				os.Remove(f.Name())
-			}
-		}()
-
-		err = ValidateCSVFiles(tempFiles)
-		if err != nil {
-			statusChan <- processor.ProcessStatus{Error: err.Error()}
-			return
-		}
-
-		err = ValidateCSVHeader(tempFiles)
-		if err != nil {
-			statusChan <- processor.ProcessStatus{Error: err.Error()}
-			return
-		}
+	err = ValidateCSVRows(tempFiles, uh.limits.MaxRows)
+	if errors.Is(err, config.ErrTooManyRows) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, config.ErrTooManyRowsHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 
-		ProcessFiles(bgCtx, tempFiles, uh.statusChannels[uploadID], *uh.repo, processor.StudentMapper)
+	// Record the job so it survives a restart, then queue it
+	if err := uh.imports.Create(job); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to record upload: "+err.Error())
+	}
 
-	}(tempFiles, uploadID)
+	position, err := uh.enqueue(job, false)
+	if err != nil {
+		uh.imports.Delete(job.ID)
+	}
+	if errors.Is(err, config.ErrQueueFull) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, config.ErrUploadQueueFullHttp)
+	}
+	if errors.Is(err, config.ErrQueueClosed) {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, config.ErrShuttingDownHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	queued = true
 
-	return c.JSON(http.StatusOK, map[string]string{
+	return c.JSON(http.StatusOK, map[string]any{
 		"upload_id": uploadID.String(),
+		"position":  position,
 	})
 }
 
@@ -135,13 +233,20 @@ func (uh *UploadHandler) HandleStatusUpdates(c echo.Context) error {
 	defer ws.Close()
 
 	uh.mu.Lock()
-	statusChan, exists := uh.statusChannels[uploadID]
+	tracker, exists := uh.uploads[uploadID]
 	uh.mu.Unlock()
 
+	// Uploads of other tenants look like unknown uploads
+	if exists && tracker.tenant != middleware.TenantID(c) {
+		exists = false
+	}
+
 	if !exists {
 		return echo.NewHTTPError(http.StatusNotFound, "upload ID not found")
 	}
 
+	defer uh.Metrics.WatcherConnected()()
+
 	// Send an initial ping so the client sees activity
 	if err := ws.WriteJSON(processor.ProcessStatus{Percent: 0}); err != nil {
 		return nil
@@ -160,37 +265,29 @@ func (uh *UploadHandler) HandleStatusUpdates(c echo.Context) error {
 		}
 	}()
 
-	processComplete := false
+	// Replay the statuses so far, then follow until processing is complete.
+	// Processing never waits on the socket, a slow or gone client only
+	// stops its own updates
+	sent := 0
 	for {
-		select {
-		case status, ok := <-statusChan:
-			if !ok {
-				// Channel closed, processing is complete
-				processComplete = true
-				goto cleanup
+		statuses, done, changed := tracker.since(sent)
+		for _, status := range statuses {
+			if err := ws.WriteJSON(status); err != nil {
+				return nil
 			}
+		}
+		sent += len(statuses)
 
-			// Try to send the status update
-			err := ws.WriteJSON(status)
-			if err != nil {
-				// The connection might be closed, but we'll keep processing
-				goto cleanup
-			}
+		if done {
+			return nil
+		}
 
+		select {
+		case <-changed:
 		case <-clientClosed:
-			// The client disconnected, but we don't mark processing as complete
-			goto cleanup
+			return nil
 		}
 	}
-
-cleanup:
-	// Only clean up the channel if processing is complete (to avoid breaking other listeners)
-	if processComplete {
-		uh.mu.Lock()
-		delete(uh.statusChannels, uploadID)
-		uh.mu.Unlock()
-	}
-	return nil
 }
 
 func ProcessFiles[T any](
@@ -199,14 +296,12 @@ func ProcessFiles[T any](
 	statusChannel chan processor.ProcessStatus,
 	studentRepo repository.StudentRepository[T],
 	mapper func([]string) (*T, error),
+	settings config.Import,
+	opts ...processor.ProcessOption,
 ) {
 	defer close(statusChannel)
-	const (
-		batchSize             = 2000
-		maxNumberOfGoRoutines = 10
-	)
 
-	var sem = make(chan struct{}, min(maxNumberOfGoRoutines, len(files))) // limit number of goroutines
+	var sem = make(chan struct{}, max(min(settings.FileWorkers, len(files)), 1)) // limit number of goroutines
 
 	var wg sync.WaitGroup
 
@@ -229,10 +324,11 @@ func ProcessFiles[T any](
 				i,
 				file,
 				fileInfo.Size(),
-				batchSize,
+				settings.BatchSize,
 				studentRepo,
 				mapper,
 				statusChannel,
+				append([]processor.ProcessOption{processor.WithInsertWorkers(settings.InsertWorkers)}, opts...)...,
 			)
 			if err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 				statusChannel <- processor.ProcessStatus{Id: i, Percent: 0, Error: fmt.Sprintf("Processing failed: %v", err)}
@@ -271,3 +367,54 @@ func ValidateCSVHeader(files []*os.File) error {
 	}
 	return nil
 }
+
+// ValidateCSVSubjects rejects files referencing subjects missing from the course catalog
+func ValidateCSVSubjects(files []*os.File, catalog map[string]bool) error {
+	return validateSubjects(files, catalog, config.ErrUnknownCourse)
+}
+
+// ValidateCSVScope checks every row is in one of the subjects, nil allows
+// any and no subjects none
+func ValidateCSVScope(files []*os.File, subjects []string) error {
+	if subjects == nil {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		allowed[subject] = true
+	}
+	return validateSubjects(files, allowed, config.ErrSubjectNotAssigned)
+}
+
+func validateSubjects(files []*os.File, allowed map[string]bool, rejected error) error {
+	const subjectCol = 2
+
+	for _, f := range files {
+		f.Seek(0, io.SeekStart)
+		reader := csv.NewReader(f)
+
+		// Skip header
+		if _, err := reader.Read(); err != nil {
+			return err
+		}
+
+		line := 1
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			line++
+
+			if len(record) <= subjectCol || !allowed[record[subjectCol]] {
+				return fmt.Errorf("%w at line %d", rejected, line)
+			}
+		}
+		f.Seek(0, io.SeekStart)
+	}
+	return nil
+}
@@ -1,18 +1,32 @@
 package upload_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"file-uploader/config"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
 	"file-uploader/internal/api/handler/upload"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
 	processor "file-uploader/internal/service/csv"
+	"file-uploader/internal/service/jobs"
 	testutils "file-uploader/internal/test-utils"
+	"fmt"
 	"io"
 	"log"
+	"mime/multipart"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -42,8 +56,7 @@ func TestMain(m *testing.M) {
 	testDB.Migrator().DropTable(model.StudentTest{})
 
 	// Cleanup
-	sqlDB, _ := testDB.DB()
-	sqlDB.Close()
+	testutils.CloseDb(testDB)
 
 	os.Exit(code)
 }
@@ -66,8 +79,10 @@ func TestUploadFilesHandler(t *testing.T) {
 
 			// Call the handler
 			statusChan := make(chan processor.ProcessStatus)
+			consumed := make(chan struct{})
 			// Start a goroutine to consume the messages from the channel
 			go func() {
+				defer close(consumed)
 				for status := range statusChan {
 					// Just consume the messages - we don't need to do anything with them in this test
 					// Optionally log them if you want to see what's happening
@@ -94,7 +109,8 @@ func TestUploadFilesHandler(t *testing.T) {
 				files = append(files, tmpFile)
 			}
 
-			upload.ProcessFiles(ctxTest, files, statusChan, studentRepo, processor.StudentTestMapper)
+			upload.ProcessFiles(ctxTest, files, statusChan, studentRepo, processor.StudentTestMapper, config.Default().Import)
+			<-consumed
 
 			// Check that files were processed and data is in the DB
 			students, _, err := studentRepo.Query([]repository.QueryOption{}, nil)
@@ -150,6 +166,50 @@ func TestValidations(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid CSV header")
 	})
+
+	t.Run("validate subjects", func(t *testing.T) {
+		// Create a file with a subject missing from the catalog
+		unknownSubjectFile, err := os.CreateTemp("", "unknown-subject-*.csv")
+		require.NoError(t, err)
+		defer os.Remove(unknownSubjectFile.Name())
+
+		_, err = unknownSubjectFile.WriteString(config.StudentsTableHeader + "\n" +
+			uuid.NewString() + ",Ali,Physics,90\n" +
+			uuid.NewString() + ",Omar,Economics,80\n")
+		require.NoError(t, err)
+
+		catalog := map[string]bool{string(config.Physics): true}
+
+		err = upload.ValidateCSVSubjects([]*os.File{unknownSubjectFile}, catalog)
+		assert.ErrorIs(t, err, config.ErrUnknownCourse)
+		assert.Contains(t, err.Error(), "line 3")
+
+		catalog["Economics"] = true
+		err = upload.ValidateCSVSubjects([]*os.File{unknownSubjectFile}, catalog)
+		assert.NoError(t, err)
+	})
+
+	t.Run("validate subject scope", func(t *testing.T) {
+		scopedFile, err := os.CreateTemp("", "scoped-*.csv")
+		require.NoError(t, err)
+		defer os.Remove(scopedFile.Name())
+
+		_, err = scopedFile.WriteString(config.StudentsTableHeader + "\n" +
+			uuid.NewString() + ",Ali,Physics,90\n" +
+			uuid.NewString() + ",Omar,Chemistry,80\n")
+		require.NoError(t, err)
+
+		err = upload.ValidateCSVScope([]*os.File{scopedFile}, []string{string(config.Physics)})
+		assert.ErrorIs(t, err, config.ErrSubjectNotAssigned)
+		assert.Contains(t, err.Error(), "line 3")
+
+		err = upload.ValidateCSVScope([]*os.File{scopedFile}, []string{string(config.Physics), string(config.Chemistry)})
+		assert.NoError(t, err)
+
+		// Unscoped uploaders may import any subject
+		err = upload.ValidateCSVScope([]*os.File{scopedFile}, nil)
+		assert.NoError(t, err)
+	})
 }
 
 func TestUploadHandlerWithValidations(t *testing.T) {
@@ -200,3 +260,230 @@ func TestUploadHandlerWithValidations(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid CSV header")
 	})
 }
+
+// multipartUpload builds an upload request with one file per content
+func multipartUpload(t *testing.T, contents ...string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for i, content := range contents {
+		part, err := writer.CreateFormFile("files", fmt.Sprintf("class%d.csv", i))
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestUploadLimits(t *testing.T) {
+	csvFile := func(rows int) string {
+		content := config.StudentsTableHeader + "\n"
+		for range rows {
+			content += uuid.NewString() + ",Ali,Physics,90\n"
+		}
+		return content
+	}
+
+	imports := repository.NewImportJobRepository(testDB)
+
+	limits := config.Upload{
+		MaxBodyBytes:  4 << 10,
+		MaxFileBytes:  1 << 10,
+		MaxFiles:      2,
+		MaxRows:       5,
+		MaxConcurrent: 1,
+	}
+
+	cases := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{
+			name:     "too many files",
+			files:    []string{csvFile(1), csvFile(1), csvFile(1)},
+			expected: config.ErrTooManyFilesHttp,
+		},
+		{
+			name:     "file too large",
+			files:    []string{csvFile(20)},
+			expected: config.ErrFileTooLargeHttp,
+		},
+		{
+			name:     "body too large",
+			files:    []string{strings.Repeat("x", 5<<10)},
+			expected: config.ErrBodyTooLargeHttp,
+		},
+		{
+			name:     "too many rows",
+			files:    []string{csvFile(1), csvFile(6)},
+			expected: config.ErrTooManyRowsHttp,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := upload.NewUploadHandler(nil, nil, nil, imports, withSpool(t, limits), config.Import{}, jobs.NewQueue(1, 1))
+
+			body, contentType := multipartUpload(t, tt.files...)
+			c, _ := testutils.NewTestContext(http.MethodPost, "/upload", body)
+			c.Request().Header.Set(echo.HeaderContentType, contentType)
+
+			err := handler.HandleFileUpload(c)
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, httpErr.Code)
+			assert.Contains(t, httpErr.Message, tt.expected)
+		})
+	}
+
+	t.Run("full queue", func(t *testing.T) {
+		queue := jobs.NewQueue(1, 0)
+		handler := upload.NewUploadHandler(nil, nil, nil, imports, withSpool(t, config.Upload{MaxConcurrent: 1}), config.Import{}, queue)
+
+		// Keep the only worker busy
+		started, release := make(chan struct{}), make(chan struct{})
+		_, err := queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+			close(started)
+			<-release
+		}})
+		require.NoError(t, err)
+		<-started
+
+		upload := func() error {
+			body, contentType := multipartUpload(t, csvFile(1))
+			c, _ := testutils.NewTestContext(http.MethodPost, "/upload", body)
+			c.Request().Header.Set(echo.HeaderContentType, contentType)
+			return handler.HandleFileUpload(c)
+		}
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, upload(), &httpErr)
+		assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
+
+		close(release)
+		require.NoError(t, queue.Shutdown(context.Background()))
+
+		require.ErrorAs(t, upload(), &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Code)
+	})
+
+	t.Run("high priority is reserved to admins", func(t *testing.T) {
+		handler := upload.NewUploadHandler(nil, nil, nil, imports, withSpool(t, limits), config.Import{}, jobs.NewQueue(1, 1))
+
+		body, contentType := multipartUpload(t, csvFile(1))
+		c, _ := testutils.NewTestContext(http.MethodPost, "/upload?priority=high", body)
+		c.Request().Header.Set(echo.HeaderContentType, contentType)
+		c.Set(middleware.PrincipalKey, &auth.Principal{User: &model.User{Role: config.RoleUploader}})
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, handler.HandleFileUpload(c), &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	})
+}
+
+// withSpool gives the upload settings a spool of their own
+func withSpool(t *testing.T, limits config.Upload) config.Upload {
+	limits.SpoolDir = t.TempDir()
+	return limits
+}
+
+// newImportHandler builds an upload handler importing into the flat students
+func newImportHandler(t *testing.T, queue *jobs.Queue, spool string) *upload.UploadHandler {
+	students := repository.NewStudentRepository[model.Student](testDB)
+	settings := config.Default()
+	settings.Upload.SpoolDir = spool
+
+	return upload.NewUploadHandler(
+		&students,
+		repository.NewCourseRepository(testDB),
+		repository.NewGradeRepository(testDB),
+		repository.NewImportJobRepository(testDB),
+		settings.Upload,
+		settings.Import,
+		queue,
+	)
+}
+
+func TestInterruptAndResume(t *testing.T) {
+	imports := repository.NewImportJobRepository(testDB)
+	spool := t.TempDir()
+
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	var content strings.Builder
+	content.WriteString(config.StudentsTableHeader + "\n")
+	for i, id := range ids {
+		fmt.Fprintf(&content, "%s,Resumed %d,Physics,%d\n", id, i, 70+i)
+	}
+
+	// Keep the only worker busy until the shutdown deadline
+	queue := jobs.NewQueue(1, 1)
+	started := make(chan struct{})
+	_, err := queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	}})
+	require.NoError(t, err)
+	<-started
+
+	handler := newImportHandler(t, queue, spool)
+
+	body, contentType := multipartUpload(t, content.String())
+	c, rec := testutils.NewTestContext(http.MethodPost, "/upload", body)
+	c.Request().Header.Set(echo.HeaderContentType, contentType)
+	require.NoError(t, handler.HandleFileUpload(c))
+
+	var response struct {
+		UploadID uuid.UUID `json:"upload_id"`
+		Position int       `json:"position"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Position)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+
+	// The waiting upload is kept for the next start
+	job, err := imports.Get(response.UploadID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportInterrupted, job.Status)
+	require.Len(t, job.Files, 1)
+	assert.FileExists(t, job.Files[0].Path)
+
+	// Pretend the first record was stored before the interruption, and the
+	// second one was stored by a crash before its checkpoint. Leave a spooled
+	// upload no job owns
+	require.NoError(t, imports.Checkpoint(job.ID, 0, 1))
+	require.NoError(t, imports.Dispatch(job.ID, 0, 2))
+	_, err = repository.NewStudentRepository[model.Student](testDB).
+		Create(&model.Student{Student_id: ids[1], Student_name: "Resumed 1", Subject: string(config.Physics), Grade: 71})
+	require.NoError(t, err)
+	orphan := filepath.Join(spool, uuid.NewString())
+	require.NoError(t, os.MkdirAll(orphan, 0o700))
+
+	queue = jobs.NewQueue(1, 0)
+	resumed, err := newImportHandler(t, queue, spool).Resume()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, resumed, 1)
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	job, err = imports.Get(response.UploadID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportCompleted, job.Status, job.Error)
+	assert.Equal(t, int64(3), job.Files[0].RowsCommitted)
+	assert.NoDirExists(t, filepath.Join(spool, job.ID.String()))
+	assert.NoDirExists(t, orphan)
+
+	// Only the records after the checkpoint were imported, the stored one
+	// once
+	var names []string
+	require.NoError(t, testDB.Model(&model.Student{}).Where("student_name LIKE ?", "Resumed %").
+		Order("student_name").Pluck("student_name", &names).Error)
+	assert.Equal(t, []string{"Resumed 1", "Resumed 2"}, names)
+
+	// Rows record the upload that stored them
+	var imported []uuid.UUID
+	require.NoError(t, testDB.Model(&model.Student{}).Where("import_id = ?", job.ID).Pluck("student_id", &imported).Error)
+	assert.Equal(t, []uuid.UUID{ids[2]}, imported)
+}
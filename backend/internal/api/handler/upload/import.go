@@ -0,0 +1,212 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	processor "file-uploader/internal/service/csv"
+	"file-uploader/internal/service/jobs"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (uh *UploadHandler) spoolDir(uploadID uuid.UUID) string {
+	return filepath.Join(uh.spool, uploadID.String())
+}
+
+// enqueue submits a recorded job, resumed jobs were accepted before the
+// restart and skip the capacity check
+func (uh *UploadHandler) enqueue(job *model.ImportJob, resumed bool) (int, error) {
+	tracker := newProgress(job.TenantID)
+	uh.mu.Lock()
+	uh.uploads[job.ID] = tracker
+	uh.mu.Unlock()
+
+	queued := &jobs.Job{
+		ID:       job.ID,
+		Priority: jobs.Priority(job.Priority),
+		Waiting:  tracker.queued,
+		Run: func(ctx context.Context) {
+			defer uh.forget(job.ID)
+			uh.process(ctx, job, tracker)
+		},
+	}
+
+	var position int
+	var err error
+	if resumed {
+		position, err = uh.jobs.Requeue(queued)
+	} else {
+		position, err = uh.jobs.Submit(queued)
+	}
+	if err != nil {
+		uh.mu.Lock()
+		delete(uh.uploads, job.ID)
+		uh.mu.Unlock()
+	}
+	return position, err
+}
+
+// process validates and imports the files of a job. The spooled files are
+// removed unless the job is interrupted, a resumed job skips the records
+// committed before
+func (uh *UploadHandler) process(ctx context.Context, job *model.ImportJob, tracker *progress) {
+	interrupted := false
+	defer func() {
+		if !interrupted {
+			os.RemoveAll(uh.spoolDir(job.ID))
+		}
+	}()
+	defer tracker.finish()
+
+	fail := func(err error) {
+		tracker.publish(processor.ProcessStatus{Error: err.Error()})
+		uh.setStatus(job.ID, config.ImportFailed, err.Error())
+		uh.Metrics.UploadFailed()
+	}
+	interrupt := func() {
+		interrupted = true
+		tracker.publish(processor.ProcessStatus{Error: config.ErrImportInterruptedHttp})
+		uh.setStatus(job.ID, config.ImportInterrupted, "")
+	}
+
+	// Jobs still waiting at shutdown run canceled
+	if ctx.Err() != nil {
+		interrupt()
+		return
+	}
+	uh.setStatus(job.ID, config.ImportRunning, "")
+	uh.Metrics.UploadStarted()
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, file := range job.Files {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			fail(err)
+			return
+		}
+		files = append(files, f)
+	}
+
+	scope := job.Scope
+	if job.AllSubjects {
+		scope = nil
+	}
+
+	// Rows record the upload storing them so it can be rolled back
+	pipeline := uh.pipeline()
+	pipeline.Students = pipeline.Students.ForImport(job.ID)
+	if err := pipeline.Validate(files, job.TenantID, scope); err != nil {
+		fail(err)
+		return
+	}
+
+	committed := make(map[int]int64, len(job.Files))
+	dispatched := make(map[int]int64, len(job.Files))
+	for _, file := range job.Files {
+		committed[file.Position] = file.RowsCommitted
+		dispatched[file.Position] = file.RowsDispatched
+	}
+
+	statusChan := make(chan processor.ProcessStatus)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.follow(statusChan)
+	}()
+
+	pipeline.Import(ctx, files, job.TenantID, job.Term, statusChan,
+		processor.WithResume(committed),
+		processor.WithReplay(dispatched),
+		processor.WithDispatch(func(id int, rows int64) error {
+			return uh.imports.Dispatch(job.ID, id, rows)
+		}),
+		processor.WithCheckpoint(func(id int, rows int64) {
+			if err := uh.imports.Checkpoint(job.ID, id, rows); err != nil {
+				log.Printf("Failed to checkpoint upload %s: %v", job.ID, err)
+			}
+		}),
+	)
+	<-done
+
+	// Batches stop at the shutdown deadline, the committed ones are kept
+	if ctx.Err() != nil {
+		interrupt()
+		return
+	}
+
+	if message := tracker.failure(); message != "" {
+		uh.setStatus(job.ID, config.ImportFailed, message)
+		uh.Metrics.UploadFailed()
+		return
+	}
+	uh.setStatus(job.ID, config.ImportCompleted, "")
+	uh.Metrics.UploadSucceeded()
+}
+
+func (uh *UploadHandler) pipeline() Pipeline {
+	return Pipeline{Students: *uh.repo, Courses: uh.courses, Grades: uh.grades, Settings: uh.importing, Metrics: uh.Metrics}
+}
+
+func (uh *UploadHandler) setStatus(id uuid.UUID, status config.ImportStatus, message string) {
+	if err := uh.imports.SetStatus(id, status, message); err != nil {
+		log.Printf("Failed to mark upload %s %s: %v", id, status, err)
+	}
+}
+
+// forget drops the progress of an upload once it can no longer be followed
+func (uh *UploadHandler) forget(uploadID uuid.UUID) {
+	time.AfterFunc(progressRetention, func() {
+		uh.mu.Lock()
+		delete(uh.uploads, uploadID)
+		uh.mu.Unlock()
+	})
+}
+
+// Resume queues again the uploads interrupted by the last shutdown, and the
+// ones left queued or running by a crash, then removes spooled files no job
+// owns. It returns the number of resumed uploads
+func (uh *UploadHandler) Resume() (int, error) {
+	owned := map[string]bool{}
+	resumed := 0
+
+	for _, status := range []config.ImportStatus{config.ImportInterrupted, config.ImportRunning, config.ImportQueued} {
+		pending, err := uh.imports.ListByStatus(status)
+		if err != nil {
+			return resumed, err
+		}
+
+		for _, job := range pending {
+			owned[job.ID.String()] = true
+			if _, err := uh.enqueue(job, true); err != nil {
+				return resumed, err
+			}
+			resumed++
+		}
+	}
+
+	entries, err := os.ReadDir(uh.spool)
+	if errors.Is(err, fs.ErrNotExist) {
+		return resumed, nil
+	}
+	if err != nil {
+		return resumed, err
+	}
+	for _, entry := range entries {
+		if !owned[entry.Name()] {
+			os.RemoveAll(filepath.Join(uh.spool, entry.Name()))
+		}
+	}
+	return resumed, nil
+}
@@ -0,0 +1,53 @@
+package upload
+
+import (
+	"encoding/csv"
+	"errors"
+	"file-uploader/config"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CountCSVRows counts the records of the file after the header, malformed
+// records count too and are reported while processing
+func CountCSVRows(f *os.File) (int, error) {
+	defer f.Seek(0, io.SeekStart)
+
+	f.Seek(0, io.SeekStart)
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	rows := -1
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return 0, err
+		}
+		rows++
+	}
+	return max(rows, 0), nil
+}
+
+// ValidateCSVRows rejects files with more than maxRows records
+func ValidateCSVRows(files []*os.File, maxRows int) error {
+	if maxRows == 0 {
+		return nil
+	}
+
+	for _, f := range files {
+		rows, err := CountCSVRows(f)
+		if err != nil {
+			return err
+		}
+		if rows > maxRows {
+			return fmt.Errorf("%w: %d, at most %d", config.ErrTooManyRows, rows, maxRows)
+		}
+	}
+	return nil
+}
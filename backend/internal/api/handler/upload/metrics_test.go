@@ -0,0 +1,133 @@
+package upload_test
+
+import (
+	"context"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/upload"
+	"file-uploader/internal/service/jobs"
+	"file-uploader/internal/service/metrics"
+	testutils "file-uploader/internal/test-utils"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricValue reads a counter or gauge, or the number of observations of a
+// histogram, from the registry
+func metricValue(t *testing.T, m *metrics.Metrics, name string) float64 {
+	families, err := m.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		total := 0.0
+		for _, metric := range family.GetMetric() {
+			switch {
+			case metric.Counter != nil:
+				total += metric.Counter.GetValue()
+			case metric.Gauge != nil:
+				total += metric.Gauge.GetValue()
+			case metric.Histogram != nil:
+				total += float64(metric.Histogram.GetSampleCount())
+			}
+		}
+		return total
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
+
+func TestUploadMetrics(t *testing.T) {
+	m := metrics.New()
+	queue := jobs.NewQueue(1, 2)
+	m.WatchQueue(queue)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	m.WatchDB(sqlDB)
+
+	students := repository.NewStudentRepository[model.Student](testDB, repository.WithInsertObserver(m.ObserveInsert))
+	settings := config.Default()
+	settings.Upload.SpoolDir = t.TempDir()
+
+	handler := upload.NewUploadHandler(
+		&students,
+		repository.NewCourseRepository(testDB),
+		repository.NewGradeRepository(testDB),
+		repository.NewImportJobRepository(testDB),
+		settings.Upload,
+		settings.Import,
+		queue,
+	)
+	handler.Metrics = m
+
+	var valid strings.Builder
+	valid.WriteString(config.StudentsTableHeader + "\n")
+	for i := range 3 {
+		fmt.Fprintf(&valid, "%s,Metered %d,Physics,%d\n", uuid.NewString(), i, 70+i)
+	}
+
+	// The second record fails the mapping, the first is rejected with it
+	malformed := config.StudentsTableHeader + "\n" +
+		uuid.NewString() + ",Metered 3,Physics,80\n" +
+		uuid.NewString() + ",Metered 4,Physics,eighty\n"
+
+	for _, content := range []string{valid.String(), malformed} {
+		body, contentType := multipartUpload(t, content)
+		c, rec := testutils.NewTestContext(http.MethodPost, "/upload", body)
+		c.Request().Header.Set(echo.HeaderContentType, contentType)
+		require.NoError(t, handler.HandleFileUpload(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// Wait for both uploads to be processed
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	cases := []struct {
+		name     string
+		expected float64
+	}{
+		{name: "file_uploader_uploads_started_total", expected: 2},
+		{name: "file_uploader_uploads_succeeded_total", expected: 1},
+		{name: "file_uploader_uploads_failed_total", expected: 1},
+		{name: "file_uploader_rows_ingested_total", expected: 3},
+		{name: "file_uploader_rows_rejected_total", expected: 2},
+		{name: "file_uploader_processed_bytes_total", expected: float64(valid.Len() + len(malformed))},
+		{name: "file_uploader_insert_batch_duration_seconds", expected: 1},
+		{name: "file_uploader_insert_batch_rows", expected: 1},
+		{name: "file_uploader_upload_queue_depth", expected: 0},
+		{name: "file_uploader_upload_watchers", expected: 0},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, metricValue(t, m, tt.name))
+		})
+	}
+
+	t.Run("pool stats", func(t *testing.T) {
+		assert.Positive(t, metricValue(t, m, "go_sql_open_connections"))
+	})
+
+	t.Run("served in the text format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		body, err := io.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "file_uploader_uploads_succeeded_total 1")
+		assert.Contains(t, string(body), "file_uploader_rows_ingested_total 3")
+	})
+}
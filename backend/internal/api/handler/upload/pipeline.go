@@ -0,0 +1,67 @@
+package upload
+
+import (
+	"context"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	processor "file-uploader/internal/service/csv"
+	"file-uploader/internal/service/metrics"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// Pipeline checks CSV files and imports them into the students of a tenant,
+// uploads and the import command share it
+type Pipeline struct {
+	Students repository.StudentRepository[model.Student]
+	Courses  repository.CourseRepository
+	Grades   repository.GradeRepository
+	Settings config.Import
+
+	// Metrics count the bytes and records of the files, nil counts nothing
+	Metrics *metrics.Metrics
+}
+
+// Validate checks the content type, the header and the subjects of the
+// files, scoped uploaders may only import their subjects
+func (p Pipeline) Validate(files []*os.File, tenantID uuid.UUID, scope []string) error {
+	if err := ValidateCSVFiles(files); err != nil {
+		return err
+	}
+
+	if err := ValidateCSVHeader(files); err != nil {
+		return err
+	}
+
+	catalog, err := repository.CourseSet(repository.Scoped(p.Courses, tenantID))
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateCSVSubjects(files, catalog); err != nil {
+		return err
+	}
+
+	return ValidateCSVScope(files, scope)
+}
+
+// Import processes validated files, the grades are tagged with the term.
+// Statuses are sent on status, which is closed once every file is done
+func (p Pipeline) Import(
+	ctx context.Context,
+	files []*os.File,
+	tenantID uuid.UUID,
+	term string,
+	status chan processor.ProcessStatus,
+	opts ...processor.ProcessOption,
+) {
+	// Mirror flat rows into the normalized grade records
+	repo := repository.NewNormalizingRepository(p.Students, p.Grades, term).ForTenant(tenantID)
+
+	report := processor.WithReport(func(id int, report processor.FileReport) {
+		p.Metrics.FileProcessed(report.Bytes, report.Stored, report.Read-report.Stored)
+	})
+	ProcessFiles(ctx, files, status, repo, processor.StudentMapper, p.Settings, append([]processor.ProcessOption{report}, opts...)...)
+}
@@ -0,0 +1,80 @@
+package upload
+
+import (
+	processor "file-uploader/internal/service/csv"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// progress keeps the statuses of an upload so workers never wait on the
+// status socket, any number of sockets can replay and follow them
+type progress struct {
+	tenant uuid.UUID
+
+	mu       sync.Mutex
+	statuses []processor.ProcessStatus
+	done     bool
+	changed  chan struct{}
+}
+
+func newProgress(tenant uuid.UUID) *progress {
+	return &progress{tenant: tenant, changed: make(chan struct{})}
+}
+
+// publish records a status and wakes the followers
+func (p *progress) publish(status processor.ProcessStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.statuses = append(p.statuses, status)
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// queued records the place of the upload in line
+func (p *progress) queued(position int) {
+	p.publish(processor.ProcessStatus{Position: position})
+}
+
+// finish marks the upload as processed
+func (p *progress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done = true
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// since returns the statuses after the first n, whether the upload is done
+// and a channel closed on the next change
+func (p *progress) since(n int) ([]processor.ProcessStatus, bool, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n > len(p.statuses) {
+		n = len(p.statuses)
+	}
+	return append([]processor.ProcessStatus(nil), p.statuses[n:]...), p.done, p.changed
+}
+
+// follow drains the status channel of the processing into the progress
+func (p *progress) follow(status <-chan processor.ProcessStatus) {
+	for s := range status {
+		p.publish(s)
+	}
+}
+
+// failure returns the first error reported, empty when there is none
+func (p *progress) failure() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, status := range p.statuses {
+		if status.Error != "" {
+			return status.Error
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package upload
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RollbackResponse tells how many rows a rollback removed
+type RollbackResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Deleted int       `json:"deleted"`
+}
+
+// Rollback handles DELETE /upload/:uploadID requests, it removes the rows
+// stored by an upload that has ended, within the subjects of the user, and
+// marks it rolled back. Uploads of other tenants look like unknown uploads
+func (uh *UploadHandler) Rollback(c echo.Context) error {
+	uploadID, err := uuid.Parse(c.Param("uploadID"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, config.ErrMissingPathParamHttp)
+	}
+
+	job, err := uh.imports.Get(uploadID)
+	if errors.Is(err, config.ErrImportJobNotExist) || (err == nil && job.TenantID != middleware.TenantID(c)) {
+		return echo.NewHTTPError(http.StatusNotFound, config.ErrUploadNotFoundHttp)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to read upload: "+err.Error())
+	}
+	if job.Status == config.ImportQueued || job.Status == config.ImportRunning {
+		return echo.NewHTTPError(http.StatusConflict, config.ErrUploadRunningHttp)
+	}
+
+	repo := repository.NewNormalizingRepository(*uh.repo, uh.grades, job.Term).ForTenant(job.TenantID)
+	ids, err := repo.Delete([]repository.QueryOption{
+		repository.WithImport(job.ID),
+		repository.WithSubjectScope(middleware.SubjectScope(c)),
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to roll back upload: "+err.Error())
+	}
+
+	uh.setStatus(job.ID, config.ImportRolledBack, "")
+	return c.JSON(http.StatusOK, RollbackResponse{ID: job.ID, Deleted: len(ids)})
+}
@@ -0,0 +1,109 @@
+// Package middleware holds the Echo middleware of the api
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"file-uploader/config"
+	"file-uploader/internal/service/auth"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PrincipalKey stores the authenticated principal in the echo context
+const PrincipalKey = "principal"
+
+// RequireAuth rejects requests without a valid session or api token
+func RequireAuth(service *auth.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := service.Authenticate(Credential(c))
+			if errors.Is(err, config.ErrUnauthenticated) {
+				return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to authenticate: "+err.Error())
+			}
+
+			c.Set(PrincipalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// RequireToken rejects requests whose bearer token isn't token, for the
+// routes scraped by machines rather than used by users
+func RequireToken(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			given, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequirePermission rejects principals whose role lacks the permission, it
+// runs after RequireAuth
+func RequirePermission(permission auth.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := Principal(c)
+			if principal == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, config.ErrUnauthorizedHttp)
+			}
+			if !principal.Can(permission) {
+				return echo.NewHTTPError(http.StatusForbidden, config.ErrForbiddenHttp)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Principal returns the principal set by RequireAuth, nil on anonymous routes
+func Principal(c echo.Context) *auth.Principal {
+	principal, _ := c.Get(PrincipalKey).(*auth.Principal)
+	return principal
+}
+
+// SubjectScope returns the subjects the request is limited to, nil when
+// unscoped or anonymous
+func SubjectScope(c echo.Context) []string {
+	if principal := Principal(c); principal != nil {
+		return principal.Scope()
+	}
+	return nil
+}
+
+// TenantID returns the tenant of the principal, uuid.Nil on anonymous
+// routes which repositories treat as the default tenant
+func TenantID(c echo.Context) uuid.UUID {
+	if principal := Principal(c); principal != nil {
+		return principal.User.TenantID
+	}
+	return uuid.Nil
+}
+
+// Credential reads a bearer token, then the session cookie. Browsers can't
+// set headers on WebSocket handshakes, those may pass access_token instead
+func Credential(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+
+	if cookie, err := c.Cookie(config.SessionCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	if strings.EqualFold(c.Request().Header.Get(echo.HeaderUpgrade), "websocket") {
+		return c.QueryParam("access_token")
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testService *auth.Service
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, _, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testService = auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
+
+func TestRequireAuth(t *testing.T) {
+	testDB.Where("email = ?", "middleware@example.com").Delete(&model.User{})
+	user, err := testService.Register("middleware@example.com", "correct horse")
+	require.NoError(t, err)
+
+	session, _, err := testService.Login("middleware@example.com", "correct horse")
+	require.NoError(t, err)
+	apiToken, _, err := testService.CreateAPIToken(user.ID, "scripts", 0)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		prepare  func(r *http.Request)
+		expected int
+	}{
+		{
+			name:     "no credentials",
+			prepare:  func(r *http.Request) {},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "session cookie",
+			prepare:  func(r *http.Request) { r.AddCookie(&http.Cookie{Name: config.SessionCookie, Value: session}) },
+			expected: http.StatusOK,
+		},
+		{
+			name:     "bearer api token",
+			prepare:  func(r *http.Request) { r.Header.Set(echo.HeaderAuthorization, "Bearer "+apiToken) },
+			expected: http.StatusOK,
+		},
+		{
+			name:     "invalid bearer token",
+			prepare:  func(r *http.Request) { r.Header.Set(echo.HeaderAuthorization, "Bearer not-a-token") },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "websocket handshake with access_token",
+			prepare: func(r *http.Request) {
+				r.Header.Set(echo.HeaderUpgrade, "websocket")
+				r.URL.RawQuery = "access_token=" + apiToken
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "access_token outside websocket handshakes",
+			prepare:  func(r *http.Request) { r.URL.RawQuery = "access_token=" + apiToken },
+			expected: http.StatusUnauthorized,
+		},
+	}
+
+	protected := middleware.RequireAuth(testService)(func(c echo.Context) error {
+		assert.Equal(t, user.ID, middleware.Principal(c).User.ID)
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := testutils.NewTestContext(http.MethodGet, "/api/students", nil)
+			tt.prepare(c.Request())
+
+			err := protected(c)
+			if tt.expected == http.StatusOK {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.expected, httpErr.Code)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	principal := func(role config.Role) *auth.Principal {
+		return &auth.Principal{User: &model.User{Role: role}}
+	}
+
+	cases := []struct {
+		name       string
+		principal  *auth.Principal
+		permission auth.Permission
+		expected   int
+	}{
+		{"anonymous", nil, auth.PermReadStudents, http.StatusUnauthorized},
+		{"viewer reads", principal(config.RoleViewer), auth.PermReadStudents, http.StatusOK},
+		{"viewer uploads", principal(config.RoleViewer), auth.PermUploadStudents, http.StatusForbidden},
+		{"uploader uploads", principal(config.RoleUploader), auth.PermUploadStudents, http.StatusOK},
+		{"uploader manages courses", principal(config.RoleUploader), auth.PermManageCourses, http.StatusForbidden},
+		{"admin deletes", principal(config.RoleAdmin), auth.PermDeleteStudents, http.StatusOK},
+		{"admin manages courses", principal(config.RoleAdmin), auth.PermManageCourses, http.StatusOK},
+		{"admin manages scales", principal(config.RoleAdmin), auth.PermManageScales, http.StatusForbidden},
+		{"platform admin manages scales", principal(config.RolePlatformAdmin), auth.PermManageScales, http.StatusOK},
+		{"unknown role", principal("owner"), auth.PermReadStudents, http.StatusForbidden},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := testutils.NewTestContext(http.MethodGet, "/api/courses", nil)
+			if tt.principal != nil {
+				c.Set(middleware.PrincipalKey, tt.principal)
+			}
+
+			err := middleware.RequirePermission(tt.permission)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+
+			if tt.expected == http.StatusOK {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.expected, httpErr.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"file-uploader/internal/service/metrics"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics counts the requests and their latency by route pattern, requests
+// matching no route share the "unmatched" route
+func Metrics(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			// Errors are written after the middlewares return, read their code
+			code := c.Response().Status
+			if err != nil {
+				code = http.StatusInternalServerError
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					code = httpErr.Code
+				}
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			m.ObserveRequest(c.Request().Method, route, code, time.Since(start))
+			return err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	e := echo.New()
+	e.Use(middleware.Metrics(m))
+	e.GET("/students/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.DELETE("/students/:id", func(c echo.Context) error { return echo.NewHTTPError(http.StatusForbidden) })
+
+	requests := []struct {
+		method string
+		target string
+	}{
+		{method: http.MethodGet, target: "/students/1"},
+		{method: http.MethodGet, target: "/students/2"},
+		{method: http.MethodDelete, target: "/students/1"},
+		{method: http.MethodGet, target: "/nowhere"},
+	}
+	for _, r := range requests {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.target, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+
+	// Routes are labeled by pattern, the code of returned errors is counted
+	cases := []string{
+		`file_uploader_http_requests_total{code="200",method="GET",route="/students/:id"} 2`,
+		`file_uploader_http_requests_total{code="403",method="DELETE",route="/students/:id"} 1`,
+		`file_uploader_http_requests_total{code="404",method="GET",route="unmatched"} 1`,
+		`file_uploader_http_request_duration_seconds_count{method="GET",route="/students/:id"} 2`,
+	}
+	for _, expected := range cases {
+		assert.True(t, strings.Contains(body, expected), expected)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"file-uploader/config"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit allows each client perMinute requests a minute, with bursts of
+// up to a minute's worth. Clients are told apart by user after RequireAuth,
+// by ip otherwise. Zero disables the limit
+func RateLimit(perMinute int) echo.MiddlewareFunc {
+	if perMinute <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+
+	store := echomiddleware.NewRateLimiterMemoryStoreWithConfig(echomiddleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(perMinute) / 60),
+		Burst:     perMinute,
+		ExpiresIn: 3 * time.Minute,
+	})
+
+	retryAfter := strconv.Itoa(max(60/perMinute, 1))
+
+	return echomiddleware.RateLimiterWithConfig(echomiddleware.RateLimiterConfig{
+		Store: store,
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			if principal := Principal(c); principal != nil {
+				return "user:" + principal.User.ID.String(), nil
+			}
+			return "ip:" + c.RealIP(), nil
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			c.Response().Header().Set("Retry-After", retryAfter)
+			return echo.NewHTTPError(http.StatusTooManyRequests, config.ErrRateLimitedHttp)
+		},
+	})
+}
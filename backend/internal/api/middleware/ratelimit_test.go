@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
+	testutils "file-uploader/internal/test-utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	limit := middleware.RateLimit(2)
+	handler := limit(func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	// Denied requests are written by the echo error handler
+	request := func(principal *auth.Principal) *httptest.ResponseRecorder {
+		c, rec := testutils.NewTestContext(http.MethodPost, "/upload", nil)
+		if principal != nil {
+			c.Set(middleware.PrincipalKey, principal)
+		}
+		require.NoError(t, handler(c))
+		return rec
+	}
+
+	alice := &auth.Principal{User: &model.User{ID: uuid.New()}}
+	bob := &auth.Principal{User: &model.User{ID: uuid.New()}}
+
+	assert.Equal(t, http.StatusOK, request(alice).Code)
+	assert.Equal(t, http.StatusOK, request(alice).Code)
+
+	rec := request(alice)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), config.ErrRateLimitedHttp)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// Each client has its own budget
+	assert.Equal(t, http.StatusOK, request(bob).Code)
+	assert.Equal(t, http.StatusOK, request(nil).Code)
+
+	// Zero disables the limit
+	unlimited := middleware.RateLimit(0)(func(c echo.Context) error { return nil })
+	for range 5 {
+		c, _ := testutils.NewTestContext(http.MethodPost, "/upload", nil)
+		assert.NoError(t, unlimited(c))
+	}
+}
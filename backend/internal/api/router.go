@@ -1,25 +1,106 @@
 package api
 
 import (
+	"file-uploader/config"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
+	"file-uploader/internal/api/handler/analytics"
+	authhandler "file-uploader/internal/api/handler/auth"
+	"file-uploader/internal/api/handler/courses"
+	"file-uploader/internal/api/handler/scales"
+	"file-uploader/internal/api/handler/stats"
 	"file-uploader/internal/api/handler/students"
 	"file-uploader/internal/api/handler/upload"
+	"file-uploader/internal/api/middleware"
+	"file-uploader/internal/service/auth"
+	"file-uploader/internal/service/jobs"
+	"file-uploader/internal/service/metrics"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(e *echo.Echo, db *gorm.DB, studentsRepo repository.StudentRepository[model.Student]) {
+// RegisterRoutes adds the routes of the api to e, the returned upload
+// handler resumes the uploads interrupted by the last shutdown
+func RegisterRoutes(e *echo.Echo, db *gorm.DB, studentsRepo repository.StudentRepository[model.Student], cfg *config.Config, queue *jobs.Queue, m *metrics.Metrics) *upload.UploadHandler {
 
 	// Create handlers
-	uploadHandler := upload.NewUploadHandler(&studentsRepo)
-	studentsHandler := students.NewHandler[model.Student](studentsRepo)
+	coursesRepo := repository.NewCourseRepository(db)
+	gradesRepo := repository.NewGradeRepository(db)
+	uploadHandler := upload.NewUploadHandler(&studentsRepo, coursesRepo, gradesRepo, repository.NewImportJobRepository(db), cfg.Upload, cfg.Import, queue)
+	uploadHandler.Metrics = m
+	m.WatchQueue(queue)
+	scalesRepo := repository.NewGradingScaleRepository(db)
+	studentsHandler := students.NewHandler[model.Student](studentsRepo, coursesRepo, gradesRepo, scalesRepo,
+		students.WithPageSizes(cfg.Query.DefaultPageSize, cfg.Query.MaxPageSize))
+	coursesHandler := courses.NewHandler(coursesRepo)
+	scalesHandler := scales.NewHandler(scalesRepo)
+	analyticsHandler := analytics.NewHandler(repository.NewAnalyticsRepository[model.Student](db), coursesRepo, scalesRepo)
+	inserts, _ := studentsRepo.(repository.InsertStatsReporter)
+	statsHandler := stats.NewHandler(db, inserts)
 
-	// Register routes
-	apiGroup := e.Group("/api")
-	apiGroup.POST("/upload", uploadHandler.HandleFileUpload)
-	apiGroup.GET("/upload/status/:uploadID", uploadHandler.HandleStatusUpdates)
+	authService := auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+	authService.SessionTTL = cfg.Auth.SessionTTL
+	authHandler := authhandler.NewHandler(authService)
 
-	apiGroup.GET("/students", studentsHandler.GetAll)
+	// Metrics are scraped with their own token rather than a user's, and
+	// aren't served without one
+	if m != nil && cfg.Server.MetricsToken != "" {
+		e.GET("/metrics", echo.WrapHandler(m.Handler()), middleware.RequireToken(cfg.Server.MetricsToken))
+	}
+
+	// Public routes
+	e.POST("/api/auth/login", authHandler.Login)
+	e.POST("/api/auth/logout", authHandler.Logout)
+
+	// Every other route needs a session or an api token, including the WebSocket status route
+	apiGroup := e.Group("/api", middleware.RequireAuth(authService))
+
+	apiGroup.GET("/auth/me", authHandler.Me)
+	apiGroup.GET("/auth/tokens", authHandler.ListTokens)
+	apiGroup.POST("/auth/tokens", authHandler.CreateToken)
+	apiGroup.DELETE("/auth/tokens/:id", authHandler.RevokeToken)
+
+	// Each route checks the role of the principal, reads are further scoped
+	// to the subjects of the user by the handlers
+	canRead := middleware.RequirePermission(auth.PermReadStudents)
+	canUpload := middleware.RequirePermission(auth.PermUploadStudents)
+	canDelete := middleware.RequirePermission(auth.PermDeleteStudents)
+	canManage := middleware.RequirePermission(auth.PermManageCourses)
+	canManageScales := middleware.RequirePermission(auth.PermManageScales)
+
+	apiGroup.POST("/upload", uploadHandler.HandleFileUpload, canUpload, middleware.RateLimit(cfg.Upload.RatePerMinute))
+	apiGroup.GET("/upload/status/:uploadID", uploadHandler.HandleStatusUpdates, canUpload)
+	apiGroup.DELETE("/upload/:uploadID", uploadHandler.Rollback, canDelete)
+
+	apiGroup.GET("/students", studentsHandler.GetAll, canRead)
+	apiGroup.GET("/students/suggest", studentsHandler.Suggest, canRead)
+	apiGroup.DELETE("/students/:id", studentsHandler.Delete, canDelete)
+
+	apiGroup.GET("/analytics/percentiles", analyticsHandler.Percentiles, canRead)
+	apiGroup.GET("/analytics/top", analyticsHandler.Top, canRead)
+	apiGroup.GET("/analytics/bottom", analyticsHandler.Bottom, canRead)
+	apiGroup.GET("/analytics/subjects", analyticsHandler.Subjects, canRead)
+	apiGroup.GET("/analytics/letters", analyticsHandler.Letters, canRead)
+
+	apiGroup.GET("/courses", coursesHandler.List, canRead)
+	apiGroup.GET("/courses/:name", coursesHandler.Get, canRead)
+	apiGroup.POST("/courses", coursesHandler.Create, canManage)
+	apiGroup.PUT("/courses/:name", coursesHandler.Update, canManage)
+	apiGroup.DELETE("/courses/:name", coursesHandler.Delete, canManage)
+
+	apiGroup.GET("/grading-scales", scalesHandler.List, canRead)
+	apiGroup.GET("/grading-scales/:name", scalesHandler.Get, canRead)
+	// Scales are shared by the tenants, a tenant admin only assigns them
+	apiGroup.POST("/grading-scales", scalesHandler.Create, canManageScales)
+	apiGroup.PUT("/grading-scales/:name", scalesHandler.Update, canManageScales)
+	apiGroup.DELETE("/grading-scales/:name", scalesHandler.Delete, canManageScales)
+
+	apiGroup.GET("/stats/database", statsHandler.Database, canManage)
+
+	return uploadHandler
 }
@@ -0,0 +1,326 @@
+package api_test
+
+import (
+	"context"
+	"file-uploader/config"
+	"file-uploader/database"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/api"
+	"file-uploader/internal/service/auth"
+	"file-uploader/internal/service/jobs"
+	"file-uploader/internal/service/metrics"
+	testutils "file-uploader/internal/test-utils"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testStudents repository.StudentRepository[model.Student]
+var testServer *echo.Echo
+var testService *auth.Service
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	driver, dsn, err := testutils.TestDSN()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	db, students, err := database.SetupDB[model.Student](driver, dsn, true)
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+	testDB = db
+	testStudents = students
+
+	testService = auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+
+	queue := jobs.NewQueue(1, 1)
+	testServer = echo.New()
+	api.RegisterRoutes(testServer, db, students, config.Default(), queue, metrics.New())
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	queue.Shutdown(context.Background())
+	sqlDB, _ := testDB.DB()
+	sqlDB.Close()
+	testutils.RemoveTestDSN(driver, dsn)
+
+	os.Exit(code)
+}
+
+// tokenFor creates a user of a new tenant with the role and returns its api
+// token
+func tokenFor(t *testing.T, role config.Role) string {
+	return tokenIn(t, newTenant(t), role)
+}
+
+// newTenant creates a tenant, with the default course catalog
+func newTenant(t *testing.T) uuid.UUID {
+	tenant := &model.Tenant{Name: "Router School " + uuid.NewString()}
+	require.NoError(t, repository.NewTenantRepository(testDB).Create(tenant))
+	return tenant.ID
+}
+
+// tokenIn creates a user of the tenant with the role and returns its api
+// token
+func tokenIn(t *testing.T, tenantID uuid.UUID, role config.Role) string {
+	user, err := testService.RegisterIn(tenantID, uuid.NewString()+"@example.com", "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, testService.Users.SetRole(user.ID, role))
+
+	token, _, err := testService.CreateAPIToken(user.ID, "router", 0)
+	require.NoError(t, err)
+	return token
+}
+
+// serve runs a request through the routes, with the token as bearer unless
+// empty
+func serve(method, path, token string, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	testServer.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegisterRoutesDoesNotResume(t *testing.T) {
+	imports := repository.NewImportJobRepository(testDB)
+	job := &model.ImportJob{Files: []*model.ImportFile{{Position: 0, Path: "missing.csv"}}}
+	require.NoError(t, imports.Create(job))
+	require.NoError(t, imports.SetStatus(job.ID, config.ImportInterrupted, ""))
+	defer imports.Delete(job.ID)
+
+	queue := jobs.NewQueue(1, 1)
+	cfg := config.Default()
+	cfg.Upload.SpoolDir = t.TempDir()
+	uploads := api.RegisterRoutes(echo.New(), testDB, testStudents, cfg, queue, nil)
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	stored, err := imports.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportInterrupted, stored.Status, "routes don't run jobs")
+
+	// Resuming is up to the caller
+	queue = jobs.NewQueue(1, 1)
+	uploads = api.RegisterRoutes(echo.New(), testDB, testStudents, cfg, queue, nil)
+	resumed, err := uploads.Resume()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, resumed, 1)
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	stored, err = imports.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportFailed, stored.Status, "the spooled file is gone")
+}
+
+func TestMetricsToken(t *testing.T) {
+	rec := serve(http.MethodGet, "/metrics", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "metrics aren't served without a token")
+
+	cfg := config.Default()
+	cfg.Server.MetricsToken = "scrape-" + uuid.NewString()
+	queue := jobs.NewQueue(1, 1)
+	defer queue.Shutdown(context.Background())
+	server := echo.New()
+	api.RegisterRoutes(server, testDB, testStudents, cfg, queue, metrics.New())
+
+	cases := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "scrape-guess", http.StatusUnauthorized},
+		{"user token", tokenFor(t, config.RoleAdmin), http.StatusUnauthorized},
+		{"metrics token", cfg.Server.MetricsToken, http.StatusOK},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestSharedGradingScales(t *testing.T) {
+	scales := repository.NewGradingScaleRepository(testDB)
+	scales.Delete("router_shared")
+	require.NoError(t, scales.Create(&model.GradingScale{
+		Name:  "router_shared",
+		Bands: []model.GradeBand{{Letter: "P", MinGrade: 50, Passing: true}, {Letter: "F", MinGrade: 0}},
+	}))
+
+	// The scale is used by another tenant, the admin of this one can't touch it
+	tenantAdmin := tokenFor(t, config.RoleAdmin)
+	platformAdmin := tokenFor(t, config.RolePlatformAdmin)
+
+	bands := `{"bands": [{"letter": "A", "min_grade": 0, "passing": true}]}`
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     string
+		expected int
+	}{
+		{"tenant admin reads", http.MethodGet, "/api/grading-scales/router_shared", tenantAdmin, "", http.StatusOK},
+		{"tenant admin creates", http.MethodPost, "/api/grading-scales", tenantAdmin, `{"name": "router_other", "bands": [{"letter": "A", "min_grade": 0}]}`, http.StatusForbidden},
+		{"tenant admin updates", http.MethodPut, "/api/grading-scales/router_shared", tenantAdmin, bands, http.StatusForbidden},
+		{"tenant admin deletes", http.MethodDelete, "/api/grading-scales/router_shared", tenantAdmin, "", http.StatusForbidden},
+		{"platform admin updates", http.MethodPut, "/api/grading-scales/router_shared", platformAdmin, bands, http.StatusOK},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.method, tt.path, tt.token, strings.NewReader(tt.body))
+			assert.Equal(t, tt.expected, rec.Code, rec.Body.String())
+
+			if tt.expected == http.StatusForbidden {
+				scale, err := scales.Get("router_shared")
+				require.NoError(t, err)
+				assert.Len(t, scale.Bands, 2, "the scale is left unchanged")
+			}
+		})
+	}
+
+	require.NoError(t, scales.Delete("router_shared"))
+}
+
+// storeStudents stores rows and their grade records in the tenant, recorded
+// as stored by the upload unless nil
+func storeStudents(t *testing.T, tenantID, importID uuid.UUID, count int) []uuid.UUID {
+	rows := make([]*model.Student, count)
+	ids := make([]uuid.UUID, count)
+	for i := range rows {
+		ids[i] = uuid.New()
+		rows[i] = &model.Student{Student_id: ids[i], Student_name: "Router Student", Subject: string(config.Physics), Grade: 70}
+	}
+
+	repo := repository.NewNormalizingRepository(testStudents, repository.NewGradeRepository(testDB), "").ForTenant(tenantID)
+	if importID != uuid.Nil {
+		repo = repo.ForImport(importID)
+	}
+	require.NoError(t, repo.CreateMany(rows))
+	return ids
+}
+
+// stored counts the rows and grade records of the ids
+func stored(t *testing.T, ids []uuid.UUID) (int64, int64) {
+	var rows, grades int64
+	require.NoError(t, testDB.Model(&model.Student{}).Where("student_id IN ?", ids).Count(&rows).Error)
+	require.NoError(t, testDB.Model(&model.GradeRecord{}).Where("id IN ?", ids).Count(&grades).Error)
+	return rows, grades
+}
+
+func TestDeleteStudent(t *testing.T) {
+	tenant := newTenant(t)
+	ids := storeStudents(t, tenant, uuid.Nil, 2)
+	path := "/api/students/" + ids[0].String()
+
+	cases := []struct {
+		name     string
+		path     string
+		token    string
+		expected int
+		left     int64
+	}{
+		{"viewers can't delete", path, tokenIn(t, tenant, config.RoleViewer), http.StatusForbidden, 2},
+		{"uploaders can't delete", path, tokenIn(t, tenant, config.RoleUploader), http.StatusForbidden, 2},
+		{"admins of other tenants don't find it", path, tokenFor(t, config.RoleAdmin), http.StatusNotFound, 2},
+		{"invalid id", "/api/students/not-an-id", tokenIn(t, tenant, config.RoleAdmin), http.StatusBadRequest, 2},
+		{"admins delete", path, tokenIn(t, tenant, config.RoleAdmin), http.StatusNoContent, 1},
+		{"deleted students are gone", path, tokenIn(t, tenant, config.RoleAdmin), http.StatusNotFound, 1},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(http.MethodDelete, tt.path, tt.token, nil)
+			assert.Equal(t, tt.expected, rec.Code, rec.Body.String())
+
+			rows, grades := stored(t, ids)
+			assert.Equal(t, tt.left, rows)
+			assert.Equal(t, tt.left, grades, "grade records follow the rows")
+		})
+	}
+}
+
+func TestRollbackUpload(t *testing.T) {
+	tenant := newTenant(t)
+	imports := repository.NewImportJobRepository(testDB)
+	job := &model.ImportJob{TenantID: tenant, Files: []*model.ImportFile{{Position: 0, Path: "rolled.csv"}}}
+	require.NoError(t, imports.Create(job))
+	defer imports.Delete(job.ID)
+
+	imported := storeStudents(t, tenant, job.ID, 3)
+	kept := storeStudents(t, tenant, uuid.Nil, 1)
+	admin := tokenIn(t, tenant, config.RoleAdmin)
+	path := "/api/upload/" + job.ID.String()
+
+	rec := serve(http.MethodDelete, path, admin, nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, "queued uploads can't be rolled back")
+	require.NoError(t, imports.SetStatus(job.ID, config.ImportCompleted, ""))
+
+	cases := []struct {
+		name     string
+		token    string
+		expected int
+		left     int64
+	}{
+		{"uploaders can't roll back", tokenIn(t, tenant, config.RoleUploader), http.StatusForbidden, 3},
+		{"admins of other tenants don't find it", tokenFor(t, config.RoleAdmin), http.StatusNotFound, 3},
+		{"admins roll back", admin, http.StatusOK, 0},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(http.MethodDelete, path, tt.token, nil)
+			assert.Equal(t, tt.expected, rec.Code, rec.Body.String())
+
+			rows, grades := stored(t, imported)
+			assert.Equal(t, tt.left, rows)
+			assert.Equal(t, tt.left, grades)
+		})
+	}
+
+	rows, grades := stored(t, kept)
+	assert.EqualValues(t, 1, rows, "rows stored otherwise are kept")
+	assert.EqualValues(t, 1, grades)
+
+	rolledBack, err := imports.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, config.ImportRolledBack, rolledBack.Status)
+}
@@ -0,0 +1,267 @@
+// Package auth logs users in with passwords, issuing session tokens for
+// browsers, and manages long-lived api tokens for scripts
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MinPasswordLength = 8
+	DefaultSessionTTL = 7 * 24 * time.Hour
+)
+
+type Method string
+
+const (
+	MethodSession  Method = "session"
+	MethodAPIToken Method = "api_token"
+)
+
+type Permission string
+
+const (
+	PermReadStudents   Permission = "students:read"
+	PermUploadStudents Permission = "students:upload"
+	PermDeleteStudents Permission = "students:delete"
+	PermManageCourses  Permission = "courses:manage"
+	PermManageScales   Permission = "scales:manage"
+)
+
+// Permissions lists every permission, in a stable order
+var Permissions = []Permission{PermReadStudents, PermUploadStudents, PermDeleteStudents, PermManageCourses, PermManageScales}
+
+// rolePermissions lists what each role may do. Grading scales are shared by
+// every tenant, only platform admins change them
+var rolePermissions = map[config.Role][]Permission{
+	config.RoleViewer:        {PermReadStudents},
+	config.RoleUploader:      {PermReadStudents, PermUploadStudents},
+	config.RoleAdmin:         {PermReadStudents, PermUploadStudents, PermDeleteStudents, PermManageCourses},
+	config.RolePlatformAdmin: {PermReadStudents, PermUploadStudents, PermDeleteStudents, PermManageCourses, PermManageScales},
+}
+
+// Principal is the authenticated user of a request
+type Principal struct {
+	User   *model.User
+	Method Method
+
+	// Subjects assigned to the user
+	Subjects []string
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[p.User.Role], permission)
+}
+
+// IsAdmin is true for the admins of the tenant, platform admins included
+func (p *Principal) IsAdmin() bool {
+	return p.User.Role == config.RoleAdmin || p.User.Role == config.RolePlatformAdmin
+}
+
+// Scope returns the subjects the principal may see and upload, nil means
+// every subject. Admins and users granted every subject are never scoped,
+// others without assigned subjects get none
+func (p *Principal) Scope() []string {
+	if p.IsAdmin() || p.User.AllSubjects {
+		return nil
+	}
+	if p.Subjects == nil {
+		return []string{}
+	}
+	return p.Subjects
+}
+
+type Service struct {
+	Users      repository.UserRepository
+	Sessions   repository.SessionRepository
+	Tokens     repository.APITokenRepository
+	SessionTTL time.Duration
+}
+
+func NewService(
+	users repository.UserRepository,
+	sessions repository.SessionRepository,
+	tokens repository.APITokenRepository,
+) *Service {
+	return &Service{
+		Users:      users,
+		Sessions:   sessions,
+		Tokens:     tokens,
+		SessionTTL: DefaultSessionTTL,
+	}
+}
+
+// Register creates a user of the default tenant with a hashed password
+func (s *Service) Register(email, password string) (*model.User, error) {
+	return s.RegisterIn(config.DefaultTenantID, email, password)
+}
+
+// RegisterIn creates a user of a tenant with a hashed password
+func (s *Service) RegisterIn(tenantID uuid.UUID, email, password string) (*model.User, error) {
+	email = normalizeEmail(email)
+	if email == "" || len(password) < MinPasswordLength {
+		return nil, config.ErrMissingUserData
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{TenantID: tenantID, Email: email, PasswordHash: string(hash)}
+	if err := s.Users.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login checks a password and opens a session, the returned token is only
+// known to the caller
+func (s *Service) Login(email, password string) (string, *model.Session, error) {
+	user, err := s.Users.GetByEmail(normalizeEmail(email))
+	if errors.Is(err, config.ErrUserNotExist) {
+		// Spend the same time as a wrong password so emails can't be probed
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return "", nil, config.ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, config.ErrInvalidCredentials
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := config.SessionTokenPrefix + secret
+
+	session := &model.Session{
+		ID:        hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.SessionTTL),
+	}
+	if err := s.Sessions.Create(session); err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// Logout ends the session of a token
+func (s *Service) Logout(token string) error {
+	return s.Sessions.Delete(hashToken(token))
+}
+
+// Authenticate resolves a session or api token to its user, tokens without
+// the api token prefix are sessions
+func (s *Service) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, config.ErrUnauthenticated
+	}
+
+	var userID uuid.UUID
+	method := MethodSession
+
+	if strings.HasPrefix(token, config.APITokenPrefix) {
+		apiToken, err := s.Tokens.GetByHash(hashToken(token))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Tokens.Touch(apiToken.ID, time.Now()); err != nil {
+			return nil, err
+		}
+		userID = apiToken.UserID
+		method = MethodAPIToken
+	} else {
+		session, err := s.Sessions.Get(hashToken(token))
+		if err != nil {
+			return nil, err
+		}
+		userID = session.UserID
+	}
+
+	user, err := s.Users.Get(userID)
+	if errors.Is(err, config.ErrUserNotExist) {
+		return nil, config.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.Users.Subjects(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{User: user, Method: method, Subjects: subjects}, nil
+}
+
+// CreateAPIToken issues a token for the user, a zero ttl never expires. The
+// plain token is returned once and can't be recovered
+func (s *Service) CreateAPIToken(userID uuid.UUID, name string, ttl time.Duration) (string, *model.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, config.ErrMissingUserData
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := config.APITokenPrefix + secret
+
+	apiToken := &model.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    token[:len(config.APITokenPrefix)+6],
+		TokenHash: hashToken(token),
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	if err := s.Tokens.Create(apiToken); err != nil {
+		return "", nil, err
+	}
+	return token, apiToken, nil
+}
+
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return hash
+})
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken is what is stored, tokens are random so a fast hash is enough
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
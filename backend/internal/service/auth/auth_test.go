@@ -0,0 +1,250 @@
+package auth_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"file-uploader/internal/service/auth"
+	testutils "file-uploader/internal/test-utils"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testDB *gorm.DB
+var testService *auth.Service
+
+func TestMain(m *testing.M) {
+	err := godotenv.Load("../../../.env")
+	if err != nil {
+		log.Fatalf("Failed to load .env file: %v", err)
+	}
+
+	db, _, err := testutils.LoadDb()
+	if err != nil {
+		log.Fatalf("Failed to load test DB: %v", err)
+	}
+
+	testDB = db
+	testService = auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
+		repository.NewAPITokenRepository(db),
+	)
+
+	// Run tests
+	code := m.Run()
+
+	// Cleanup
+	testutils.CloseDb(testDB)
+
+	os.Exit(code)
+}
+
+func TestRegister(t *testing.T) {
+	testDB.Where("email = ?", "register@example.com").Delete(&model.User{})
+
+	user, err := testService.Register("  Register@Example.com ", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "register@example.com", user.Email)
+	assert.NotContains(t, user.PasswordHash, "correct horse")
+
+	_, err = testService.Register("register@example.com", "another password")
+	assert.ErrorIs(t, err, config.ErrUserAlreadyExist)
+
+	_, err = testService.Register("short@example.com", "short")
+	assert.ErrorIs(t, err, config.ErrMissingUserData)
+}
+
+func TestSessions(t *testing.T) {
+	testDB.Where("email = ?", "session@example.com").Delete(&model.User{})
+	_, err := testService.Register("session@example.com", "correct horse")
+	require.NoError(t, err)
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, _, err := testService.Login("session@example.com", "wrong horse")
+		assert.ErrorIs(t, err, config.ErrInvalidCredentials)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		_, _, err := testService.Login("nobody@example.com", "correct horse")
+		assert.ErrorIs(t, err, config.ErrInvalidCredentials)
+	})
+
+	t.Run("login, authenticate and logout", func(t *testing.T) {
+		token, session, err := testService.Login("SESSION@example.com", "correct horse")
+		require.NoError(t, err)
+		assert.NotEqual(t, token, session.ID, "only the hash of the token is stored")
+		assert.True(t, strings.HasPrefix(token, config.SessionTokenPrefix))
+		assert.False(t, strings.HasPrefix(token, config.APITokenPrefix), "sessions are never looked up as api tokens")
+
+		principal, err := testService.Authenticate(token)
+		require.NoError(t, err)
+		assert.Equal(t, "session@example.com", principal.User.Email)
+		assert.Equal(t, auth.MethodSession, principal.Method)
+
+		require.NoError(t, testService.Logout(token))
+		_, err = testService.Authenticate(token)
+		assert.ErrorIs(t, err, config.ErrUnauthenticated)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		service := *testService
+		service.SessionTTL = -time.Minute
+
+		token, _, err := service.Login("session@example.com", "correct horse")
+		require.NoError(t, err)
+
+		_, err = testService.Authenticate(token)
+		assert.ErrorIs(t, err, config.ErrUnauthenticated)
+	})
+}
+
+func TestAPITokens(t *testing.T) {
+	testDB.Where("email = ?", "token@example.com").Delete(&model.User{})
+	user, err := testService.Register("token@example.com", "correct horse")
+	require.NoError(t, err)
+
+	token, apiToken, err := testService.CreateAPIToken(user.ID, "nightly import", 0)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, config.APITokenPrefix))
+	assert.True(t, strings.HasPrefix(token, apiToken.Prefix))
+	assert.Nil(t, apiToken.ExpiresAt)
+
+	principal, err := testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, principal.User.ID)
+	assert.Equal(t, auth.MethodAPIToken, principal.Method)
+
+	tokens, err := testService.Tokens.List(user.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	// Revoked and unknown tokens are rejected
+	require.NoError(t, testService.Tokens.Revoke(user.ID, apiToken.ID))
+	_, err = testService.Authenticate(token)
+	assert.ErrorIs(t, err, config.ErrUnauthenticated)
+	assert.ErrorIs(t, testService.Tokens.Revoke(user.ID, apiToken.ID), config.ErrTokenNotExist)
+
+	_, err = testService.Authenticate(config.APITokenPrefix + "made-up")
+	assert.ErrorIs(t, err, config.ErrUnauthenticated)
+
+	// Expired tokens are rejected
+	token, _, err = testService.CreateAPIToken(user.ID, "short lived", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = testService.Authenticate(token)
+	assert.ErrorIs(t, err, config.ErrUnauthenticated)
+}
+
+func TestRolesAndScope(t *testing.T) {
+	testDB.Where("email = ?", "teacher@example.com").Delete(&model.User{})
+	user, err := testService.Register("teacher@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, config.RoleViewer, user.Role)
+
+	token, _, err := testService.CreateAPIToken(user.ID, "scripts", 0)
+	require.NoError(t, err)
+
+	principal, err := testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.True(t, principal.Can(auth.PermReadStudents))
+	assert.False(t, principal.Can(auth.PermUploadStudents))
+	assert.Equal(t, []string{}, principal.Scope(), "users without subjects see none")
+
+	require.NoError(t, testService.Users.SetAllSubjects(user.ID, true))
+	principal, err = testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.Nil(t, principal.Scope(), "users granted every subject are unscoped")
+
+	assert.ErrorIs(t, testService.Users.SetRole(user.ID, "owner"), config.ErrInvalidRole)
+	require.NoError(t, testService.Users.SetRole(user.ID, config.RoleUploader))
+
+	err = testService.Users.SetSubjects(user.ID, []string{"Economics"})
+	assert.ErrorIs(t, err, config.ErrCourseNotExist)
+
+	err = testService.Users.SetSubjects(user.ID, []string{string(config.Physics), string(config.Art), string(config.Physics)})
+	require.NoError(t, err)
+
+	principal, err = testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.True(t, principal.Can(auth.PermUploadStudents))
+	assert.False(t, principal.Can(auth.PermManageCourses))
+	assert.Equal(t, []string{string(config.Art), string(config.Physics)}, principal.Scope(), "assigning subjects limits the user to them")
+
+	// Admins see every subject, even with assignments
+	require.NoError(t, testService.Users.SetRole(user.ID, config.RoleAdmin))
+	principal, err = testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.True(t, principal.Can(auth.PermManageCourses))
+	assert.Nil(t, principal.Scope())
+
+	require.NoError(t, testService.Users.SetSubjects(user.ID, nil))
+	subjects, err := testService.Users.Subjects(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestScopeAfterCourseDeletion(t *testing.T) {
+	testDB.Where("email = ?", "deleted-course@example.com").Delete(&model.User{})
+	user, err := testService.Register("deleted-course@example.com", "correct horse")
+	require.NoError(t, err)
+
+	courses := repository.NewCourseRepository(testDB)
+	courses.Delete("Astronomy")
+	require.NoError(t, courses.Create(&model.Course{Name: "Astronomy"}))
+	require.NoError(t, testService.Users.SetSubjects(user.ID, []string{"Astronomy"}))
+
+	token, _, err := testService.CreateAPIToken(user.ID, "scripts", 0)
+	require.NoError(t, err)
+
+	principal, err := testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Astronomy"}, principal.Scope())
+
+	// Deleting the only course of the user drops the assignment, the user
+	// then sees no subject instead of every one
+	require.NoError(t, courses.Delete("Astronomy"))
+
+	principal, err = testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.Empty(t, principal.Subjects)
+	assert.NotNil(t, principal.Scope())
+	assert.Empty(t, principal.Scope())
+}
+
+func TestRegisterInTenant(t *testing.T) {
+	testDB.Where("email = ?", "tenant@example.com").Delete(&model.User{})
+
+	tenant := &model.Tenant{Name: "Auth School " + uuid.NewString()}
+	require.NoError(t, repository.NewTenantRepository(testDB).Create(tenant))
+	require.NoError(t, repository.NewCourseRepository(testDB).ForTenant(tenant.ID).Create(&model.Course{Name: "Auth Elective"}))
+
+	user, err := testService.RegisterIn(tenant.ID, "tenant@example.com", "correct horse")
+	require.NoError(t, err)
+
+	token, _, err := testService.CreateAPIToken(user.ID, "scripts", 0)
+	require.NoError(t, err)
+
+	principal, err := testService.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.ID, principal.User.TenantID)
+
+	// Subjects come from the catalog of the user's tenant
+	require.NoError(t, testService.Users.SetSubjects(user.ID, []string{"Auth Elective"}))
+
+	testDB.Where("email = ?", "default@example.com").Delete(&model.User{})
+	other, err := testService.Register("default@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultTenantID, other.TenantID)
+	assert.ErrorIs(t, testService.Users.SetSubjects(other.ID, []string{"Auth Elective"}), config.ErrCourseNotExist)
+}
@@ -0,0 +1,195 @@
+package processor
+
+import (
+	"context"
+	"file-uploader/database/repository"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// batch is a run of records of a file, seq orders the batches for the
+// checkpoints and start is the number of records before it
+type batch[T any] struct {
+	seq   int
+	start int64
+	items []*T
+}
+
+// insertPipeline inserts the batches sent by the parser. With workers it
+// runs them concurrently behind a queue of as many batches, so the parser
+// blocks once the database falls behind, without workers each batch is
+// inserted before the parser goes on
+type insertPipeline[T any] struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	repo   repository.StudentRepository[T]
+
+	queue  chan batch[T]
+	wg     sync.WaitGroup
+	next   int
+	offset int64
+
+	// Records before replayed may have been stored by an earlier run, they
+	// are inserted skipping duplicates. dispatched is told about the others
+	// before they are inserted
+	replayed   int64
+	dispatched func(rows int64) error
+
+	checkpoints *checkpoints
+
+	// stored counts the records inserted, batches done after a failure
+	// included
+	stored atomic.Int64
+}
+
+func newInsertPipeline[T any](
+	ctx context.Context,
+	workers int,
+	repo repository.StudentRepository[T],
+	committed int64,
+	checkpoint func(rows int64),
+) *insertPipeline[T] {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &insertPipeline[T]{
+		ctx:         ctx,
+		cancel:      cancel,
+		repo:        repo,
+		offset:      committed,
+		checkpoints: newCheckpoints(committed, checkpoint),
+		dispatched:  func(int64) error { return nil },
+	}
+
+	if workers > 0 {
+		p.queue = make(chan batch[T], workers)
+		for range workers {
+			p.wg.Add(1)
+			go p.work()
+		}
+	}
+	return p
+}
+
+// replay sets the records an earlier run dispatched and what to tell before
+// inserting the records past them
+func (p *insertPipeline[T]) replay(dispatched int64, report func(rows int64) error) {
+	p.replayed = dispatched
+	p.dispatched = report
+}
+
+// endsReplay is true when n more records reach the end of the replayed ones
+func (p *insertPipeline[T]) endsReplay(n int) bool {
+	return p.offset < p.replayed && p.offset+int64(n) >= p.replayed
+}
+
+func (p *insertPipeline[T]) work() {
+	defer p.wg.Done()
+
+	// Batches left after a failure are drained without inserting them
+	for b := range p.queue {
+		if p.ctx.Err() == nil {
+			p.insert(b)
+		}
+	}
+}
+
+func (p *insertPipeline[T]) insert(b batch[T]) {
+	repo := p.repo
+	if b.start < p.replayed {
+		repo = repo.SkipDuplicates()
+	}
+
+	if err := repo.CreateMany(b.items); err != nil {
+		p.cancel(fmt.Errorf("error inserting batch : %v", err))
+		return
+	}
+	p.stored.Add(int64(len(b.items)))
+	p.checkpoints.done(b.seq, int64(len(b.items)))
+}
+
+// send hands the items to the inserts, it blocks while the queue is full and
+// returns the cause once the pipeline is cancelled
+func (p *insertPipeline[T]) send(items []*T) error {
+	b := batch[T]{seq: p.next, start: p.offset, items: items}
+	p.next++
+	p.offset += int64(len(items))
+
+	if err := context.Cause(p.ctx); err != nil {
+		return err
+	}
+
+	// Past the replayed records, a resume must know the batch may be stored
+	// before it is
+	if p.offset > p.replayed {
+		if err := p.dispatched(p.offset); err != nil {
+			p.cancel(fmt.Errorf("error dispatching batch : %v", err))
+			return context.Cause(p.ctx)
+		}
+	}
+
+	if p.queue == nil {
+		p.insert(b)
+		return context.Cause(p.ctx)
+	}
+
+	select {
+	case p.queue <- b:
+		return nil
+	case <-p.ctx.Done():
+		return context.Cause(p.ctx)
+	}
+}
+
+// fail stops the pipeline with the error of the parser
+func (p *insertPipeline[T]) fail(err error) {
+	p.cancel(err)
+}
+
+// wait lets the queued batches finish and returns the first error of the
+// pipeline, or of its context
+func (p *insertPipeline[T]) wait() error {
+	if p.queue != nil {
+		close(p.queue)
+		p.wg.Wait()
+	}
+	err := context.Cause(p.ctx)
+	p.cancel(nil)
+	return err
+}
+
+// checkpoints report the records stored in file order, a batch done early
+// is held until every batch before it is done so a resume never skips
+// records that weren't stored
+type checkpoints struct {
+	mu        sync.Mutex
+	committed int64
+	next      int
+	pending   map[int]int64
+	report    func(rows int64)
+}
+
+func newCheckpoints(committed int64, report func(rows int64)) *checkpoints {
+	return &checkpoints{committed: committed, pending: map[int]int64{}, report: report}
+}
+
+func (c *checkpoints) done(seq int, rows int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending[seq] = rows
+	advanced := false
+	for {
+		rows, exist := c.pending[c.next]
+		if !exist {
+			break
+		}
+		delete(c.pending, c.next)
+		c.committed += rows
+		c.next++
+		advanced = true
+	}
+
+	if advanced {
+		c.report(c.committed)
+	}
+}
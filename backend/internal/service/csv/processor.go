@@ -26,6 +26,10 @@ type ProcessStatus struct {
 	Percent  float64
 	Timeleft float64
 	Error    string
+
+	// Position is the place of the upload in the job queue, zero once it is
+	// processed
+	Position int
 }
 
 func (c *CountingReader) Read(p []byte) (int, error) {
@@ -40,6 +44,91 @@ func ToRecordMapper[T any](fn func([]string) (*T, error)) RecordMapper[T] {
 	return RecordMapper[T](fn)
 }
 
+// ProcessOption tunes ProcessCSV, options receive the id of the file
+type ProcessOption func(*processOptions)
+
+type processOptions struct {
+	skip       func(id int) int64
+	replay     func(id int) int64
+	checkpoint func(id int, rows int64)
+	dispatch   func(id int, rows int64) error
+	workers    int
+	report     func(id int, report FileReport)
+}
+
+// FileReport sums up the processing of a file, the records skipped on resume
+// aren't counted
+type FileReport struct {
+	// Bytes of the file read, the header and skipped records included
+	Bytes int64
+
+	// Read records, up to the first malformed one
+	Read int64
+
+	// Stored records, Read - Stored were rejected
+	Stored int64
+
+	// Done is true once every record of the file was read and stored, a
+	// failed or cancelled file is never done whatever its progress
+	Done bool
+}
+
+// WithResume skips the records of each file stored by an earlier run,
+// committed is indexed by file id
+func WithResume(committed map[int]int64) ProcessOption {
+	return func(o *processOptions) {
+		o.skip = func(id int) int64 { return committed[id] }
+	}
+}
+
+// WithReplay inserts the records of each file up to dispatched, indexed by
+// file id, skipping the ones already stored. An earlier run may have stored
+// records past its last checkpoint, up to the ones it dispatched
+func WithReplay(dispatched map[int]int64) ProcessOption {
+	return func(o *processOptions) {
+		o.replay = func(id int) int64 { return dispatched[id] }
+	}
+}
+
+// WithDispatch reports the number of records of a file handed to the inserts
+// before they are inserted, an error stops the file
+func WithDispatch(dispatch func(id int, rows int64) error) ProcessOption {
+	return func(o *processOptions) {
+		o.dispatch = dispatch
+	}
+}
+
+// WithCheckpoint reports the number of records of a file stored so far
+// after every batch
+func WithCheckpoint(checkpoint func(id int, rows int64)) ProcessOption {
+	return func(o *processOptions) {
+		o.checkpoint = checkpoint
+	}
+}
+
+// WithInsertWorkers inserts the batches of a file on n goroutines while the
+// parser reads ahead, n batches at most. Zero inserts each batch before
+// reading on
+func WithInsertWorkers(n int) ProcessOption {
+	return func(o *processOptions) {
+		o.workers = max(n, 0)
+	}
+}
+
+// WithReport hands the report of each file to report once it is processed
+// or has failed, after the reports of earlier options
+func WithReport(report func(id int, report FileReport)) ProcessOption {
+	return func(o *processOptions) {
+		previous := o.report
+		o.report = func(id int, r FileReport) {
+			previous(id, r)
+			report(id, r)
+		}
+	}
+}
+
+// ProcessCSV reads the records of a file and inserts them in batches, the
+// parser runs ahead of the inserts and the first error stops both
 func ProcessCSV[T any](
 	ctx context.Context,
 	id int,
@@ -48,7 +137,20 @@ func ProcessCSV[T any](
 	batchSize int,
 	studentRepo repository.StudentRepository[T],
 	mapper RecordMapper[T],
-	status chan ProcessStatus) error {
+	status chan ProcessStatus,
+	opts ...ProcessOption) error {
+
+	options := processOptions{
+		skip:       func(int) int64 { return 0 },
+		replay:     func(int) int64 { return 0 },
+		checkpoint: func(int, int64) {},
+		dispatch:   func(int, int64) error { return nil },
+		workers:    1,
+		report:     func(int, FileReport) {},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
 
 	// Send initial status update immediately
 	status <- ProcessStatus{
@@ -77,10 +179,64 @@ func ProcessCSV[T any](
 		return fmt.Errorf("error reading CSV header: %v", err)
 	}
 
+	// Skip the records stored before an interruption
+	committed := options.skip(id)
+	for range committed {
+		if _, err := reader.Read(); err != nil {
+			return fmt.Errorf("error skipping imported records : %v", err)
+		}
+	}
+
+	pipeline := newInsertPipeline(ctx, options.workers, studentRepo, committed, func(rows int64) {
+		options.checkpoint(id, rows)
+	})
+	pipeline.replay(options.replay(id), func(rows int64) error {
+		return options.dispatch(id, rows)
+	})
+	read, err := parseCSV(reader, countingReader, id, fileSize, batchSize, mapper, status, pipeline)
+	if err != nil {
+		pipeline.fail(err)
+	}
+	done := false
+	defer func() {
+		options.report(id, FileReport{Bytes: countingReader.N, Read: read, Stored: pipeline.stored.Load(), Done: done})
+	}()
+
+	// Wait for the queued batches, the first error wins
+	if err := pipeline.wait(); err != nil {
+		if ctx.Err() != nil {
+			log.Printf("CSV processing cancelled: %v", ctx.Err())
+			return ctx.Err()
+		}
+		return err
+	}
+	done = true
+
+	status <- ProcessStatus{
+		Id:       id,
+		Percent:  100,
+		Timeleft: 0,
+	}
+	return nil
+}
+
+// parseCSV maps the records and sends them to the pipeline in batches of
+// batchSize, reporting progress by the bytes read. It returns the number
+// of records read
+func parseCSV[T any](
+	reader *csv.Reader,
+	countingReader *CountingReader,
+	id int,
+	fileSize int64,
+	batchSize int,
+	mapper RecordMapper[T],
+	status chan ProcessStatus,
+	pipeline *insertPipeline[T],
+) (int64, error) {
 	buffer := make([]*T, 0, batchSize)
 	startTime := time.Now()
 	lastStatusUpdate := time.Now()
-	recordCount := 0
+	var recordCount int64
 
 	// Read the csv file line by line
 	for {
@@ -90,7 +246,7 @@ func ProcessCSV[T any](
 		}
 
 		if err != nil {
-			return fmt.Errorf("error reading csv file : %v", err)
+			return recordCount, fmt.Errorf("error reading csv file : %v", err)
 		}
 
 		recordCount++
@@ -98,21 +254,18 @@ func ProcessCSV[T any](
 		// Map CSV record to struct
 		entity, err := mapper(record)
 		if err != nil {
-			return fmt.Errorf("error mapping csv record :%v", err)
+			return recordCount, fmt.Errorf("error mapping csv record :%v", err)
 		}
 
 		buffer = append(buffer, entity)
 
-		// If buffer reaches batch size, insert into db
-		if len(buffer) >= batchSize {
-			// Check context before database operation
-			if ctxErr := ctx.Err(); ctxErr != nil {
-				return ctxErr
-			}
-			if err := studentRepo.CreateMany(buffer); err != nil {
-				return fmt.Errorf("error inserting batch : %v", err)
+		// If buffer reaches batch size, hand it to the inserts, the buffer
+		// belongs to them from then on. Replayed records are sent apart
+		if len(buffer) >= batchSize || pipeline.endsReplay(len(buffer)) {
+			if err := pipeline.send(buffer); err != nil {
+				return recordCount, err
 			}
-			buffer = buffer[:0] // clear buffer
+			buffer = make([]*T, 0, batchSize)
 		}
 
 		updateInterval := 100 * time.Millisecond
@@ -136,31 +289,11 @@ func ProcessCSV[T any](
 		}
 	}
 
-	// Check for context cancellation
-	select {
-	case <-ctx.Done():
-		log.Printf("CSV processing cancelled: %v", ctx.Err())
-		return ctx.Err()
-	default:
-	}
-
 	// Insert any remaining records in the buffer
 	if len(buffer) > 0 {
-		// Check context before final database operation
-		if ctxErr := ctx.Err(); ctxErr != nil {
-			return ctxErr
-		}
-		if err := studentRepo.CreateMany(buffer); err != nil {
-			return fmt.Errorf("error inserting final batch : %v", err)
-		}
+		return recordCount, pipeline.send(buffer)
 	}
-
-	status <- ProcessStatus{
-		Id:       id,
-		Percent:  100,
-		Timeleft: 0,
-	}
-	return nil
+	return recordCount, nil
 }
 
 // MapStudentData creates a Student or StudentTest struct from a CSV record
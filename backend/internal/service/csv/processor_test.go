@@ -2,6 +2,8 @@ package processor_test
 
 import (
 	"context"
+	"errors"
+	"file-uploader/config"
 	"file-uploader/database/model"
 	"file-uploader/database/repository"
 	processor "file-uploader/internal/service/csv"
@@ -11,11 +13,14 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
@@ -44,8 +49,7 @@ func TestMain(m *testing.M) {
 	testDB.Migrator().DropTable(model.StudentTest{})
 
 	// Cleanup
-	sqlDB, _ := testDB.DB()
-	sqlDB.Close()
+	testutils.CloseDb(testDB)
 
 	os.Exit(code)
 }
@@ -115,6 +119,292 @@ func TestCSVProcessor(t *testing.T) {
 	})
 }
 
+func TestCSVProcessorInMemory(t *testing.T) {
+	const (
+		testFilesDir  = "/tmp/testDirMemory/"
+		recordsLength = 25
+		batchSize     = 10
+	)
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+
+	filepath, err := Seeder.SeedStudentsCSV("test.csv", testFilesDir, recordsLength)
+	require.NoError(t, err)
+	defer Seeder.RemoveSeededCSVs(testFilesDir)
+
+	f, err := os.Open(filepath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	fileStat, err := f.Stat()
+	require.NoError(t, err)
+
+	status := make(chan processor.ProcessStatus)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err = processor.ProcessCSV(context.TODO(), 0, f, fileStat.Size(), batchSize, memoryRepo, StudentTestMapper, status)
+		close(status)
+	}()
+
+	for range status {
+	}
+	wg.Wait()
+	require.NoError(t, err)
+
+	// Every row is inserted, including the last partial batch
+	_, count, err := memoryRepo.Query(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(recordsLength), count)
+}
+
+func TestCSVProcessorResume(t *testing.T) {
+	const (
+		recordsLength = 25
+		batchSize     = 10
+	)
+
+	var content strings.Builder
+	content.WriteString(config.StudentsTableHeader + "\n")
+	for i := range recordsLength {
+		fmt.Fprintf(&content, "%s,Student %02d,Physics,%d\n", uuid.NewString(), i, 50+i)
+	}
+
+	memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	checkpoints := []int64{}
+
+	status := make(chan processor.ProcessStatus)
+	go func() {
+		for range status {
+		}
+	}()
+
+	// The first 12 records were stored before an interruption
+	err := processor.ProcessCSV(context.TODO(), 3, strings.NewReader(content.String()), int64(content.Len()), batchSize,
+		memoryRepo, StudentTestMapper, status,
+		processor.WithResume(map[int]int64{3: 12}),
+		processor.WithCheckpoint(func(id int, rows int64) {
+			assert.Equal(t, 3, id)
+			checkpoints = append(checkpoints, rows)
+		}),
+	)
+	close(status)
+	require.NoError(t, err)
+
+	students, count, err := memoryRepo.Query([]repository.QueryOption{repository.WithSort(config.Name, config.SortAsc)}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(recordsLength-12), count)
+	assert.Equal(t, "Student 12", students[0].Student_name)
+
+	// Checkpoints count the skipped records too
+	assert.Equal(t, []int64{22, 25}, checkpoints)
+}
+
+func TestCSVProcessorReplay(t *testing.T) {
+	const (
+		recordsLength = 25
+		batchSize     = 10
+	)
+
+	content := studentsCSV(recordsLength)
+	records := strings.Split(strings.TrimSpace(content), "\n")[1:]
+
+	// The run before the crash checkpointed 12 records, dispatched 20 and
+	// stored 13 to 16 without reaching the checkpoint
+	stored := func(t *testing.T) repository.StudentRepository[model.StudentTest] {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+		var students []*model.StudentTest
+		for _, record := range records[12:16] {
+			student, err := StudentTestMapper(strings.Split(record, ","))
+			require.NoError(t, err)
+			students = append(students, student)
+		}
+		require.NoError(t, memoryRepo.CreateMany(students))
+		return memoryRepo
+	}
+
+	t.Run("replays the dispatched records", func(t *testing.T) {
+		memoryRepo := stored(t)
+		dispatched := []int64{}
+
+		err := process(memoryRepo, content, batchSize,
+			processor.WithResume(map[int]int64{0: 12}),
+			processor.WithReplay(map[int]int64{0: 20}),
+			processor.WithDispatch(func(id int, rows int64) error {
+				dispatched = append(dispatched, rows)
+				return nil
+			}),
+		)
+		require.NoError(t, err)
+
+		_, count, err := memoryRepo.Query(nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(recordsLength-12), count)
+
+		// Replayed records were dispatched already
+		assert.Equal(t, []int64{25}, dispatched)
+	})
+
+	t.Run("fails without the replay", func(t *testing.T) {
+		err := process(stored(t), content, batchSize, processor.WithResume(map[int]int64{0: 12}))
+		assert.ErrorContains(t, err, config.ErrDuplicateStudent.Error())
+	})
+
+	t.Run("duplicates past the replay still fail", func(t *testing.T) {
+		err := process(stored(t), content, batchSize,
+			processor.WithResume(map[int]int64{0: 12}),
+			processor.WithReplay(map[int]int64{0: 14}),
+		)
+		assert.ErrorContains(t, err, config.ErrDuplicateStudent.Error())
+	})
+
+	t.Run("a failed dispatch stops the file", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+		err := process(memoryRepo, content, batchSize,
+			processor.WithDispatch(func(id int, rows int64) error { return errors.New("database is gone") }),
+		)
+		assert.ErrorContains(t, err, "database is gone")
+
+		_, count, err := memoryRepo.Query(nil, nil)
+		require.NoError(t, err)
+		assert.Zero(t, count, "nothing is inserted before it is dispatched")
+	})
+}
+
+// slowRepo delays every insert like a database would, and fails the batches
+// starting with a failing student
+type slowRepo struct {
+	repository.StudentRepository[model.StudentTest]
+	delay   time.Duration
+	failing string
+}
+
+func (r *slowRepo) CreateMany(items []*model.StudentTest) error {
+	time.Sleep(r.delay)
+	if r.failing != "" && items[0].Student_name == r.failing {
+		return errors.New("insert failed")
+	}
+	return r.StudentRepository.CreateMany(items)
+}
+
+// studentsCSV holds count valid records under the header
+func studentsCSV(count int) string {
+	var content strings.Builder
+	content.WriteString(config.StudentsTableHeader + "\n")
+	for i := range count {
+		fmt.Fprintf(&content, "%s,Student %05d,Physics,%d\n", uuid.NewString(), i, 50+i%50)
+	}
+	return content.String()
+}
+
+func process(repo repository.StudentRepository[model.StudentTest], content string, batchSize int, opts ...processor.ProcessOption) error {
+	status := make(chan processor.ProcessStatus)
+	go func() {
+		for range status {
+		}
+	}()
+	defer close(status)
+
+	return processor.ProcessCSV(context.TODO(), 0, strings.NewReader(content), int64(len(content)), batchSize,
+		repo, StudentTestMapper, status, opts...)
+}
+
+func TestCSVProcessorPipeline(t *testing.T) {
+	const (
+		recordsLength = 95
+		batchSize     = 10
+	)
+	content := studentsCSV(recordsLength)
+
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("%d workers insert every record", workers), func(t *testing.T) {
+			memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+			var mu sync.Mutex
+			checkpoints := []int64{}
+
+			err := process(&slowRepo{StudentRepository: memoryRepo, delay: time.Millisecond}, content, batchSize,
+				processor.WithInsertWorkers(workers),
+				processor.WithCheckpoint(func(id int, rows int64) {
+					mu.Lock()
+					defer mu.Unlock()
+					checkpoints = append(checkpoints, rows)
+				}),
+			)
+			require.NoError(t, err)
+
+			_, count, err := memoryRepo.Query(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(recordsLength), count)
+
+			// Checkpoints only grow and end on the last record
+			assert.IsIncreasing(t, checkpoints)
+			assert.Equal(t, int64(recordsLength), checkpoints[len(checkpoints)-1])
+		})
+	}
+
+	t.Run("a failed insert stops the pipeline", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+		checkpoints := []int64{}
+
+		err := process(&slowRepo{StudentRepository: memoryRepo, failing: "Student 00030"}, content, batchSize,
+			processor.WithInsertWorkers(1),
+			processor.WithCheckpoint(func(id int, rows int64) { checkpoints = append(checkpoints, rows) }),
+		)
+		assert.ErrorContains(t, err, "insert failed")
+
+		// Nothing past the failed batch is checkpointed
+		require.NotEmpty(t, checkpoints)
+		assert.LessOrEqual(t, checkpoints[len(checkpoints)-1], int64(30))
+	})
+
+	t.Run("a malformed record stops the pipeline", func(t *testing.T) {
+		memoryRepo := repository.NewMemoryStudentRepository[model.StudentTest]()
+		broken := content + "not-a-uuid,Student,Physics,50\n"
+
+		err := process(memoryRepo, broken, batchSize, processor.WithInsertWorkers(4))
+		assert.ErrorContains(t, err, "error mapping csv record")
+	})
+
+	t.Run("cancellation stops the pipeline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		repo := &slowRepo{StudentRepository: repository.NewMemoryStudentRepository[model.StudentTest](), delay: 10 * time.Millisecond}
+
+		status := make(chan processor.ProcessStatus)
+		go func() {
+			for range status {
+			}
+		}()
+		defer close(status)
+
+		time.AfterFunc(15*time.Millisecond, cancel)
+		err := processor.ProcessCSV(ctx, 0, strings.NewReader(content), int64(len(content)), batchSize,
+			repo, StudentTestMapper, status, processor.WithInsertWorkers(2))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// BenchmarkProcessCSV compares reading and inserting in turn, zero workers,
+// with the pipeline, on an insert latency close to a database
+func BenchmarkProcessCSV(b *testing.B) {
+	const (
+		recordsLength = 20_000
+		batchSize     = 1000
+	)
+	content := studentsCSV(recordsLength)
+
+	for _, workers := range []int{0, 1, 2, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				repo := &slowRepo{StudentRepository: repository.NewMemoryStudentRepository[model.StudentTest](), delay: 5 * time.Millisecond}
+				require.NoError(b, process(repo, content, batchSize, processor.WithInsertWorkers(workers)))
+			}
+			b.ReportMetric(float64(recordsLength*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
 func StudentTestMapper(record []string) (*model.StudentTest, error) {
 	studentID, err := uuid.Parse(record[0])
 	if err != nil {
@@ -0,0 +1,121 @@
+package Seeder
+
+import (
+	"file-uploader/config"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+type DistributionKind string
+
+const (
+	Normal  DistributionKind = "normal"
+	Uniform DistributionKind = "uniform"
+)
+
+// Uploads reject a grade of 0 as missing
+const (
+	minGrade = 1
+	maxGrade = 100
+)
+
+// Distribution draws grades between 1 and 100, the range uploads accept,
+// normal grades are clamped
+type Distribution struct {
+	Kind DistributionKind
+
+	Mean   float64 // normal
+	StdDev float64 // normal
+	Min    int     // uniform
+	Max    int     // uniform
+}
+
+// DefaultDistribution applies to subjects without a distribution of their own
+var DefaultDistribution = Distribution{Kind: Normal, Mean: 72, StdDev: 13}
+
+// DefaultDistributions mimic the spread of grades per course, sciences are
+// graded harder than the arts
+var DefaultDistributions = map[string]Distribution{
+	string(config.Mathematics): {Kind: Normal, Mean: 66, StdDev: 16},
+	string(config.Physics):     {Kind: Normal, Mean: 65, StdDev: 15},
+	string(config.Chemistry):   {Kind: Normal, Mean: 67, StdDev: 14},
+	string(config.Biology):     {Kind: Normal, Mean: 71, StdDev: 12},
+	string(config.History):     {Kind: Normal, Mean: 73, StdDev: 11},
+	string(config.EnglishLit):  {Kind: Normal, Mean: 74, StdDev: 10},
+	string(config.CompSci):     {Kind: Normal, Mean: 70, StdDev: 17},
+	string(config.Art):         {Kind: Normal, Mean: 80, StdDev: 9},
+	string(config.Music):       {Kind: Normal, Mean: 79, StdDev: 10},
+	string(config.Geography):   {Kind: Normal, Mean: 72, StdDev: 11},
+}
+
+// Draw returns a grade between 1 and 100
+func (d Distribution) Draw(rng *rand.Rand) uint {
+	if d.Kind == Uniform {
+		return uint(d.Min + rng.Intn(d.Max-d.Min+1))
+	}
+
+	grade := math.Round(rng.NormFloat64()*d.StdDev + d.Mean)
+	return uint(min(max(grade, minGrade), maxGrade))
+}
+
+func (d Distribution) String() string {
+	if d.Kind == Uniform {
+		return fmt.Sprintf("%s:%d:%d", d.Kind, d.Min, d.Max)
+	}
+	return fmt.Sprintf("%s:%g:%g", d.Kind, d.Mean, d.StdDev)
+}
+
+// ParseDistribution reads normal:<mean>:<stddev> or uniform:<min>:<max>
+func ParseDistribution(spec string) (Distribution, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) != 3 {
+		return Distribution{}, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, spec)
+	}
+
+	a, errA := strconv.ParseFloat(parts[1], 64)
+	b, errB := strconv.ParseFloat(parts[2], 64)
+	if errA != nil || errB != nil {
+		return Distribution{}, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, spec)
+	}
+
+	switch DistributionKind(parts[0]) {
+	case Normal:
+		if a < 0 || a > maxGrade || b < 0 {
+			return Distribution{}, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, spec)
+		}
+		return Distribution{Kind: Normal, Mean: a, StdDev: b}, nil
+
+	case Uniform:
+		if a != math.Trunc(a) || b != math.Trunc(b) || a < minGrade || b > maxGrade || a > b {
+			return Distribution{}, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, spec)
+		}
+		return Distribution{Kind: Uniform, Min: int(a), Max: int(b)}, nil
+	}
+	return Distribution{}, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, spec)
+}
+
+// ParseDistributions reads comma separated <subject>=<distribution> pairs,
+// the subject * sets the distribution of every other subject
+func ParseDistributions(spec string) (map[string]Distribution, error) {
+	distributions := map[string]Distribution{}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		subject, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %q", config.ErrInvalidDistribution, pair)
+		}
+
+		distribution, err := ParseDistribution(value)
+		if err != nil {
+			return nil, err
+		}
+		distributions[strings.TrimSpace(subject)] = distribution
+	}
+	return distributions, nil
+}
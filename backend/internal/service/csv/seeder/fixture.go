@@ -0,0 +1,200 @@
+package Seeder
+
+import (
+	"encoding/json"
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture is a named set of rows bringing a tenant to a known state
+type Fixture struct {
+	// Tenant is created when missing, the default tenant when empty
+	Tenant string `yaml:"tenant" json:"tenant"`
+
+	// Courses are added to the catalog of the tenant when missing
+	Courses []string `yaml:"courses" json:"courses"`
+
+	Students []FixtureStudent `yaml:"students" json:"students"`
+}
+
+type FixtureStudent struct {
+	// StudentID is generated when empty
+	StudentID string `yaml:"student_id" json:"student_id"`
+	Name      string `yaml:"student_name" json:"student_name"`
+	Subject   string `yaml:"subject" json:"subject"`
+	Grade     uint   `yaml:"grade" json:"grade"`
+}
+
+// fixtureExtensions are tried in order when finding a fixture by name
+var fixtureExtensions = []string{".yaml", ".yml", ".json"}
+
+// FindFixture returns the path of the fixture set named name in dir
+func FindFixture(dir, name string) (string, error) {
+	for _, ext := range fixtureExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s in %s", config.ErrFixtureNotExist, name, dir)
+}
+
+// ReadFixture reads a fixture set, JSON when the file ends in .json and YAML
+// otherwise
+func ReadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, &fixture)
+	} else {
+		err = yaml.Unmarshal(content, &fixture)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", config.ErrInvalidFixture, path, err)
+	}
+	return &fixture, nil
+}
+
+// Loader inserts fixtures and generated students into the database through
+// the repositories
+type Loader[T any] struct {
+	Students repository.StudentRepository[T]
+
+	// Courses may be nil when fixtures don't add courses
+	Courses repository.CourseRepository
+
+	// Tenants may be nil when fixtures only use the default tenant
+	Tenants repository.TenantRepository
+
+	// Mapper turns the fields of config.StudentsTableHeader into a student
+	Mapper func([]string) (*T, error)
+}
+
+// chunkSize bounds the students held in memory while generating
+const chunkSize = 10_000
+
+// Tenant returns the id of the named tenant, created when missing. An empty
+// name is the default tenant
+func (l Loader[T]) Tenant(name string) (uuid.UUID, error) {
+	if strings.TrimSpace(name) == "" {
+		return config.DefaultTenantID, nil
+	}
+	if l.Tenants == nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", config.ErrTenantNotExist, name)
+	}
+
+	tenant, err := l.Tenants.GetByName(strings.TrimSpace(name))
+	if errors.Is(err, config.ErrTenantNotExist) {
+		tenant = &model.Tenant{Name: name}
+		err = l.Tenants.Create(tenant)
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return tenant.ID, nil
+}
+
+// Reset removes the students of the tenant and returns how many
+func (l Loader[T]) Reset(tenantID uuid.UUID) (int64, error) {
+	return l.Students.ForTenant(tenantID).DeleteAll()
+}
+
+// Load applies the fixture, after removing the students of its tenant when
+// reset is set. It returns the number of students inserted
+func (l Loader[T]) Load(fixture *Fixture, reset bool) (int, error) {
+	tenantID, err := l.Tenant(fixture.Tenant)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(fixture.Courses) > 0 && l.Courses == nil {
+		return 0, fmt.Errorf("%w: courses need a course repository", config.ErrInvalidFixture)
+	}
+	for _, name := range fixture.Courses {
+		courses := l.Courses.ForTenant(tenantID)
+		exists, err := courses.Exists(name)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			if err := courses.Create(&model.Course{Name: name}); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if reset {
+		if _, err := l.Reset(tenantID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(fixture.Students) == 0 {
+		return 0, nil
+	}
+
+	students := make([]*T, 0, len(fixture.Students))
+	for i, student := range fixture.Students {
+		id := student.StudentID
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		item, err := l.Mapper([]string{id, student.Name, student.Subject, strconv.FormatUint(uint64(student.Grade), 10)})
+		if err != nil {
+			return 0, fmt.Errorf("%w: student %d: %v", config.ErrInvalidFixture, i+1, err)
+		}
+		students = append(students, item)
+	}
+
+	if err := l.Students.ForTenant(tenantID).CreateMany(students); err != nil {
+		return 0, err
+	}
+	return len(students), nil
+}
+
+// Generate inserts count students drawn from the generator, in chunks so
+// any count fits in memory. Rows the mapper rejects are skipped, generators
+// injecting errors are meant for files. It returns the number of students
+// inserted
+func (l Loader[T]) Generate(tenantID uuid.UUID, generator *Generator, count int) (int, error) {
+	repo := l.Students.ForTenant(tenantID)
+	inserted := 0
+
+	for count > 0 {
+		size := min(count, chunkSize)
+		count -= size
+
+		students := make([]*T, 0, size)
+		for range size {
+			item, err := l.Mapper(generator.Next().Record())
+			if err != nil {
+				continue
+			}
+			students = append(students, item)
+		}
+		if len(students) == 0 {
+			continue
+		}
+
+		if err := repo.CreateMany(students); err != nil {
+			return inserted, err
+		}
+		inserted += len(students)
+	}
+	return inserted, nil
+}
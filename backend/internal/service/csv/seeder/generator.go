@@ -0,0 +1,170 @@
+package Seeder
+
+import (
+	"file-uploader/config"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ErrorRates are the shares of rows, between 0 and 1, broken on purpose for
+// negative testing
+type ErrorRates struct {
+	BadIDs     float64 // student ids that aren't UUIDs
+	BadGrades  float64 // grades that aren't numbers
+	Duplicates float64 // student ids of earlier rows
+}
+
+func (r ErrorRates) validate() error {
+	for _, rate := range []float64{r.BadIDs, r.BadGrades, r.Duplicates} {
+		if rate < 0 || rate > 1 {
+			return config.ErrInvalidErrorRate
+		}
+	}
+	if r.BadIDs+r.BadGrades+r.Duplicates > 1 {
+		return config.ErrInvalidErrorRate
+	}
+	return nil
+}
+
+type Options struct {
+	// Seed makes the rows reproducible, the same seed and options give the
+	// same rows
+	Seed int64
+
+	// Subjects to draw from, the default course catalog when empty
+	Subjects []string
+
+	// Distributions of grades by subject, * applies to the subjects without
+	// one, DefaultDistributions and then DefaultDistribution otherwise
+	Distributions map[string]Distribution
+
+	Errors ErrorRates
+}
+
+// Row holds the fields of config.StudentsTableHeader, broken rows included
+type Row struct {
+	StudentID string
+	Name      string
+	Subject   string
+	Grade     string
+}
+
+// Record returns the fields in the order of config.StudentsTableHeader
+func (r Row) Record() []string {
+	return []string{r.StudentID, r.Name, r.Subject, r.Grade}
+}
+
+// Generator draws student rows from a seeded source
+type Generator struct {
+	rng      *rand.Rand
+	subjects []string
+	grades   map[string]Distribution
+	errors   ErrorRates
+
+	// recent ids feed the duplicates
+	recent []string
+	next   int
+}
+
+// duplicatePool bounds the ids remembered for duplicates
+const duplicatePool = 1024
+
+// Malformed values injected in broken rows, some look almost right
+var (
+	badIDs    = []string{"", "not-a-uuid", "12345", "3f2b8c1e-0a4d-4e6b-9c7a", "3f2b8c1e-0a4d-4e6b-9c7a-1d2e3f4a5b6z"}
+	badGrades = []string{"", "N/A", "A+", "eighty", "9O", "7.5", "-"}
+)
+
+func NewGenerator(opts Options) (*Generator, error) {
+	if err := opts.Errors.validate(); err != nil {
+		return nil, err
+	}
+
+	subjects := opts.Subjects
+	if len(subjects) == 0 {
+		for _, course := range config.DefaultCourses {
+			subjects = append(subjects, string(course))
+		}
+	}
+
+	grades := make(map[string]Distribution, len(subjects))
+	for _, subject := range subjects {
+		distribution, exist := opts.Distributions[subject]
+		if !exist {
+			distribution, exist = opts.Distributions["*"]
+		}
+		if !exist {
+			distribution, exist = DefaultDistributions[subject]
+		}
+		if !exist {
+			distribution = DefaultDistribution
+		}
+		grades[subject] = distribution
+	}
+
+	return &Generator{
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		subjects: subjects,
+		grades:   grades,
+		errors:   opts.Errors,
+	}, nil
+}
+
+// Next draws a row, broken at the configured rates
+func (g *Generator) Next() Row {
+	id := g.uuid()
+	subject := g.subjects[g.rng.Intn(len(g.subjects))]
+	row := Row{
+		StudentID: id,
+		Name:      g.name(),
+		Subject:   subject,
+		Grade:     strconv.FormatUint(uint64(g.grades[subject].Draw(g.rng)), 10),
+	}
+
+	r := g.rng.Float64()
+	switch {
+	case r < g.errors.BadIDs:
+		row.StudentID = badIDs[g.rng.Intn(len(badIDs))]
+	case r < g.errors.BadIDs+g.errors.BadGrades:
+		row.Grade = badGrades[g.rng.Intn(len(badGrades))]
+	case r < g.errors.BadIDs+g.errors.BadGrades+g.errors.Duplicates && len(g.recent) > 0:
+		row.StudentID = g.recent[g.rng.Intn(len(g.recent))]
+	}
+
+	if row.StudentID == id {
+		g.remember(id)
+	}
+	return row
+}
+
+func (g *Generator) uuid() string {
+	id, err := uuid.NewRandomFromReader(g.rng)
+	if err != nil {
+		// The source never fails, keep the row valid anyway
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+func (g *Generator) name() string {
+	first := firstNames[g.rng.Intn(len(firstNames))]
+	last := lastNames[g.rng.Intn(len(lastNames))]
+
+	// Some students carry a second first name
+	if g.rng.Intn(10) == 0 {
+		return fmt.Sprintf("%s %s %s", first, firstNames[g.rng.Intn(len(firstNames))], last)
+	}
+	return first + " " + last
+}
+
+func (g *Generator) remember(id string) {
+	if len(g.recent) < duplicatePool {
+		g.recent = append(g.recent, id)
+		return
+	}
+	g.recent[g.next] = id
+	g.next = (g.next + 1) % duplicatePool
+}
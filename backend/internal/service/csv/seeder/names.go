@@ -0,0 +1,21 @@
+package Seeder
+
+// Name pools, mixed so generated classes look like real ones, with names
+// sharing a prefix to exercise search and suggestions
+var firstNames = []string{
+	"Omar", "Ali", "Saad", "Mohamed", "Ahmed", "Amira", "Fatima", "Layla", "Yusuf", "Zainab",
+	"Emma", "Olivia", "Liam", "Noah", "Ava", "Sophia", "Lucas", "Mia", "Ethan", "Isabella",
+	"Mateo", "Valentina", "Santiago", "Camila", "Diego", "Lucia", "Hiroshi", "Yuki", "Haruto", "Sakura",
+	"Wei", "Mei", "Jun", "Lin", "Arjun", "Priya", "Rohan", "Ananya", "Kwame", "Amara",
+	"Chidi", "Ngozi", "Olga", "Ivan", "Anna", "Alexei", "Sven", "Ingrid", "Luca", "Giulia",
+	"Aaliyah", "Aaron", "Abigail", "Adam", "Alexander", "Alexandra", "Alice", "Alina", "Amelia", "Andrea",
+}
+
+var lastNames = []string{
+	"Hassan", "Ibrahim", "Khalil", "Mansour", "Nasser", "Saleh", "Haddad", "Farouk", "Aziz", "Rahman",
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Miller", "Davis", "Wilson", "Taylor", "Anderson",
+	"Garcia", "Martinez", "Rodriguez", "Lopez", "Gonzalez", "Hernandez", "Perez", "Sanchez", "Ramirez", "Torres",
+	"Tanaka", "Suzuki", "Sato", "Watanabe", "Wang", "Li", "Zhang", "Chen", "Patel", "Sharma",
+	"Singh", "Gupta", "Mensah", "Okafor", "Adeyemi", "Ivanova", "Petrov", "Larsen", "Nielsen", "Rossi",
+	"Bianchi", "Muller", "Schmidt", "Schneider", "Dubois", "Moreau", "Silva", "Santos", "O'Brien", "Van der Berg",
+}
@@ -1,13 +1,12 @@
 package Seeder
 
 import (
-	"encoding/csv"
-	"file-uploader/config"
 	"file-uploader/database/model"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/google/uuid"
 )
@@ -30,50 +29,30 @@ func SeedStudentsCSV(filename, saveLocation string, length int) (string, error)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	header := []string{"Student_id", "Student_name", "Subject", "Grade"}
-	if err := writer.Write(header); err != nil {
-		return "", fmt.Errorf("failed to write header: %w", err)
+	// Every file gets rows of its own
+	generator, err := NewGenerator(Options{Seed: rand.Int63()})
+	if err != nil {
+		return "", err
 	}
 
-	for range length {
-		student := CreateStudentRecord()
-		record := []string{
-			student.Student_id.String(),
-			student.Student_name,
-			student.Subject,
-			fmt.Sprintf("%d", student.Grade), // Convert uint to string
-		}
-		if err := writer.Write(record); err != nil {
-			return "", fmt.Errorf("failed to write record: %w", err)
-		}
+	if err := Write(file, FormatCSV, generator, length); err != nil {
+		return "", err
 	}
 
 	return path, nil
 }
 
+// CreateStudentRecord draws a valid student from the default distributions
 func CreateStudentRecord() model.StudentTest {
-	// Sample data for names and subjects
-	names := []string{"Omar", "Ali", "Saad", "Mohamed", "Ahmed"}
-	subjects := []config.Course{config.Art, config.Geography, config.Biology, config.History}
-
-	// Pick random name and subject
-	name := names[rand.Intn(len(names))]
-	subject := subjects[rand.Intn(len(subjects))]
-
-	// Generate random grade between 0 and 100 inclusive
-	grade := uint(rand.Intn(101))
-
-	// Generate new UUID
-	id := uuid.New()
+	generator, _ := NewGenerator(Options{Seed: rand.Int63()})
+	row := generator.Next()
+	grade, _ := strconv.Atoi(row.Grade)
 
 	return model.StudentTest{
-		Student_id:   id,
-		Student_name: name,
-		Subject:      string(subject),
-		Grade:        grade,
+		Student_id:   uuid.MustParse(row.StudentID),
+		Student_name: row.Name,
+		Subject:      row.Subject,
+		Grade:        uint(grade),
 	}
 }
 
@@ -1,13 +1,23 @@
 package Seeder_test
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"file-uploader/config"
+	"file-uploader/database/model"
+	"file-uploader/database/repository"
+	processor "file-uploader/internal/service/csv"
 	Seeder "file-uploader/internal/service/csv/seeder"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestSeeder(t *testing.T) {
@@ -44,3 +54,303 @@ func TestSeeder(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestSeededHeader(t *testing.T) {
+	path, err := Seeder.SeedStudentsCSV("test.csv", t.TempDir(), 3)
+	require.NoError(t, err)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	require.NoError(t, err)
+	assert.Equal(t, config.StudentsTableHeader, strings.Join(header, ","))
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("same seed same rows", func(t *testing.T) {
+		draw := func(seed int64) []Seeder.Row {
+			generator, err := Seeder.NewGenerator(Seeder.Options{Seed: seed, Errors: Seeder.ErrorRates{Duplicates: 0.1}})
+			require.NoError(t, err)
+
+			rows := make([]Seeder.Row, 50)
+			for i := range rows {
+				rows[i] = generator.Next()
+			}
+			return rows
+		}
+
+		assert.Equal(t, draw(42), draw(42))
+		assert.NotEqual(t, draw(42), draw(43))
+	})
+
+	t.Run("every catalog subject", func(t *testing.T) {
+		generator, err := Seeder.NewGenerator(Seeder.Options{Seed: 1})
+		require.NoError(t, err)
+
+		seen := map[string]bool{}
+		for range 1000 {
+			seen[generator.Next().Subject] = true
+		}
+		assert.Len(t, seen, len(config.DefaultCourses))
+	})
+
+	t.Run("distributions", func(t *testing.T) {
+		generator, err := Seeder.NewGenerator(Seeder.Options{
+			Seed:     1,
+			Subjects: []string{"Art", "Physics"},
+			Distributions: map[string]Seeder.Distribution{
+				"Art": {Kind: Seeder.Uniform, Min: 90, Max: 95},
+				"*":   {Kind: Seeder.Normal, Mean: 50, StdDev: 0},
+			},
+		})
+		require.NoError(t, err)
+
+		for range 200 {
+			row := generator.Next()
+			grade, err := strconv.Atoi(row.Grade)
+			require.NoError(t, err)
+
+			if row.Subject == "Art" {
+				assert.True(t, grade >= 90 && grade <= 95, grade)
+			} else {
+				assert.Equal(t, 50, grade)
+			}
+		}
+	})
+
+	t.Run("injected errors", func(t *testing.T) {
+		const rows = 2000
+		generator, err := Seeder.NewGenerator(Seeder.Options{
+			Seed:   7,
+			Errors: Seeder.ErrorRates{BadIDs: 0.1, BadGrades: 0.1, Duplicates: 0.1},
+		})
+		require.NoError(t, err)
+
+		ids := map[string]bool{}
+		badIDs, badGrades, duplicates := 0, 0, 0
+		for range rows {
+			row := generator.Next()
+			if _, err := uuid.Parse(row.StudentID); err != nil {
+				badIDs++
+			} else if ids[row.StudentID] {
+				duplicates++
+			}
+			ids[row.StudentID] = true
+
+			if _, err := strconv.Atoi(row.Grade); err != nil {
+				badGrades++
+			}
+		}
+
+		// Each rate is 10%, give or take
+		for _, count := range []int{badIDs, badGrades, duplicates} {
+			assert.InDelta(t, rows/10, count, rows/25)
+		}
+	})
+
+	t.Run("invalid rates", func(t *testing.T) {
+		_, err := Seeder.NewGenerator(Seeder.Options{Errors: Seeder.ErrorRates{BadIDs: 0.6, Duplicates: 0.6}})
+		assert.ErrorIs(t, err, config.ErrInvalidErrorRate)
+
+		_, err = Seeder.NewGenerator(Seeder.Options{Errors: Seeder.ErrorRates{BadGrades: -0.1}})
+		assert.ErrorIs(t, err, config.ErrInvalidErrorRate)
+	})
+}
+
+func TestParseDistributions(t *testing.T) {
+	distributions, err := Seeder.ParseDistributions("Mathematics=normal:60:18, *=uniform:40:100")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Seeder.Distribution{
+		"Mathematics": {Kind: Seeder.Normal, Mean: 60, StdDev: 18},
+		"*":           {Kind: Seeder.Uniform, Min: 40, Max: 100},
+	}, distributions)
+
+	for _, spec := range []string{
+		"Mathematics",
+		"Mathematics=normal:60",
+		"Mathematics=poisson:60:10",
+		"Mathematics=normal:160:10",
+		"Mathematics=uniform:80:40",
+		"Mathematics=uniform:40.5:100",
+		"Mathematics=uniform:0:100",
+	} {
+		_, err := Seeder.ParseDistributions(spec)
+		assert.ErrorIs(t, err, config.ErrInvalidDistribution, spec)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	const rows = 20
+
+	generate := func(t *testing.T, format Seeder.Format) []byte {
+		generator, err := Seeder.NewGenerator(Seeder.Options{Seed: 3, Errors: Seeder.ErrorRates{BadGrades: 0.2}})
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, Seeder.Write(&out, format, generator, rows))
+		return out.Bytes()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		records, err := csv.NewReader(bytes.NewReader(generate(t, Seeder.FormatCSV))).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, rows+1)
+		assert.Equal(t, config.StudentsTableHeader, strings.Join(records[0], ","))
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(string(generate(t, Seeder.FormatNDJSON))), "\n")
+		assert.Len(t, lines, rows)
+
+		for _, line := range lines {
+			var object map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &object))
+			assert.Contains(t, object, "student_id")
+			assert.Contains(t, object, "grade")
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		file, err := excelize.OpenReader(bytes.NewReader(generate(t, Seeder.FormatXLSX)))
+		require.NoError(t, err)
+		defer file.Close()
+
+		records, err := file.GetRows("Students")
+		require.NoError(t, err)
+		assert.Len(t, records, rows+1)
+		assert.Equal(t, config.StudentsTableHeader, strings.Join(records[0], ","))
+	})
+
+	t.Run("format from the extension", func(t *testing.T) {
+		for path, expected := range map[string]Seeder.Format{
+			"-":           Seeder.FormatCSV,
+			"out.csv":     Seeder.FormatCSV,
+			"out.XLSX":    Seeder.FormatXLSX,
+			"out.ndjson":  Seeder.FormatNDJSON,
+			"out.jsonl":   Seeder.FormatNDJSON,
+			"out.unknown": Seeder.FormatCSV,
+		} {
+			format, err := Seeder.ParseFormat("", path)
+			require.NoError(t, err)
+			assert.Equal(t, expected, format, path)
+		}
+
+		_, err := Seeder.ParseFormat("parquet", "out.csv")
+		assert.ErrorIs(t, err, config.ErrUnsupportedFormat)
+	})
+}
+
+func TestFixture(t *testing.T) {
+	t.Run("demo fixture is valid", func(t *testing.T) {
+		path, err := Seeder.FindFixture("../../../../fixtures", "demo")
+		require.NoError(t, err)
+
+		fixture, err := Seeder.ReadFixture(path)
+		require.NoError(t, err)
+		assert.NotEmpty(t, fixture.Students)
+	})
+
+	t.Run("json fixture", func(t *testing.T) {
+		dir := t.TempDir()
+		content := `{"tenant": "acme", "students": [{"student_name": "Ali", "subject": "Physics", "grade": 90}]}`
+		require.NoError(t, os.WriteFile(dir+"/class.json", []byte(content), 0o644))
+
+		path, err := Seeder.FindFixture(dir, "class")
+		require.NoError(t, err)
+		fixture, err := Seeder.ReadFixture(path)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", fixture.Tenant)
+		assert.Equal(t, Seeder.FixtureStudent{Name: "Ali", Subject: "Physics", Grade: 90}, fixture.Students[0])
+	})
+
+	t.Run("missing fixture", func(t *testing.T) {
+		_, err := Seeder.FindFixture(t.TempDir(), "class")
+		assert.ErrorIs(t, err, config.ErrFixtureNotExist)
+	})
+
+	t.Run("malformed fixture", func(t *testing.T) {
+		path := t.TempDir() + "/class.yaml"
+		require.NoError(t, os.WriteFile(path, []byte("students: {"), 0o644))
+
+		_, err := Seeder.ReadFixture(path)
+		assert.ErrorIs(t, err, config.ErrInvalidFixture)
+	})
+}
+
+func TestLoader(t *testing.T) {
+	repo := repository.NewMemoryStudentRepository[model.StudentTest]()
+	loader := Seeder.Loader[model.StudentTest]{Students: repo, Mapper: processor.StudentTestMapper}
+	fixture := &Seeder.Fixture{
+		Students: []Seeder.FixtureStudent{
+			{Name: "Ali", Subject: string(config.Physics), Grade: 90},
+			{Name: "Omar", Subject: string(config.Art), Grade: 70},
+		},
+	}
+
+	t.Run("load adds the students", func(t *testing.T) {
+		inserted, err := loader.Load(fixture, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, inserted)
+		assert.Equal(t, 2, count(t, repo))
+	})
+
+	t.Run("reset replaces the students", func(t *testing.T) {
+		_, err := loader.Load(fixture, false)
+		require.NoError(t, err)
+		_, err = loader.Load(fixture, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count(t, repo))
+	})
+
+	t.Run("invalid student", func(t *testing.T) {
+		_, err := loader.Load(&Seeder.Fixture{Students: []Seeder.FixtureStudent{{StudentID: "not-a-uuid", Name: "Ali", Subject: "Physics", Grade: 90}}}, false)
+		assert.ErrorIs(t, err, config.ErrInvalidFixture)
+	})
+
+	t.Run("courses need a repository", func(t *testing.T) {
+		_, err := loader.Load(&Seeder.Fixture{Courses: []string{"Astronomy"}}, false)
+		assert.ErrorIs(t, err, config.ErrInvalidFixture)
+	})
+
+	t.Run("generate inserts the rows", func(t *testing.T) {
+		_, err := loader.Reset(config.DefaultTenantID)
+		require.NoError(t, err)
+
+		generator, err := Seeder.NewGenerator(Seeder.Options{Seed: 1})
+		require.NoError(t, err)
+		inserted, err := loader.Generate(config.DefaultTenantID, generator, 25)
+		require.NoError(t, err)
+		assert.Equal(t, 25, inserted)
+		assert.Equal(t, 25, count(t, repo))
+	})
+
+	t.Run("generated grades are never rejected", func(t *testing.T) {
+		_, err := loader.Reset(config.DefaultTenantID)
+		require.NoError(t, err)
+
+		// Most normal draws fall below 1 and are clamped
+		generator, err := Seeder.NewGenerator(Seeder.Options{
+			Seed:          3,
+			Subjects:      []string{"Art", "Physics"},
+			Distributions: map[string]Seeder.Distribution{"Art": {Kind: Seeder.Uniform, Min: 1, Max: 2}, "*": {Kind: Seeder.Normal, Mean: 0, StdDev: 5}},
+		})
+		require.NoError(t, err)
+
+		// Create validates each student, unlike the batch inserts
+		for range 500 {
+			student, err := processor.StudentTestMapper(generator.Next().Record())
+			require.NoError(t, err)
+			_, err = repo.Create(student)
+			require.NoError(t, err, "grade %d", student.Grade)
+		}
+		assert.Equal(t, 500, count(t, repo))
+	})
+}
+
+func count(t *testing.T, repo repository.StudentRepository[model.StudentTest]) int {
+	_, total, err := repo.Query(nil, nil)
+	require.NoError(t, err)
+	return int(total)
+}
@@ -0,0 +1,144 @@
+package Seeder
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"file-uploader/config"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat reads csv, xlsx or ndjson, empty picks the format from the
+// extension of path and falls back to csv
+func ParseFormat(value, path string) (Format, error) {
+	if value == "" {
+		value = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if value == "jsonl" {
+			value = string(FormatNDJSON)
+		}
+		if value != string(FormatXLSX) && value != string(FormatNDJSON) {
+			return FormatCSV, nil
+		}
+	}
+
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatCSV, FormatXLSX, FormatNDJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: %q", config.ErrUnsupportedFormat, value)
+}
+
+// Write draws rows from the generator into w, under the header of
+// config.StudentsTableHeader
+func Write(w io.Writer, format Format, generator *Generator, rows int) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, generator, rows)
+	case FormatXLSX:
+		return writeXLSX(w, generator, rows)
+	case FormatNDJSON:
+		return writeNDJSON(w, generator, rows)
+	}
+	return fmt.Errorf("%w: %q", config.ErrUnsupportedFormat, format)
+}
+
+func writeCSV(w io.Writer, generator *Generator, rows int) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(strings.Split(config.StudentsTableHeader, ",")); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for range rows {
+		if err := writer.Write(generator.Next().Record()); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeNDJSON writes an object per line keyed by the header, grades are
+// numbers unless broken on purpose
+func writeNDJSON(w io.Writer, generator *Generator, rows int) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	keys := strings.Split(config.StudentsTableHeader, ",")
+
+	for range rows {
+		row := generator.Next()
+		object := map[string]any{
+			keys[0]: row.StudentID,
+			keys[1]: row.Name,
+			keys[2]: row.Subject,
+			keys[3]: gradeValue(row.Grade),
+		}
+		if err := encoder.Encode(object); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return buffered.Flush()
+}
+
+// writeXLSX streams the rows into a single sheet so large files don't sit
+// in memory
+func writeXLSX(w io.Writer, generator *Generator, rows int) error {
+	const sheet = "Students"
+
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := []any{}
+	for _, key := range strings.Split(config.StudentsTableHeader, ",") {
+		header = append(header, key)
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for i := range rows {
+		row := generator.Next()
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := stream.SetRow(cell, []any{row.StudentID, row.Name, row.Subject, gradeValue(row.Grade)}); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	_, err = file.WriteTo(w)
+	return err
+}
+
+// gradeValue types valid grades as numbers
+func gradeValue(grade string) any {
+	if value, err := strconv.Atoi(grade); err == nil {
+		return value
+	}
+	return grade
+}
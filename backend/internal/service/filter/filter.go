@@ -0,0 +1,415 @@
+// Package filter parses filter expressions of the students API into
+// repository conditions, e.g.
+//
+//	grade >= 90 AND subject IN (Physics, Chemistry)
+//	name contains "ali" OR (grade BETWEEN 50 AND 60 AND subject = "English Literature")
+package filter
+
+import (
+	"errors"
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxLength     = 1000
+	MaxConditions = 50
+)
+
+var ErrInvalidFilter = errors.New("invalid filter expression")
+
+type fieldType int
+
+const (
+	textField fieldType = iota
+	numberField
+	idField
+)
+
+type field struct {
+	column config.StudentCol
+	kind   fieldType
+}
+
+// Filterable columns, anything else is rejected
+var fields = map[string]field{
+	"id":           {config.Id, idField},
+	"student_id":   {config.Id, idField},
+	"name":         {config.Name, textField},
+	"student_name": {config.Name, textField},
+	"subject":      {config.Subject, textField},
+	"grade":        {config.Grade, numberField},
+}
+
+var allowedOps = map[fieldType]map[repository.Operator]bool{
+	textField: {
+		repository.OpEq: true, repository.OpNe: true, repository.OpIn: true,
+		repository.OpContains: true, repository.OpPrefix: true,
+	},
+	numberField: {
+		repository.OpEq: true, repository.OpNe: true, repository.OpGt: true, repository.OpGte: true,
+		repository.OpLt: true, repository.OpLte: true, repository.OpIn: true, repository.OpBetween: true,
+	},
+	idField: {
+		repository.OpEq: true, repository.OpNe: true, repository.OpIn: true,
+	},
+}
+
+// Parse compiles an expression into a condition, an empty expression has no condition
+func Parse(expr string) (*repository.Condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	if len(expr) > MaxLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidFilter, MaxLength)
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.at(tokenEOF) {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+
+	return &condition, nil
+}
+
+type parser struct {
+	tokens     []token
+	pos        int
+	conditions int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) at(kind tokenKind) bool {
+	return p.peek().kind == kind
+}
+
+func (p *parser) atKeyword(keyword string) bool {
+	return p.peek().isKeyword(keyword)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at position %d: %s", ErrInvalidFilter, p.peek().pos+1, fmt.Sprintf(format, args...))
+}
+
+// or := and ("OR" and)*
+func (p *parser) parseOr() (repository.Condition, error) {
+	return p.parseGroup(repository.LogicOr, "OR", p.parseAnd)
+}
+
+// and := primary ("AND" primary)*
+func (p *parser) parseAnd() (repository.Condition, error) {
+	return p.parseGroup(repository.LogicAnd, "AND", p.parsePrimary)
+}
+
+func (p *parser) parseGroup(
+	logic repository.Logic,
+	keyword string,
+	parseOperand func() (repository.Condition, error),
+) (repository.Condition, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return repository.Condition{}, err
+	}
+
+	children := []repository.Condition{first}
+	for p.atKeyword(keyword) {
+		p.next()
+		operand, err := parseOperand()
+		if err != nil {
+			return repository.Condition{}, err
+		}
+		children = append(children, operand)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return repository.Condition{Logic: logic, Children: children}, nil
+}
+
+// primary := "(" or ")" | comparison
+func (p *parser) parsePrimary() (repository.Condition, error) {
+	if p.at(tokenLParen) {
+		p.next()
+		condition, err := p.parseOr()
+		if err != nil {
+			return repository.Condition{}, err
+		}
+		if !p.at(tokenRParen) {
+			return repository.Condition{}, p.errorf("expected )")
+		}
+		p.next()
+		return condition, nil
+	}
+
+	return p.parseComparison()
+}
+
+// comparison := field (op value | IN "(" value ("," value)* ")" | BETWEEN value AND value | CONTAINS value | PREFIX value)
+func (p *parser) parseComparison() (repository.Condition, error) {
+	p.conditions++
+	if p.conditions > MaxConditions {
+		return repository.Condition{}, p.errorf("more than %d conditions", MaxConditions)
+	}
+
+	if !p.at(tokenWord) {
+		return repository.Condition{}, p.errorf("expected a field name, got %s", p.peek())
+	}
+	name := p.next()
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return repository.Condition{}, fmt.Errorf("%w at position %d: unknown field %q", ErrInvalidFilter, name.pos+1, name.text)
+	}
+
+	op, err := p.parseOperator()
+	if err != nil {
+		return repository.Condition{}, err
+	}
+	if !allowedOps[f.kind][op] {
+		return repository.Condition{}, fmt.Errorf("%w: operator %s is not supported on %s", ErrInvalidFilter, op, name.text)
+	}
+
+	condition := repository.Condition{Column: f.column, Op: op}
+
+	switch op {
+	case repository.OpIn:
+		if !p.at(tokenLParen) {
+			return repository.Condition{}, p.errorf("expected ( after IN")
+		}
+		p.next()
+		for {
+			value, err := p.parseValue(f)
+			if err != nil {
+				return repository.Condition{}, err
+			}
+			condition.Values = append(condition.Values, value)
+
+			if p.at(tokenComma) {
+				p.next()
+				continue
+			}
+			if p.at(tokenRParen) {
+				p.next()
+				break
+			}
+			return repository.Condition{}, p.errorf("expected , or )")
+		}
+
+	case repository.OpBetween:
+		low, err := p.parseValue(f)
+		if err != nil {
+			return repository.Condition{}, err
+		}
+		if !p.atKeyword("AND") {
+			return repository.Condition{}, p.errorf("expected AND in BETWEEN")
+		}
+		p.next()
+		high, err := p.parseValue(f)
+		if err != nil {
+			return repository.Condition{}, err
+		}
+		condition.Values = []any{low, high}
+
+	default:
+		value, err := p.parseValue(f)
+		if err != nil {
+			return repository.Condition{}, err
+		}
+		condition.Values = []any{value}
+	}
+
+	return condition, nil
+}
+
+func (p *parser) parseOperator() (repository.Operator, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenOp:
+		p.next()
+		if t.text == "==" {
+			return repository.OpEq, nil
+		}
+		return repository.Operator(t.text), nil
+	case t.isKeyword("IN"):
+		p.next()
+		return repository.OpIn, nil
+	case t.isKeyword("BETWEEN"):
+		p.next()
+		return repository.OpBetween, nil
+	case t.isKeyword("CONTAINS"):
+		p.next()
+		return repository.OpContains, nil
+	case t.isKeyword("PREFIX"):
+		p.next()
+		return repository.OpPrefix, nil
+	}
+	return "", p.errorf("expected an operator, got %s", t)
+}
+
+// parseValue reads a quoted string or consecutive bare words, typed by the field
+func (p *parser) parseValue(f field) (any, error) {
+	start := p.peek()
+
+	var text string
+	switch {
+	case p.at(tokenString):
+		text = p.next().text
+	case p.at(tokenWord) && !isReserved(start):
+		words := []string{p.next().text}
+		for p.at(tokenWord) && !isReserved(p.peek()) {
+			words = append(words, p.next().text)
+		}
+		text = strings.Join(words, " ")
+	default:
+		return nil, p.errorf("expected a value, got %s", start)
+	}
+
+	switch f.kind {
+	case numberField:
+		value, err := strconv.ParseUint(text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w at position %d: %q is not a valid grade", ErrInvalidFilter, start.pos+1, text)
+		}
+		return uint(value), nil
+	case idField:
+		value, err := uuid.Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w at position %d: %q is not a valid id", ErrInvalidFilter, start.pos+1, text)
+		}
+		return value, nil
+	default:
+		return text, nil
+	}
+}
+
+func isReserved(t token) bool {
+	return t.isKeyword("AND") || t.isKeyword("OR")
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				// Backslash escapes the next character
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w at position %d: unterminated string", ErrInvalidFilter, start+1)
+			}
+			i++
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+				i++
+			}
+			i++
+			if op == "!" {
+				return nil, fmt.Errorf("%w at position %d: unknown operator !", ErrInvalidFilter, start+1)
+			}
+			tokens = append(tokens, token{tokenOp, op, start})
+
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune("_-.", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), start})
+
+		default:
+			return nil, fmt.Errorf("%w at position %d: unexpected character %q", ErrInvalidFilter, i+1, r)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
@@ -0,0 +1,101 @@
+package filter_test
+
+import (
+	"file-uploader/config"
+	"file-uploader/database/repository"
+	"file-uploader/internal/service/filter"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		expr     string
+		expected *repository.Condition
+	}{
+		{
+			name:     "empty expression",
+			expr:     "  ",
+			expected: nil,
+		},
+		{
+			name:     "single comparison",
+			expr:     "grade >= 90",
+			expected: &repository.Condition{Column: config.Grade, Op: repository.OpGte, Values: []any{uint(90)}},
+		},
+		{
+			name: "comparison and IN list with bare words",
+			expr: "grade >= 90 AND subject IN (Physics, Chemistry, English Literature)",
+			expected: &repository.Condition{Logic: repository.LogicAnd, Children: []repository.Condition{
+				{Column: config.Grade, Op: repository.OpGte, Values: []any{uint(90)}},
+				{Column: config.Subject, Op: repository.OpIn, Values: []any{"Physics", "Chemistry", "English Literature"}},
+			}},
+		},
+		{
+			name:     "contains with a quoted string",
+			expr:     `name contains "ali"`,
+			expected: &repository.Condition{Column: config.Name, Op: repository.OpContains, Values: []any{"ali"}},
+		},
+		{
+			name: "AND binds tighter than OR",
+			expr: "name prefix 'Om' or grade between 50 and 60 and subject = Art",
+			expected: &repository.Condition{Logic: repository.LogicOr, Children: []repository.Condition{
+				{Column: config.Name, Op: repository.OpPrefix, Values: []any{"Om"}},
+				{Logic: repository.LogicAnd, Children: []repository.Condition{
+					{Column: config.Grade, Op: repository.OpBetween, Values: []any{uint(50), uint(60)}},
+					{Column: config.Subject, Op: repository.OpEq, Values: []any{"Art"}},
+				}},
+			}},
+		},
+		{
+			name: "parentheses",
+			expr: "(subject = Art OR subject = Music) AND grade < 50",
+			expected: &repository.Condition{Logic: repository.LogicAnd, Children: []repository.Condition{
+				{Logic: repository.LogicOr, Children: []repository.Condition{
+					{Column: config.Subject, Op: repository.OpEq, Values: []any{"Art"}},
+					{Column: config.Subject, Op: repository.OpEq, Values: []any{"Music"}},
+				}},
+				{Column: config.Grade, Op: repository.OpLt, Values: []any{uint(50)}},
+			}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := filter.Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, condition)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name          string
+		expr          string
+		expectedError string
+	}{
+		{name: "unknown field", expr: "password = 1", expectedError: `unknown field "password"`},
+		{name: "sql injection attempt", expr: "grade = 1; DROP TABLE students", expectedError: "unexpected character"},
+		{name: "operator not supported on field", expr: "grade contains 9", expectedError: "operator contains is not supported on grade"},
+		{name: "invalid number", expr: "grade > high", expectedError: `"high" is not a valid grade`},
+		{name: "invalid id", expr: "id = 42", expectedError: `"42" is not a valid id`},
+		{name: "missing value", expr: "grade >", expectedError: "expected a value"},
+		{name: "unclosed parenthesis", expr: "(grade > 1", expectedError: "expected )"},
+		{name: "unterminated string", expr: `name = "ali`, expectedError: "unterminated string"},
+		{name: "dangling operator", expr: "grade > 1 AND", expectedError: "expected a field name"},
+		{name: "too many conditions", expr: strings.Repeat("grade > 1 OR ", 50) + "grade > 1", expectedError: "more than 50 conditions"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := filter.Parse(tt.expr)
+			require.ErrorIs(t, err, filter.ErrInvalidFilter)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package jobs runs background work on a fixed pool of workers
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"file-uploader/config"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Priority orders waiting jobs, higher first, jobs of equal priority run in
+// the order they were submitted
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// ParsePriority reads low, normal or high, empty is normal
+func ParsePriority(value string) (Priority, error) {
+	switch value {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	}
+	return PriorityNormal, config.ErrInvalidPriority
+}
+
+type Job struct {
+	ID       uuid.UUID
+	Priority Priority
+
+	// Run does the work, ctx is canceled when shutdown runs out of time.
+	// Jobs still waiting at that point run with a canceled ctx so they can
+	// release what they hold
+	Run func(ctx context.Context)
+
+	// Waiting is told the place of the job in line each time it changes, 1
+	// runs next. It is called with the queue lock held and must not block
+	Waiting func(position int)
+
+	seq uint64
+
+	// position is the place in line Waiting was last told
+	position int
+}
+
+// Queue runs submitted jobs on a fixed number of workers, at most capacity
+// jobs wait for a busy worker
+type Queue struct {
+	mu       sync.Mutex
+	ready    *sync.Cond
+	waiting  jobHeap
+	capacity int
+	idle     int
+	seq      uint64
+	closed   bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewQueue(workers, capacity int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	workers = max(workers, 1)
+	q := &Queue{capacity: capacity, idle: workers, ctx: ctx, cancel: cancel}
+	q.ready = sync.NewCond(&q.mu)
+
+	for range workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Submit queues the job and returns its place in line
+func (q *Queue) Submit(job *Job) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, config.ErrQueueClosed
+	}
+	if len(q.waiting) >= q.capacity+q.idle {
+		return 0, config.ErrQueueFull
+	}
+	return q.push(job), nil
+}
+
+// Requeue queues a job accepted before a restart, capacity doesn't apply
+func (q *Queue) Requeue(job *Job) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, config.ErrQueueClosed
+	}
+	return q.push(job), nil
+}
+
+// push adds the job and returns its place in line, the lock must be held
+func (q *Queue) push(job *Job) int {
+	q.seq++
+	job.seq = q.seq
+	heap.Push(&q.waiting, job)
+
+	positions := q.positions()
+	notify(positions)
+	q.ready.Signal()
+	return positions[job]
+}
+
+// Position returns the place in line of a waiting job
+func (q *Queue) Position(id uuid.UUID) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for job, position := range q.positions() {
+		if job.ID == id {
+			return position, true
+		}
+	}
+	return 0, false
+}
+
+// Len returns the number of waiting jobs
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting)
+}
+
+// Shutdown stops accepting jobs and waits for the workers to drain the
+// queue. When ctx ends first, running jobs are canceled and waiting jobs
+// run canceled, Shutdown then returns the ctx error once they are done
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.ready.Broadcast()
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		for len(q.waiting) == 0 && !q.closed {
+			q.ready.Wait()
+		}
+		if len(q.waiting) == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		job := heap.Pop(&q.waiting).(*Job)
+		q.idle--
+		notify(q.positions())
+		q.mu.Unlock()
+
+		job.Run(q.ctx)
+
+		q.mu.Lock()
+		q.idle++
+		q.mu.Unlock()
+	}
+}
+
+// positions ranks the waiting jobs, the lock must be held
+func (q *Queue) positions() map[*Job]int {
+	ordered := make(jobHeap, len(q.waiting))
+	copy(ordered, q.waiting)
+
+	positions := make(map[*Job]int, len(ordered))
+	for position := 1; len(ordered) > 0; position++ {
+		positions[heap.Pop(&ordered).(*Job)] = position
+	}
+	return positions
+}
+
+// notify tells the jobs whose place in line changed, the lock must be held
+func notify(positions map[*Job]int) {
+	for job, position := range positions {
+		if job.position == position {
+			continue
+		}
+		job.position = position
+		if job.Waiting != nil {
+			job.Waiting(position)
+		}
+	}
+}
+
+// jobHeap implements heap.Interface, the next job to run first
+type jobHeap []*Job
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x any) { *h = append(*h, x.(*Job)) }
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return job
+}
@@ -0,0 +1,168 @@
+package jobs_test
+
+import (
+	"context"
+	"file-uploader/config"
+	"file-uploader/internal/service/jobs"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blocker occupies a worker until it is released
+func blocker(t *testing.T, queue *jobs.Queue) (release func()) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	_, err := queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+		close(started)
+		<-done
+	}})
+	require.NoError(t, err)
+	<-started
+
+	return func() { close(done) }
+}
+
+func TestQueueOrder(t *testing.T) {
+	queue := jobs.NewQueue(1, 10)
+	release := blocker(t, queue)
+
+	var mu sync.Mutex
+	order := []string{}
+	run := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		priority jobs.Priority
+		position int
+	}{
+		{name: "first", priority: jobs.PriorityNormal, position: 1},
+		{name: "second", priority: jobs.PriorityNormal, position: 2},
+		{name: "background", priority: jobs.PriorityLow, position: 3},
+		{name: "urgent", priority: jobs.PriorityHigh, position: 1},
+	}
+
+	// Called with the queue lock held
+	positions := map[string][]int{}
+	for _, tt := range cases {
+		job := &jobs.Job{ID: uuid.New(), Priority: tt.priority, Run: run(tt.name)}
+		job.Waiting = func(position int) { positions[tt.name] = append(positions[tt.name], position) }
+
+		position, err := queue.Submit(job)
+		require.NoError(t, err)
+		assert.Equal(t, tt.position, position, tt.name)
+	}
+
+	// Waiting jobs are told when they are pushed back, only then
+	assert.Equal(t, []int{1, 2}, positions["first"])
+	assert.Equal(t, []int{3, 4}, positions["background"])
+	assert.Equal(t, []int{1}, positions["urgent"])
+	assert.Equal(t, 4, queue.Len())
+
+	release()
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"urgent", "first", "second", "background"}, order)
+
+	// Each job moving up is told once per move
+	assert.Equal(t, []int{1, 2, 1}, positions["first"])
+	assert.Equal(t, []int{2, 3, 2, 1}, positions["second"])
+	assert.Equal(t, []int{3, 4, 3, 2, 1}, positions["background"])
+}
+
+func TestQueueCapacity(t *testing.T) {
+	queue := jobs.NewQueue(1, 1)
+	release := blocker(t, queue)
+
+	id := uuid.New()
+	_, err := queue.Submit(&jobs.Job{ID: id, Run: func(ctx context.Context) {}})
+	require.NoError(t, err)
+
+	position, waiting := queue.Position(id)
+	assert.True(t, waiting)
+	assert.Equal(t, 1, position)
+
+	_, err = queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {}})
+	assert.ErrorIs(t, err, config.ErrQueueFull)
+
+	release()
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	_, waiting = queue.Position(id)
+	assert.False(t, waiting)
+
+	_, err = queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {}})
+	assert.ErrorIs(t, err, config.ErrQueueClosed)
+}
+
+func TestQueueShutdown(t *testing.T) {
+	t.Run("drains waiting jobs", func(t *testing.T) {
+		queue := jobs.NewQueue(2, 5)
+
+		var mu sync.Mutex
+		finished := 0
+		for range 5 {
+			_, err := queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				finished++
+			}})
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, queue.Shutdown(context.Background()))
+		assert.Equal(t, 5, finished)
+	})
+
+	t.Run("cancels jobs past the deadline", func(t *testing.T) {
+		queue := jobs.NewQueue(1, 5)
+
+		running := make(chan struct{})
+		_, err := queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+			close(running)
+			<-ctx.Done()
+		}})
+		require.NoError(t, err)
+		<-running
+
+		// Jobs still waiting run canceled so they can clean up
+		var waitingErr error
+		_, err = queue.Submit(&jobs.Job{ID: uuid.New(), Run: func(ctx context.Context) {
+			waitingErr = ctx.Err()
+		}})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+		assert.ErrorIs(t, waitingErr, context.Canceled)
+	})
+}
+
+func TestParsePriority(t *testing.T) {
+	for value, expected := range map[string]jobs.Priority{
+		"":       jobs.PriorityNormal,
+		"normal": jobs.PriorityNormal,
+		"low":    jobs.PriorityLow,
+		"high":   jobs.PriorityHigh,
+	} {
+		priority, err := jobs.ParsePriority(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, priority)
+	}
+
+	_, err := jobs.ParsePriority("urgent")
+	assert.ErrorIs(t, err, config.ErrInvalidPriority)
+}
@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric of the service
+const namespace = "file_uploader"
+
+// Metrics holds the collectors of the service on a registry of its own, so
+// tests can read them. Every method does nothing on a nil *Metrics, code
+// running without metrics passes nil
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+
+	uploadsStarted   prometheus.Counter
+	uploadsSucceeded prometheus.Counter
+	uploadsFailed    prometheus.Counter
+
+	rowsIngested   prometheus.Counter
+	rowsRejected   prometheus.Counter
+	bytesProcessed prometheus.Counter
+
+	insertLatency prometheus.Histogram
+	insertRows    prometheus.Histogram
+
+	watchers prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status code",
+		}, []string{"method", "route", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_request_duration_seconds",
+			Help:    "Time to answer HTTP requests by method and route",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		uploadsStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "uploads_started_total",
+			Help: "Uploads whose processing started, resumed uploads included",
+		}),
+		uploadsSucceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "uploads_succeeded_total",
+			Help: "Uploads whose every file was imported",
+		}),
+		uploadsFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "uploads_failed_total",
+			Help: "Uploads with a file that failed validation or import",
+		}),
+
+		rowsIngested: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rows_ingested_total",
+			Help: "CSV records stored by imports",
+		}),
+		rowsRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rows_rejected_total",
+			Help: "CSV records read by imports but not stored, a failed file rejects the records read since its last stored batch",
+		}),
+		bytesProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "processed_bytes_total",
+			Help: "Bytes of CSV read by imports",
+		}),
+
+		insertLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "insert_batch_duration_seconds",
+			Help:    "Time of the insert statements of CreateMany",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		}),
+		insertRows: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "insert_batch_rows",
+			Help:    "Rows of the insert statements of CreateMany",
+			Buckets: prometheus.ExponentialBuckets(50, 2, 8),
+		}),
+
+		watchers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "upload_watchers",
+			Help: "WebSockets following the progress of an upload",
+		}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.latency,
+		m.uploadsStarted, m.uploadsSucceeded, m.uploadsFailed,
+		m.rowsIngested, m.rowsRejected, m.bytesProcessed,
+		m.insertLatency, m.insertRows,
+		m.watchers,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// WatchQueue reports the jobs waiting in the queue when scraped
+func (m *Metrics) WatchQueue(queue interface{ Len() int }) {
+	if m == nil {
+		return
+	}
+	m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace, Name: "upload_queue_depth",
+		Help: "Uploads waiting for a worker of the job queue",
+	}, func() float64 { return float64(queue.Len()) }))
+}
+
+// WatchDB reports the pool of connections when scraped
+func (m *Metrics) WatchDB(db *sql.DB) {
+	if m == nil {
+		return
+	}
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveRequest counts a request answered with code, route is the pattern
+// of the route so ids don't make a series each
+func (m *Metrics) ObserveRequest(method, route string, code int, took time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.latency.WithLabelValues(method, route).Observe(took.Seconds())
+}
+
+func (m *Metrics) UploadStarted() {
+	if m != nil {
+		m.uploadsStarted.Inc()
+	}
+}
+
+func (m *Metrics) UploadSucceeded() {
+	if m != nil {
+		m.uploadsSucceeded.Inc()
+	}
+}
+
+func (m *Metrics) UploadFailed() {
+	if m != nil {
+		m.uploadsFailed.Inc()
+	}
+}
+
+// FileProcessed counts the bytes and records of a file once it is imported
+// or has failed
+func (m *Metrics) FileProcessed(bytes, stored, rejected int64) {
+	if m == nil {
+		return
+	}
+	m.bytesProcessed.Add(float64(bytes))
+	m.rowsIngested.Add(float64(stored))
+	m.rowsRejected.Add(float64(rejected))
+}
+
+// ObserveInsert records an insert statement of rows that took took
+func (m *Metrics) ObserveInsert(rows int, took time.Duration) {
+	if m == nil {
+		return
+	}
+	m.insertLatency.Observe(took.Seconds())
+	m.insertRows.Observe(float64(rows))
+}
+
+// WatcherConnected counts a WebSocket following an upload until the
+// returned function is called
+func (m *Metrics) WatcherConnected() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.watchers.Inc()
+	return m.watchers.Dec
+}
@@ -14,6 +14,7 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -110,21 +111,75 @@ func createMultipartFileHeader(filePath string) (*multipart.FileHeader, error) {
 	return fileHeaders[0], nil
 }
 
-func LoadDb() (*gorm.DB, repository.StudentRepository[model.StudentTest], error) {
-	dsn, exist := os.LookupEnv(config.DBEnvVar)
+// TestDSN picks the database used by tests, an embedded SQLite file unless
+// TEST_DB_DRIVER=postgres, which uses DB_DSN_LOCAL
+func TestDSN() (config.DBDriver, string, error) {
+	driver := config.DriverSQLite
+	if value, exist := os.LookupEnv(config.TestDriverEnvVar); exist && value != "" {
+		driver = config.DBDriver(value)
+	}
 
-	if !exist {
-		return nil, nil, config.ErrEnvVarNotFound
+	if driver != config.DriverSQLite {
+		dsn, exist := os.LookupEnv(config.DBEnvVar)
+		if !exist {
+			return "", "", config.ErrEnvVarNotFound
+		}
+		return driver, dsn, nil
 	}
 
-	db, repository, err := database.SetupDB[model.StudentTest](dsn, true)
+	dir, err := os.MkdirTemp("", "file-uploader-test")
 	if err != nil {
+		return "", "", err
+	}
+	return driver, filepath.Join(dir, "test.db"), nil
+}
+
+// RemoveTestDSN removes the SQLite file of a DSN from TestDSN along with its
+// directory, a postgres DSN is left alone
+func RemoveTestDSN(driver config.DBDriver, dsn string) error {
+	if driver != config.DriverSQLite {
+		return nil
+	}
+	return os.RemoveAll(filepath.Dir(dsn))
+}
+
+// testDSN is where a database opened by LoadDb lives
+type testDSN struct {
+	driver config.DBDriver
+	dsn    string
+}
+
+// opened holds the testDSN of each database opened by LoadDb, CloseDb removes
+// its SQLite file
+var opened sync.Map
+
+func LoadDb() (*gorm.DB, repository.StudentRepository[model.StudentTest], error) {
+	driver, dsn, err := TestDSN()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, repository, err := database.SetupDB[model.StudentTest](driver, dsn, true)
+	if err != nil {
+		RemoveTestDSN(driver, dsn)
 		return nil, nil, err
 	}
+	opened.Store(db, testDSN{driver: driver, dsn: dsn})
 
 	return db, repository, nil
 }
 
+// CloseDb closes a database opened by LoadDb and removes its SQLite file
+func CloseDb(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	if value, exist := opened.LoadAndDelete(db); exist {
+		test := value.(testDSN)
+		RemoveTestDSN(test.driver, test.dsn)
+	}
+}
+
 func NewTestContext(method, path string, body io.Reader) (echo.Context, *httptest.ResponseRecorder) {
 	e := echo.New()
 	req := httptest.NewRequest(method, path, body)
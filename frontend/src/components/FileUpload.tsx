@@ -262,6 +262,7 @@ export default function FileUpload() {
       const response = await fetch(apiUrl, {
         method: "POST",
         body: formData,
+        credentials: "include",
       });
 
       if (response.ok) {
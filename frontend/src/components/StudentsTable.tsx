@@ -21,17 +21,20 @@ export default function StudentsTable({ records }: { records: Record[] }) {
           <tbody className="overflow-y-auto">
             {records?.map((record, idx) => (
               <tr
-                key={record.Student_id}
+                key={record.student_id}
                 className={`border-b border-teal-700/10 hover:bg-teal-700/10 transition-colors duration-300 ${idx % 2 === 0 ? "bg-neutral-200/20" : ""}`}
               >
                 <td className="py-1 sm:py-2 px-2 sm:px-5 md:px-10 text-left text-sm sm:text-base">
-                  {record.Student_name}
+                  {record.student_name}
                 </td>
                 <td className="py-1 sm:py-2 px-2 sm:px-5 md:px-10 text-left text-sm sm:text-base">
-                  {record.Subject}
+                  {record.subject}
                 </td>
                 <td className="py-1 sm:py-2 px-2 sm:px-5 md:px-10 text-left text-sm sm:text-base font-bold">
-                  {record.Grade}
+                  {record.grade}
+                  {record.letter_grade && (
+                    <span className="ml-2 font-normal text-teal-700">({record.letter_grade})</span>
+                  )}
                 </td>
               </tr>
             ))}
@@ -2,7 +2,7 @@ import BackgroundLeft from "../components/BackgroundLeft";
 import BackgroundRight from "../components/BackgroundRight";
 import StudentsTable from "../components/StudentsTable";
 import { useEffect, useState } from "react";
-import { useSearchParams } from "react-router";
+import { useNavigate, useSearchParams } from "react-router";
 import { Record, SearchParamsKeys } from "../types/shared";
 import NameSearch from "../components/NameSearch";
 import Options from "../components/Options";
@@ -12,6 +12,7 @@ const DEFAULT_PAGE_SIZE = 100;
 
 export default function Dashboard() {
   const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
   const page = searchParams.get(SearchParamsKeys.PAGE) || DEFAULT_PAGE;
   const pageSize =
     searchParams.get(SearchParamsKeys.PAGE_SIZE) || DEFAULT_PAGE_SIZE;
@@ -34,8 +35,15 @@ export default function Dashboard() {
 
     fetch(
       `${serverUrl}api/students?page=${page}&size=${pageSize}&sort_by=${capitalizedSortBy}&sort_order=${sortOrderSmallCase}&name=${name}&subject=${capitalizedSubject}`,
+      { credentials: "include" },
     )
-      .then((response) => response.json())
+      .then((response) => {
+        if (response.status === 401) {
+          navigate("/login?next=/dashboard");
+          throw new Error("Authentication required");
+        }
+        return response.json();
+      })
       .then((data) => {
         setRecords(data.records);
         setCount(data.count);
@@ -0,0 +1,73 @@
+import { FormEvent, useState } from "react";
+import { useNavigate, useSearchParams } from "react-router";
+import BackgroundLeft from "../components/BackgroundLeft";
+import BackgroundRight from "../components/BackgroundRight";
+import Logo from "../components/Logo";
+
+export default function Login() {
+  const navigate = useNavigate();
+  const [searchParams] = useSearchParams();
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState<string | null>(null);
+
+  const serverUrl = import.meta.env.VITE_SERVER_URL;
+
+  const handleSubmit = async (e: FormEvent) => {
+    e.preventDefault();
+    setError(null);
+
+    // The session cookie is set by the server
+    const response = await fetch(`${serverUrl}api/auth/login`, {
+      method: "POST",
+      credentials: "include",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ email, password }),
+    });
+
+    if (response.ok) {
+      navigate(searchParams.get("next") || "/");
+    } else {
+      setError("Invalid email or password");
+    }
+  };
+
+  return (
+    <main className="p-4 sm:p-6 md:p-8 lg:p-10 min-h-screen relative overflow-hidden selection:bg-teal-800 selection:text-teal-50">
+      <div className="flex items-center min-h-[calc(100vh-2rem)] sm:min-h-[calc(100vh-3rem)] justify-center">
+        <form
+          onSubmit={handleSubmit}
+          className="w-full max-w-[95%] sm:max-w-md flex flex-col gap-4 sm:gap-6 justify-center bg-teal-50/20 backdrop-blur-xl border-teal-600 px-4 sm:px-8 py-8 sm:py-12 border rounded-2xl sm:rounded-3xl"
+        >
+          <Logo />
+          <input
+            type="email"
+            placeholder="Email"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            className="border border-teal-600 rounded-lg px-4 py-2 bg-transparent"
+            required
+          />
+          <input
+            type="password"
+            placeholder="Password"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            className="border border-teal-600 rounded-lg px-4 py-2 bg-transparent"
+            required
+          />
+          {error && <p className="text-red-700 text-sm">{error}</p>}
+          <button
+            type="submit"
+            className="bg-teal-700 text-white rounded-lg px-4 py-2 hover:bg-teal-800 transition-colors duration-300"
+          >
+            Log in
+          </button>
+        </form>
+      </div>
+
+      <BackgroundLeft className="absolute saturate-0 hidden sm:block opacity-10 -z-10 -left-12 bottom-0 rotate-12" />
+      <BackgroundRight className="absolute -z-10 saturate-0 top-0 -right-36 rotate-12 opacity-16" />
+    </main>
+  );
+}
@@ -1,8 +1,9 @@
 export interface Record {
-  Student_id: string;
-  Student_name: string;
-  Subject: string;
-  Grade: string;
+  student_id: string;
+  student_name: string;
+  subject: string;
+  grade: number;
+  letter_grade?: string;
 }
 
 export const SearchParamsKeys = {